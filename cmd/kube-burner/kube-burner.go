@@ -17,14 +17,19 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"syscall"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 
+	ocpmetadata "github.com/cloud-bulldozer/go-commons/ocp-metadata"
 	"github.com/cloud-bulldozer/go-commons/version"
 	"github.com/cloud-bulldozer/kube-burner/pkg/alerting"
 	"github.com/cloud-bulldozer/kube-burner/pkg/burner"
@@ -41,12 +46,16 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/dynamic"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 )
 
 var binName = filepath.Base(os.Args[0])
 
+// rcInterrupted return code of a run interrupted by a signal
+const rcInterrupted = 4
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   binName,
@@ -87,11 +96,16 @@ To configure your bash shell to load completions for each session execute:
 
 func initCmd() *cobra.Command {
 	var err error
-	var url, metricsEndpoint, metricsProfile, alertProfile, configFile string
-	var username, password, uuid, token, configMap, namespace, userMetadata string
-	var skipTLSVerify bool
+	var url, metricsEndpoint, metricsProfile, alertProfile string
+	var configFiles []string
+	var username, password, uuid, token, configMap, namespace, userMetadata, retryFailed, dryRunOutput string
+	var kubeconfig, kubeContext, uuidFile string
+	var skipTLSVerify, dryRun, urlFromRoute, force bool
+	var caCert string
 	var prometheusStep time.Duration
-	var timeout time.Duration
+	var timeout, progressInterval time.Duration
+	var csvDirectory string
+	var scaleFactor float64
 	var rc int
 	var metricsScraper metrics.Scraper
 	cmd := &cobra.Command{
@@ -99,27 +113,85 @@ func initCmd() *cobra.Command {
 		Short: "Launch benchmark",
 		PostRun: func(cmd *cobra.Command, args []string) {
 			log.Info("👋 Exiting kube-burner ", uuid)
-			os.Exit(rc)
+			log.Exit(rc)
 		},
 		Args: cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
+			if err := config.SetKubeConfig(kubeconfig, kubeContext); err != nil {
+				log.Fatal(err.Error())
+			}
 			if configMap != "" {
 				metricsProfile, alertProfile, err = config.FetchConfigMap(configMap, namespace)
 				if err != nil {
 					log.Fatal(err.Error())
 				}
 				// We assume configFile is config.yml
-				configFile = "config.yml"
+				configFiles = []string{"config.yml"}
 			}
-			f, err := util.ReadConfig(configFile)
+			var pulledBundle bool
+			for i, configFile := range configFiles {
+				if !strings.HasPrefix(configFile, util.OCIPrefix) {
+					continue
+				}
+				// The relative paths are resolved against the bundle directory, so there can be only one
+				if pulledBundle {
+					log.Fatal("Only one config bundle can be pulled from an OCI registry")
+				}
+				var bundleDir string
+				bundleDir, metricsProfile, alertProfile, err = pullConfigBundle(configFile, metricsProfile, alertProfile)
+				if err != nil {
+					os.RemoveAll(bundleDir)
+					log.Fatal(err.Error())
+				}
+				defer os.RemoveAll(bundleDir)
+				configFiles[i] = "config.yml"
+				pulledBundle = true
+			}
+			f, err := readConfigs(configFiles)
 			if err != nil {
-				log.Fatalf("Error reading configuration file %s: %s", configFile, err)
+				log.Fatal(err.Error())
+			}
+			// Retries reuse the UUID of the previous run unless a different one is given
+			if retryFailed != "" && !cmd.Flags().Changed("uuid") {
+				uuid = retryFailed
+			}
+			if err := config.SetScaleFactor(scaleFactor); err != nil {
+				log.Fatal(err.Error())
 			}
-			configSpec, err := config.Parse(uuid, f)
+			configSpec, err := config.Parse(uuid, f...)
 			if err != nil {
 				log.Fatalf("Config error: %s", err.Error())
 			}
-			if configSpec.GlobalConfig.IndexerConfig.Type != "" || alertProfile != "" {
+			// Written before running the jobs, so the UUID is available for the cleanup of a crashed run
+			if uuidFile != "" && !dryRun {
+				if err := util.WriteUUIDFile(uuidFile, uuid); err != nil {
+					log.Fatal(err.Error())
+				}
+			}
+			if retryFailed != "" {
+				failedIterations, err := burner.ReadFailedIterations(retryFailed)
+				if err != nil {
+					log.Fatal(err.Error())
+				}
+				configSpec.Retry = &config.Retry{UUID: retryFailed, Iterations: failedIterations}
+			}
+			if dryRunOutput != "" && !dryRun {
+				log.Fatal("--dry-run-output requires --dry-run")
+			}
+			if dryRun {
+				configSpec.DryRun = &config.DryRun{OutputDir: dryRunOutput}
+			}
+			configSpec.ProgressInterval = progressInterval
+			configSpec.Force = force
+			if csvDirectory != "" {
+				configSpec.GlobalConfig.CSVDirectory = csvDirectory
+			}
+			// Measurements and metrics are skipped in dry run mode
+			if !dryRun && (configSpec.GlobalConfig.IndexerConfig.Type != "" || alertProfile != "") {
+				if urlFromRoute && metricsEndpoint == "" {
+					discoverPrometheus(&url, &token, username)
+				}
+				tlsVerification(cmd, caCert, &skipTLSVerify)
 				metricsScraper = metrics.ProcessMetricsScraperConfig(metrics.ScraperConfig{
 					ConfigSpec:      configSpec,
 					Password:        password,
@@ -128,53 +200,152 @@ func initCmd() *cobra.Command {
 					MetricsProfile:  metricsProfile,
 					AlertProfile:    alertProfile,
 					SkipTLSVerify:   skipTLSVerify,
+					CACert:          caCert,
 					URL:             url,
 					Token:           token,
 					Username:        username,
 					UserMetaData:    userMetadata,
 				})
 			}
-			rc, err = burner.Run(configSpec, metricsScraper.PrometheusClients, metricsScraper.AlertMs, metricsScraper.Indexer, timeout, metricsScraper.Metadata)
+			if retryFailed != "" && metricsScraper.Metadata != nil {
+				metricsScraper.Metadata["retryOf"] = retryFailed
+			}
+			if scaleFactor != 1 && metricsScraper.Metadata != nil {
+				metricsScraper.Metadata["scaleFactor"] = scaleFactor
+			}
+			result, err := burner.Run(interruptContext(), configSpec, metricsScraper.PrometheusClients, metricsScraper.AlertMs, metricsScraper.Indexer, timeout, metricsScraper.Metadata)
+			rc = result.ReturnCode
 			if err != nil {
 				log.Errorf(err.Error())
-				os.Exit(rc)
+				log.Exit(rc)
 			}
 		},
 	}
 	cmd.Flags().StringVar(&uuid, "uuid", uid.NewV4().String(), "Benchmark UUID")
 	cmd.Flags().StringVarP(&url, "prometheus-url", "u", "", "Prometheus URL")
 	cmd.Flags().StringVarP(&token, "token", "t", "", "Prometheus Bearer token")
+	cmd.Flags().BoolVar(&urlFromRoute, "prometheus-url-from-route", false, "Discover the Prometheus URL and token not given from the thanos-querier route of OpenShift")
 	cmd.Flags().StringVar(&username, "username", "", "Prometheus username for authentication")
 	cmd.Flags().StringVarP(&password, "password", "p", "", "Prometheus password for basic authentication")
 	cmd.Flags().StringVarP(&metricsProfile, "metrics-profile", "m", "", "Metrics profile file or URL")
 	cmd.Flags().StringVarP(&metricsEndpoint, "metrics-endpoint", "e", "", "YAML file with a list of metric endpoints")
 	cmd.Flags().StringVarP(&alertProfile, "alert-profile", "a", "", "Alert profile file or URL")
 	cmd.Flags().BoolVar(&skipTLSVerify, "skip-tls-verify", true, "Verify prometheus TLS certificate")
+	cmd.Flags().StringVar(&caCert, "ca-cert", "", "PEM bundle of the CAs the Prometheus certificate is verified against, enables the verification unless --skip-tls-verify is given")
 	cmd.Flags().DurationVarP(&prometheusStep, "step", "s", 30*time.Second, "Prometheus step size")
 	cmd.Flags().DurationVarP(&timeout, "timeout", "", 4*time.Hour, "Benchmark timeout")
-	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Config file path or URL")
+	cmd.Flags().DurationVar(&progressInterval, "progress-interval", 30*time.Second, "Interval of the object creation progress reports, 0 disables them")
+	cmd.Flags().Float64Var(&scaleFactor, "scale-factor", 1, "Factor the jobIterations of every job are multiplied by, exposed to the configuration as .ScaleFactor")
+	cmd.Flags().StringVar(&csvDirectory, "csv-directory", "", "Directory where the measurement results are exported as CSV, overrides csvDirectory")
+	cmd.Flags().StringArrayVarP(&configFiles, "config", "c", nil, "Config file path or URL, - reads it from stdin, oci://<registry>/<repository>:<tag> pulls a config bundle. It can be given multiple times, the jobs of the files are concatenated")
 	cmd.Flags().StringVarP(&configMap, "configmap", "", "", "Configmap holding all the configuration: config.yml, metrics.yml and alerts.yml. metrics and alerts are optional")
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace where the configmap is")
 	cmd.MarkFlagsMutuallyExclusive("config", "configmap")
 	cmd.Flags().StringVar(&userMetadata, "user-metadata", "", "User provided metadata file, in YAML format")
+	cmd.Flags().StringVar(&retryFailed, "retry-failed", "", "UUID of a previous run, only its failed iterations are run")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Render the objects without creating, patching or deleting them")
+	cmd.Flags().StringVar(&dryRunOutput, "dry-run-output", "", "Directory where the objects rendered in dry run mode are written, they're logged otherwise")
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file, overrides KUBECONFIG")
+	cmd.Flags().StringVar(&kubeContext, "context", "", "Name of the kubeconfig context to use")
+	cmd.Flags().StringVar(&uuidFile, "uuid-file", "", "File where the benchmark UUID is written, it can be read by other subcommands with --uuid-from-file")
+	cmd.Flags().BoolVar(&force, "force", false, "Run the configuration even when it creates more objects than maxObjects")
 	cmd.Flags().SortFlags = false
 	return cmd
 }
 
+// readConfigs opens the given configuration files, stdin can be read only once
+func readConfigs(configFiles []string) ([]io.Reader, error) {
+	var readers []io.Reader
+	var stdin bool
+	if len(configFiles) == 0 {
+		return nil, fmt.Errorf("no configuration file given")
+	}
+	for _, configFile := range configFiles {
+		if configFile == "-" {
+			if stdin {
+				return nil, fmt.Errorf("the configuration can be read from stdin only once")
+			}
+			stdin = true
+		}
+		f, err := util.ReadConfig(configFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading configuration file %s: %s", configFile, err)
+		}
+		readers = append(readers, f)
+	}
+	return readers, nil
+}
+
+// pullConfigBundle pulls the files of the given OCI config bundle into a temporary directory, returned so it's removed
+// once the run finishes. metrics.yml and alerts.yml are used as profiles when present, unless other profiles were given
+func pullConfigBundle(reference, metricsProfile, alertProfile string) (string, string, string, error) {
+	dir, files, err := util.PullOCIBundle(reference)
+	if err != nil {
+		return "", metricsProfile, alertProfile, err
+	}
+	var hasConfig bool
+	for _, file := range files {
+		switch file {
+		case "config.yml":
+			hasConfig = true
+		case "metrics.yml":
+			if metricsProfile == "" {
+				metricsProfile = file
+			}
+		case "alerts.yml":
+			if alertProfile == "" {
+				alertProfile = file
+			}
+		}
+	}
+	if !hasConfig {
+		return dir, metricsProfile, alertProfile, fmt.Errorf("config bundle %s has no config.yml", reference)
+	}
+	return dir, metricsProfile, alertProfile, nil
+}
+
 func destroyCmd() *cobra.Command {
-	var uuid string
+	var uuid, uuidFromFile, selector, kubeconfig, kubeContext string
 	var timeout time.Duration
-	var rc int
+	var rc, deletionConcurrency int
 	cmd := &cobra.Command{
 		Use:   "destroy",
-		Short: "Destroy old namespaces labeled with the given UUID.",
+		Short: "Destroy old namespaces labeled with the given UUID or label selector.",
 		PostRun: func(cmd *cobra.Command, args []string) {
 			log.Info("👋 Exiting kube-burner ", uuid)
-			os.Exit(rc)
+			log.Exit(rc)
 		},
 		Args: cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
-			listOptions := metav1.ListOptions{LabelSelector: fmt.Sprintf("kube-burner-uuid=%s", uuid)}
+			var listOptions metav1.ListOptions
+			if uuidFromFile != "" {
+				var err error
+				if uuid, err = util.ReadUUIDFile(uuidFromFile); err != nil {
+					log.Fatal(err.Error())
+				}
+			}
+			if uuid == "" && selector == "" {
+				log.Fatal("Either --uuid, --uuid-from-file or --selector must be set")
+			}
+			if deletionConcurrency < 1 {
+				log.Fatal("--deletion-concurrency must be greater than 0")
+			}
+			if uuid != "" {
+				listOptions.LabelSelector = fmt.Sprintf("kube-burner-uuid=%s", uuid)
+			} else {
+				labelSelector, err := labels.Parse(selector)
+				if err != nil {
+					log.Fatalf("Invalid label selector %s: %s", selector, err)
+				}
+				// An empty selector would match every object of the cluster
+				if labelSelector.Empty() {
+					log.Fatal("Empty label selector not allowed")
+				}
+				listOptions.LabelSelector = labelSelector.String()
+			}
+			if err := config.SetKubeConfig(kubeconfig, kubeContext); err != nil {
+				log.Fatal(err.Error())
+			}
 			clientSet, restConfig, err := config.GetClientSet(0, 0)
 			if err != nil {
 				log.Fatalf("Error creating clientSet: %s", err)
@@ -183,13 +354,99 @@ func destroyCmd() *cobra.Command {
 			burner.DynamicClient = dynamic.NewForConfigOrDie(restConfig)
 			ctx, cancel := context.WithTimeout(context.Background(), timeout)
 			defer cancel()
-			burner.CleanupNamespaces(ctx, listOptions, true)
-			burner.CleanupNonNamespacedResources(ctx, listOptions, true)
+			burner.DeletionConcurrency = deletionConcurrency
+			if err := burner.CleanupNamespaces(ctx, listOptions, true); err != nil {
+				log.Fatal(err.Error())
+			}
+			// Objects created in reused namespaces outlive the namespaces deleted above
+			if err := burner.CleanupNamespacedResources(ctx, listOptions, true); err != nil {
+				log.Fatal(err.Error())
+			}
+			if err := burner.CleanupNonNamespacedResources(ctx, listOptions, true); err != nil {
+				log.Fatal(err.Error())
+			}
 		},
 	}
 	cmd.Flags().StringVar(&uuid, "uuid", "", "UUID")
+	cmd.Flags().StringVar(&uuidFromFile, "uuid-from-file", "", "File holding the UUID, as written by init --uuid-file")
+	cmd.Flags().StringVar(&selector, "selector", "", "Label selector, i.e. ci-run=1234,team=perf")
 	cmd.Flags().DurationVarP(&timeout, "timeout", "", 4*time.Hour, "Deletion timeout")
-	cmd.MarkFlagRequired("uuid")
+	cmd.Flags().IntVar(&deletionConcurrency, "deletion-concurrency", 10, "Maximum number of namespaces deleted in parallel")
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file, overrides KUBECONFIG")
+	cmd.Flags().StringVar(&kubeContext, "context", "", "Name of the kubeconfig context to use")
+	cmd.MarkFlagsMutuallyExclusive("uuid", "uuid-from-file", "selector")
+	return cmd
+}
+
+func verifyCmd() *cobra.Command {
+	var uuid, uuidFromFile, selector, kubeconfig, kubeContext string
+	var timeout time.Duration
+	var rc int
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the objects labeled with the given UUID or label selector are still ready, without creating anything",
+		PostRun: func(cmd *cobra.Command, args []string) {
+			log.Info("👋 Exiting kube-burner ", uuid)
+			log.Exit(rc)
+		},
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var listOptions metav1.ListOptions
+			if uuidFromFile != "" {
+				var err error
+				if uuid, err = util.ReadUUIDFile(uuidFromFile); err != nil {
+					log.Fatal(err.Error())
+				}
+			}
+			if uuid == "" && selector == "" {
+				log.Fatal("Either --uuid, --uuid-from-file or --selector must be set")
+			}
+			if uuid != "" {
+				listOptions.LabelSelector = fmt.Sprintf("kube-burner-uuid=%s", uuid)
+			} else {
+				labelSelector, err := labels.Parse(selector)
+				if err != nil {
+					log.Fatalf("Invalid label selector %s: %s", selector, err)
+				}
+				// An empty selector would match every object of the cluster
+				if labelSelector.Empty() {
+					log.Fatal("Empty label selector not allowed")
+				}
+				listOptions.LabelSelector = labelSelector.String()
+			}
+			if err := config.SetKubeConfig(kubeconfig, kubeContext); err != nil {
+				log.Fatal(err.Error())
+			}
+			clientSet, restConfig, err := config.GetClientSet(0, 0)
+			if err != nil {
+				log.Fatalf("Error creating clientSet: %s", err)
+			}
+			burner.ClientSet = clientSet
+			burner.DynamicClient = dynamic.NewForConfigOrDie(restConfig)
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			log.Infof("Verifying the readiness of the objects matching %s", listOptions.LabelSelector)
+			checked, notReady, err := burner.VerifyReadiness(ctx, listOptions)
+			if err != nil {
+				log.Error(err.Error())
+				rc = 1
+			}
+			for _, obj := range notReady {
+				log.Errorf("%s %s/%s not ready: %s", obj.Kind, obj.Namespace, obj.Name, obj.Reason)
+			}
+			log.Infof("%d/%d objects ready", checked-len(notReady), checked)
+			if len(notReady) > 0 {
+				rc = 1
+			}
+		},
+	}
+	cmd.Flags().StringVar(&uuid, "uuid", "", "UUID")
+	cmd.Flags().StringVar(&uuidFromFile, "uuid-from-file", "", "File holding the UUID, as written by init --uuid-file")
+	cmd.Flags().StringVar(&selector, "selector", "", "Label selector, i.e. ci-run=1234,team=perf")
+	cmd.Flags().DurationVarP(&timeout, "timeout", "", 10*time.Minute, "Verification timeout")
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file, overrides KUBECONFIG")
+	cmd.Flags().StringVar(&kubeContext, "context", "", "Name of the kubeconfig context to use")
+	cmd.MarkFlagsMutuallyExclusive("uuid", "uuid-from-file", "selector")
 	return cmd
 }
 
@@ -200,6 +457,9 @@ func measureCmd() *cobra.Command {
 	var configFile string
 	var jobName string
 	var userMetadata string
+	var kubeconfig, kubeContext string
+	var watch bool
+	var duration, flushInterval time.Duration
 	var indexer *indexers.Indexer
 	metadata := make(map[string]interface{})
 	cmd := &cobra.Command{
@@ -210,6 +470,9 @@ func measureCmd() *cobra.Command {
 		},
 		Args: cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
+			if err := config.SetKubeConfig(kubeconfig, kubeContext); err != nil {
+				log.Fatal(err.Error())
+			}
 			f, err := util.ReadConfig(configFile)
 			if err != nil {
 				log.Fatalf("Error reading configuration file %s: %s", configFile, err)
@@ -224,7 +487,7 @@ func measureCmd() *cobra.Command {
 			if configSpec.GlobalConfig.IndexerConfig.Type != "" {
 				indexerConfig := configSpec.GlobalConfig.IndexerConfig
 				log.Infof("📁 Creating indexer: %s", indexerConfig.Type)
-				indexer, err = indexers.NewIndexer(indexerConfig)
+				indexer, err = metrics.NewIndexers(configSpec.GlobalConfig.IndexerConfigs())
 				if err != nil {
 					log.Fatalf("%v indexer: %v", indexerConfig.Type, err.Error())
 				}
@@ -245,13 +508,19 @@ func measureCmd() *cobra.Command {
 				namespaceLabels[req.Key()] = req.Values().List()[0]
 			}
 			log.Infof("%v", namespaceLabels)
-			measurements.NewMeasurementFactory(configSpec, indexer, metadata)
+			if err := measurements.NewMeasurementFactory(configSpec, indexer, metadata); err != nil {
+				log.Fatal(err.Error())
+			}
 			measurements.SetJobConfig(&config.Job{
 				Name:            jobName,
 				Namespace:       rawNamespaces,
 				NamespaceLabels: namespaceLabels,
 			})
-			measurements.Collect()
+			if watch || duration > 0 {
+				watchMeasurements(duration, flushInterval)
+			} else {
+				measurements.Collect()
+			}
 			if err = measurements.Stop(); err != nil {
 				log.Error(err.Error())
 			}
@@ -259,20 +528,76 @@ func measureCmd() *cobra.Command {
 	}
 	cmd.Flags().StringVar(&uuid, "uuid", "", "UUID")
 	cmd.Flags().StringVar(&userMetadata, "user-metadata", "", "User provided metadata file, in YAML format")
-	cmd.Flags().StringVarP(&configFile, "config", "c", "config.yml", "Config file path or URL")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "config.yml", "Config file path or URL, - reads it from stdin")
 	cmd.Flags().StringVarP(&jobName, "job-name", "j", "kube-burner-measure", "Measure job name")
 	cmd.Flags().StringVarP(&rawNamespaces, "namespaces", "n", corev1.NamespaceAll, "comma-separated list of namespaces")
 	cmd.Flags().StringVarP(&selector, "selector", "l", "", "namespace label selector. (e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().BoolVar(&watch, "watch", false, "Keep measuring the resources as they change until interrupted")
+	cmd.Flags().DurationVar(&duration, "duration", 0, "Keep measuring the resources as they change for the given duration, it implies --watch")
+	cmd.Flags().DurationVar(&flushInterval, "flush-interval", 5*time.Minute, "Interval the measurements completed so far are indexed at in watch mode, 0 indexes them only at the end")
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file, overrides KUBECONFIG")
+	cmd.Flags().StringVar(&kubeContext, "context", "", "Name of the kubeconfig context to use")
 	return cmd
 }
 
+// interruptContext returns a context cancelled on the first SIGINT or SIGTERM, so the run stops gracefully, a second
+// signal exits the process immediately
+func interruptContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	signalCh := make(chan os.Signal, 1)
+	signal.Notify(signalCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-signalCh
+		log.Warnf("Received %v, stopping gracefully, send it again to exit immediately", sig)
+		cancel()
+		sig = <-signalCh
+		log.Errorf("Received %v again, exiting immediately", sig)
+		os.Exit(rcInterrupted)
+	}()
+	return ctx
+}
+
+// watchMeasurements keeps the measurement informers running until the given duration elapses, or until interrupted
+// when it's 0, indexing the datapoints completed so far every flush interval
+func watchMeasurements(duration, flushInterval time.Duration) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+		log.Infof("Watching resources for %v", duration)
+	} else {
+		log.Info("Watching resources until interrupted")
+	}
+	measurements.Start()
+	var flushCh <-chan time.Time
+	if flushInterval > 0 {
+		ticker := time.NewTicker(flushInterval)
+		defer ticker.Stop()
+		flushCh = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Stopping measurements")
+			return
+		case <-flushCh:
+			if err := measurements.Flush(); err != nil {
+				log.Error(err.Error())
+			}
+		}
+	}
+}
+
 func indexCmd() *cobra.Command {
 	var url, metricsEndpoint, metricsProfile, jobName string
 	var start, end int64
-	var username, password, uuid, token, userMetadata string
+	var username, password, uuid, uuidFromFile, token, userMetadata string
 	var esServer, esIndex, metricsDirectory string
 	var configSpec config.Spec
-	var skipTLSVerify bool
+	var skipTLSVerify, urlFromRoute bool
+	var caCert string
 	var prometheusStep time.Duration
 	var tarballName string
 	cmd := &cobra.Command{
@@ -284,19 +609,18 @@ func indexCmd() *cobra.Command {
 			log.Info("👋 Exiting kube-burner ", uuid)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			configSpec.GlobalConfig.UUID = uuid
-			if esServer != "" && esIndex != "" {
-				configSpec.GlobalConfig.IndexerConfig = indexers.IndexerConfig{
-					Type:    indexers.ElasticIndexer,
-					Servers: []string{esServer},
-					Index:   esIndex,
-				}
-			} else {
-				configSpec.GlobalConfig.IndexerConfig = indexers.IndexerConfig{
-					Type:             indexers.LocalIndexer,
-					MetricsDirectory: metricsDirectory,
+			if uuidFromFile != "" {
+				var err error
+				if uuid, err = util.ReadUUIDFile(uuidFromFile); err != nil {
+					log.Fatal(err.Error())
 				}
 			}
+			configSpec.GlobalConfig.UUID = uuid
+			configSpec.GlobalConfig.IndexerConfig = flagsIndexerConfig(esServer, esIndex, metricsDirectory)
+			if urlFromRoute && metricsEndpoint == "" {
+				discoverPrometheus(&url, &token, username)
+			}
+			tlsVerification(cmd, caCert, &skipTLSVerify)
 			metricsScraper := metrics.ProcessMetricsScraperConfig(metrics.ScraperConfig{
 				ConfigSpec:      configSpec,
 				Password:        password,
@@ -304,6 +628,7 @@ func indexCmd() *cobra.Command {
 				MetricsEndpoint: metricsEndpoint,
 				MetricsProfile:  metricsProfile,
 				SkipTLSVerify:   skipTLSVerify,
+				CACert:          caCert,
 				URL:             url,
 				Token:           token,
 				Username:        username,
@@ -319,27 +644,26 @@ func indexCmd() *cobra.Command {
 					},
 				}
 				prometheusClients.JobList = append(prometheusClients.JobList, prometheusJob)
-				if err := prometheusClients.ScrapeJobsMetrics(docsToIndex); err != nil {
+				if err := prometheusClients.ScrapeJobsMetrics(context.TODO(), docsToIndex); err != nil {
 					log.Fatal(err)
 				}
 			}
 			log.Infof("Indexing metrics with UUID %s", uuid)
-			metrics.IndexDatapoints(docsToIndex, configSpec.GlobalConfig.IndexerConfig.Type, metricsScraper.Indexer)
-			if configSpec.GlobalConfig.IndexerConfig.Type == indexers.LocalIndexer && tarballName != "" {
-				if err := metrics.CreateTarball(configSpec.GlobalConfig.IndexerConfig, tarballName); err != nil {
-					log.Fatal(err)
-				}
-			}
+			indexDocuments(docsToIndex, configSpec.GlobalConfig.IndexerConfig, metricsScraper.Indexer, tarballName)
 		},
 	}
 	cmd.Flags().StringVar(&uuid, "uuid", uid.NewV4().String(), "Benchmark UUID")
+	cmd.Flags().StringVar(&uuidFromFile, "uuid-from-file", "", "File holding the benchmark UUID, as written by init --uuid-file")
+	cmd.MarkFlagsMutuallyExclusive("uuid", "uuid-from-file")
 	cmd.Flags().StringVarP(&url, "prometheus-url", "u", "", "Prometheus URL")
 	cmd.Flags().StringVarP(&token, "token", "t", "", "Prometheus Bearer token")
+	cmd.Flags().BoolVar(&urlFromRoute, "prometheus-url-from-route", false, "Discover the Prometheus URL and token not given from the thanos-querier route of OpenShift")
 	cmd.Flags().StringVar(&username, "username", "", "Prometheus username for authentication")
 	cmd.Flags().StringVarP(&password, "password", "p", "", "Prometheus password for basic authentication")
 	cmd.Flags().StringVarP(&metricsProfile, "metrics-profile", "m", "metrics.yml", "Metrics profile file")
 	cmd.Flags().StringVarP(&metricsEndpoint, "metrics-endpoint", "e", "", "YAML file with a list of metric endpoints")
 	cmd.Flags().BoolVar(&skipTLSVerify, "skip-tls-verify", true, "Verify prometheus TLS certificate")
+	cmd.Flags().StringVar(&caCert, "ca-cert", "", "PEM bundle of the CAs the Prometheus certificate is verified against, enables the verification unless --skip-tls-verify is given")
 	cmd.Flags().DurationVarP(&prometheusStep, "step", "s", 30*time.Second, "Prometheus step size")
 	cmd.Flags().Int64VarP(&start, "start", "", time.Now().Unix()-3600, "Epoch start time")
 	cmd.Flags().Int64VarP(&end, "end", "", time.Now().Unix(), "Epoch end time")
@@ -353,33 +677,175 @@ func indexCmd() *cobra.Command {
 	return cmd
 }
 
+func snapshotCmd() *cobra.Command {
+	var url, metricsEndpoint, metricsProfile, jobName string
+	var timestamp int64
+	var username, password, uuid, uuidFromFile, token, userMetadata string
+	var esServer, esIndex, metricsDirectory string
+	var configSpec config.Spec
+	var skipTLSVerify, urlFromRoute bool
+	var caCert string
+	var prometheusStep time.Duration
+	var tarballName string
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Index a point-in-time snapshot of the metrics profile",
+		Long:  "Runs the queries of the metrics profile as instant queries at the given time. If no other indexer is specified, local indexer is used by default",
+		Args:  cobra.NoArgs,
+		PostRun: func(cmd *cobra.Command, args []string) {
+			log.Info("👋 Exiting kube-burner ", uuid)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if uuidFromFile != "" {
+				var err error
+				if uuid, err = util.ReadUUIDFile(uuidFromFile); err != nil {
+					log.Fatal(err.Error())
+				}
+			}
+			configSpec.GlobalConfig.UUID = uuid
+			configSpec.GlobalConfig.IndexerConfig = flagsIndexerConfig(esServer, esIndex, metricsDirectory)
+			if urlFromRoute && metricsEndpoint == "" {
+				discoverPrometheus(&url, &token, username)
+			}
+			tlsVerification(cmd, caCert, &skipTLSVerify)
+			metricsScraper := metrics.ProcessMetricsScraperConfig(metrics.ScraperConfig{
+				ConfigSpec:      configSpec,
+				Password:        password,
+				PrometheusStep:  prometheusStep,
+				MetricsEndpoint: metricsEndpoint,
+				MetricsProfile:  metricsProfile,
+				SkipTLSVerify:   skipTLSVerify,
+				CACert:          caCert,
+				URL:             url,
+				Token:           token,
+				Username:        username,
+				UserMetaData:    userMetadata,
+			})
+			snapshotTime := time.Now().UTC()
+			if cmd.Flags().Changed("time") {
+				snapshotTime = time.Unix(timestamp, 0).UTC()
+			}
+			docsToIndex := make(map[string][]interface{})
+			for _, prometheusClient := range metricsScraper.PrometheusClients {
+				prometheusClient.SnapshotMetrics(snapshotTime, config.Job{Name: jobName}, docsToIndex)
+			}
+			log.Infof("Indexing metrics snapshot with UUID %s", uuid)
+			indexDocuments(docsToIndex, configSpec.GlobalConfig.IndexerConfig, metricsScraper.Indexer, tarballName)
+		},
+	}
+	cmd.Flags().StringVar(&uuid, "uuid", uid.NewV4().String(), "Benchmark UUID")
+	cmd.Flags().StringVar(&uuidFromFile, "uuid-from-file", "", "File holding the benchmark UUID, as written by init --uuid-file")
+	cmd.MarkFlagsMutuallyExclusive("uuid", "uuid-from-file")
+	cmd.Flags().StringVarP(&url, "prometheus-url", "u", "", "Prometheus URL")
+	cmd.Flags().StringVarP(&token, "token", "t", "", "Prometheus Bearer token")
+	cmd.Flags().BoolVar(&urlFromRoute, "prometheus-url-from-route", false, "Discover the Prometheus URL and token not given from the thanos-querier route of OpenShift")
+	cmd.Flags().StringVar(&username, "username", "", "Prometheus username for authentication")
+	cmd.Flags().StringVarP(&password, "password", "p", "", "Prometheus password for basic authentication")
+	cmd.Flags().StringVarP(&metricsProfile, "metrics-profile", "m", "metrics.yml", "Metrics profile file")
+	cmd.Flags().StringVarP(&metricsEndpoint, "metrics-endpoint", "e", "", "YAML file with a list of metric endpoints")
+	cmd.Flags().BoolVar(&skipTLSVerify, "skip-tls-verify", true, "Verify prometheus TLS certificate")
+	cmd.Flags().StringVar(&caCert, "ca-cert", "", "PEM bundle of the CAs the Prometheus certificate is verified against, enables the verification unless --skip-tls-verify is given")
+	cmd.Flags().DurationVarP(&prometheusStep, "step", "s", 30*time.Second, "Prometheus step size, queries using {{.elapsed}} get it as value")
+	cmd.Flags().Int64Var(&timestamp, "time", 0, "Epoch time of the snapshot, now by default")
+	cmd.Flags().StringVarP(&jobName, "job-name", "j", "kube-burner-snapshot", "Snapshot job name")
+	cmd.Flags().StringVar(&userMetadata, "user-metadata", "", "User provided metadata file, in YAML format")
+	cmd.Flags().StringVar(&metricsDirectory, "metrics-directory", "collected-metrics", "Directory to dump the metrics files in, when using default local indexing")
+	cmd.Flags().StringVar(&esServer, "es-server", "", "Elastic Search endpoint")
+	cmd.Flags().StringVar(&esIndex, "es-index", "", "Elastic Search index")
+	cmd.Flags().StringVar(&tarballName, "tarball-name", "", "Dump collected metrics into a tarball with the given name, requires local indexing")
+	cmd.Flags().SortFlags = false
+	return cmd
+}
+
+// discoverPrometheus fills the Prometheus URL and token not given by flags with the ones discovered from the OpenShift
+// monitoring stack, the same way the ocp workloads do, the explicit flags are kept when the discovery fails
+func discoverPrometheus(url, token *string, username string) {
+	if *url != "" && (*token != "" || username != "") {
+		return
+	}
+	_, restConfig, err := config.GetClientSet(0, 0)
+	if err != nil {
+		log.Warnf("Prometheus discovery failed, using the given flags: %s", err)
+		return
+	}
+	ocpMetadata, err := ocpmetadata.NewMetadata(restConfig)
+	if err != nil {
+		log.Warnf("Prometheus discovery failed, using the given flags: %s", err)
+		return
+	}
+	discoveredURL, discoveredToken, err := ocpMetadata.GetPrometheus()
+	if err != nil {
+		log.Warnf("Prometheus discovery failed, using the given flags: %s", err)
+		return
+	}
+	if *url == "" {
+		*url = discoveredURL
+		log.Infof("Discovered Prometheus URL %s", *url)
+	}
+	if *token == "" && username == "" {
+		*token = discoveredToken
+	}
+}
+
+// tlsVerification enables the verification of the Prometheus certificate when a CA bundle is given, unless --skip-tls-verify was explicitly set
+func tlsVerification(cmd *cobra.Command, caCert string, skipTLSVerify *bool) {
+	if caCert != "" && !cmd.Flags().Changed("skip-tls-verify") {
+		*skipTLSVerify = false
+	}
+}
+
+// flagsIndexerConfig returns the configuration of the indexer given by the --es-server and --es-index flags,
+// the local indexer writing to metricsDirectory is used when they're not set
+func flagsIndexerConfig(esServer, esIndex, metricsDirectory string) config.IndexerConfig {
+	if esServer != "" && esIndex != "" {
+		return config.IndexerConfig{
+			IndexerConfig: indexers.IndexerConfig{
+				Type:    indexers.ElasticIndexer,
+				Servers: []string{esServer},
+				Index:   esIndex,
+			},
+		}
+	}
+	return config.IndexerConfig{
+		IndexerConfig: indexers.IndexerConfig{
+			Type:             indexers.LocalIndexer,
+			MetricsDirectory: metricsDirectory,
+		},
+	}
+}
+
+// indexDocuments indexes the collected documents and, with the local indexer, dumps them into the given tarball
+func indexDocuments(docsToIndex map[string][]interface{}, indexerConfig config.IndexerConfig, indexer *indexers.Indexer, tarballName string) {
+	metrics.IndexDatapoints(docsToIndex, indexerConfig.Type, indexer)
+	metrics.LogIndexingSummary(indexer)
+	if indexerConfig.Type == indexers.LocalIndexer && tarballName != "" {
+		if err := metrics.CreateTarball(indexerConfig, tarballName); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
 func importCmd() *cobra.Command {
-	var tarball string
+	var tarball, uuid string
 	var esServer, esIndex, metricsDirectory string
+	var setMetadata map[string]string
 	var configSpec config.Spec
 	cmd := &cobra.Command{
 		Use:   "import",
 		Short: "Import metrics tarball",
 		Run: func(cmd *cobra.Command, args []string) {
-			if esServer != "" && esIndex != "" {
-				configSpec.GlobalConfig.IndexerConfig = indexers.IndexerConfig{
-					Type:    indexers.ElasticIndexer,
-					Servers: []string{esServer},
-					Index:   esIndex,
-				}
-			} else {
-				configSpec.GlobalConfig.IndexerConfig = indexers.IndexerConfig{
-					Type:             indexers.LocalIndexer,
-					MetricsDirectory: metricsDirectory,
-				}
-			}
+			configSpec.GlobalConfig.IndexerConfig = flagsIndexerConfig(esServer, esIndex, metricsDirectory)
 			indexerConfig := configSpec.GlobalConfig.IndexerConfig
 			log.Infof("📁 Creating indexer: %s", indexerConfig.Type)
-			indexer, err := indexers.NewIndexer(indexerConfig)
+			indexer, err := metrics.NewIndexer(indexerConfig)
 			if err != nil {
 				log.Fatal(err.Error())
 			}
-			err = metrics.ImportTarball(tarball, indexer, indexerConfig.MetricsDirectory)
+			var transform metrics.DocumentTransform
+			if uuid != "" || len(setMetadata) > 0 {
+				transform = metrics.RelabelDocuments(uuid, setMetadata)
+			}
+			err = metrics.ImportTarball(tarball, indexer, indexerConfig.MetricsDirectory, transform)
 			if err != nil {
 				log.Fatal(err.Error())
 			}
@@ -389,17 +855,95 @@ func importCmd() *cobra.Command {
 	cmd.Flags().StringVar(&metricsDirectory, "metrics-directory", "collected-metrics", "Directory to dump the metrics files in, when using default local indexing")
 	cmd.Flags().StringVar(&esServer, "es-server", "", "Elastic Search endpoint")
 	cmd.Flags().StringVar(&esIndex, "es-index", "", "Elastic Search index")
+	cmd.Flags().StringVar(&uuid, "uuid", "", "Override the uuid of the imported documents")
+	cmd.Flags().StringToStringVar(&setMetadata, "set-metadata", map[string]string{}, "Metadata merged into every imported document, as key=value pairs")
 	cmd.MarkFlagRequired("tarball")
 	return cmd
 }
 
+func mergeCmd() *cobra.Command {
+	var metricsDirectories, dedupeKeys []string
+	var esServer, esIndex, outputDirectory string
+	cmd := &cobra.Command{
+		Use:   "merge",
+		Short: "Merge and index metrics from several local metrics directories",
+		Long:  "If no other indexer is specified, local indexer is used by default",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			indexerConfig := flagsIndexerConfig(esServer, esIndex, outputDirectory)
+			docsToIndex, err := metrics.LoadMetricsDirectories(metricsDirectories)
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+			if len(dedupeKeys) > 0 {
+				removed := metrics.DedupeDocuments(docsToIndex, dedupeKeys)
+				log.Infof("Removed %d duplicated documents", removed)
+			}
+			log.Infof("📁 Creating indexer: %s", indexerConfig.Type)
+			indexer, err := metrics.NewIndexer(indexerConfig)
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+			metrics.IndexDatapoints(docsToIndex, indexerConfig.Type, indexer)
+		},
+	}
+	cmd.Flags().StringSliceVar(&metricsDirectories, "metrics-directory", []string{}, "Comma-separated list of metrics directories or glob patterns to merge")
+	cmd.Flags().StringSliceVar(&dedupeKeys, "dedupe-key", []string{}, "Comma-separated list of document keys, documents of the same metric with equal values are indexed once")
+	cmd.Flags().StringVar(&outputDirectory, "output-directory", "merged-metrics", "Directory to dump the merged metrics files in, when using default local indexing")
+	cmd.Flags().StringVar(&esServer, "es-server", "", "Elastic Search endpoint")
+	cmd.Flags().StringVar(&esIndex, "es-index", "", "Elastic Search index")
+	cmd.MarkFlagRequired("metrics-directory")
+	cmd.Flags().SortFlags = false
+	return cmd
+}
+
+func validateCmd() *cobra.Command {
+	var configFiles []string
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate a configuration without contacting the cluster",
+		Long:  "Parses the configuration, renders its object templates with sample iteration variables and checks the referenced files, selectors and durations. It never contacts the API server nor Prometheus",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			f, err := readConfigs(configFiles)
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+			var errs []error
+			configSpec, err := config.Parse(uid.NewV4().String(), f...)
+			if err != nil {
+				// Validation errors are aggregated, the rest prevent parsing the configuration at all
+				agg, ok := err.(utilerrors.Aggregate)
+				if !ok {
+					log.Fatalf("Config error: %s", err.Error())
+				}
+				errs = agg.Errors()
+			}
+			errs = append(errs, burner.Validate(configSpec)...)
+			for _, err := range errs {
+				log.Error(err.Error())
+			}
+			configFile := strings.Join(configFiles, ", ")
+			if len(errs) > 0 {
+				log.Fatalf("%d problems found in %s", len(errs), configFile)
+			}
+			log.Infof("Configuration %s is valid", configFile)
+		},
+	}
+	cmd.Flags().StringArrayVarP(&configFiles, "config", "c", nil, "Config file path or URL, - reads it from stdin. It can be given multiple times, the jobs of the files are concatenated")
+	cmd.MarkFlagRequired("config")
+	return cmd
+}
+
 func alertCmd() *cobra.Command {
 	var configSpec config.Spec
 	var err error
-	var url, alertProfile, username, password, uuid, token string
+	var url, alertProfile, username, password, uuid, uuidFromFile, token, output string
 	var esServer, esIndex, metricsDirectory string
+	var jobSummaries []string
 	var start, end int64
 	var skipTLSVerify bool
+	var caCert string
 	var alertM *alerting.AlertManager
 	var prometheusStep time.Duration
 	var indexer *indexers.Indexer
@@ -408,62 +952,101 @@ func alertCmd() *cobra.Command {
 		Short: "Evaluate alerts for the given time range",
 		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
+			if uuidFromFile != "" {
+				var err error
+				if uuid, err = util.ReadUUIDFile(uuidFromFile); err != nil {
+					log.Fatal(err.Error())
+				}
+			}
 			configSpec.GlobalConfig.UUID = uuid
 			if esServer != "" && esIndex != "" {
-				configSpec.GlobalConfig.IndexerConfig = indexers.IndexerConfig{
-					Type:    indexers.ElasticIndexer,
-					Servers: []string{esServer},
-					Index:   esIndex,
+				configSpec.GlobalConfig.IndexerConfig = config.IndexerConfig{
+					IndexerConfig: indexers.IndexerConfig{
+						Type:    indexers.ElasticIndexer,
+						Servers: []string{esServer},
+						Index:   esIndex,
+					},
 				}
 			} else if metricsDirectory != "" {
-				configSpec.GlobalConfig.IndexerConfig = indexers.IndexerConfig{
-					Type:             indexers.LocalIndexer,
-					MetricsDirectory: metricsDirectory,
+				configSpec.GlobalConfig.IndexerConfig = config.IndexerConfig{
+					IndexerConfig: indexers.IndexerConfig{
+						Type:             indexers.LocalIndexer,
+						MetricsDirectory: metricsDirectory,
+					},
 				}
 			}
 			if configSpec.GlobalConfig.IndexerConfig.Type != "" {
 				indexerConfig := configSpec.GlobalConfig.IndexerConfig
 				log.Infof("📁 Creating indexer: %s", indexerConfig.Type)
-				indexer, err = indexers.NewIndexer(indexerConfig)
+				indexer, err = metrics.NewIndexer(indexerConfig)
 				if err != nil {
 					log.Fatal(err.Error())
 				}
 			}
+			tlsVerification(cmd, caCert, &skipTLSVerify)
 			auth := prometheus.Auth{
 				Username:      username,
 				Password:      password,
 				Token:         token,
 				SkipTLSVerify: skipTLSVerify,
+				CACert:        caCert,
 			}
 			p, err := prometheus.NewPrometheusClient(configSpec, url, auth, prometheusStep, map[string]interface{}{}, false)
 			if err != nil {
 				log.Fatal(err)
 			}
-			startTime := time.Unix(start, 0)
-			endTime := time.Unix(end, 0)
+			jobList := []prometheus.Job{{
+				Start: time.Unix(start, 0),
+				End:   time.Unix(end, 0),
+				JobConfig: config.Job{
+					Name: "kube-burner-check-alerts",
+				},
+			}}
+			if len(jobSummaries) > 0 {
+				if jobList, err = prometheus.ReadJobSummaries(jobSummaries); err != nil {
+					log.Fatal(err)
+				}
+			}
 			if alertM, err = alerting.NewAlertManager(alertProfile, uuid, indexer, p, false); err != nil {
 				log.Fatalf("Error creating alert manager: %s", err)
 			}
-			err = alertM.Evaluate(startTime, endTime)
+			alerts, err := alertM.EvaluateJobs(jobList)
+			if output != "" {
+				if reportErr := alerting.WriteReport(output, alerts); reportErr != nil {
+					log.Fatal(reportErr)
+				}
+				var fired int
+				for _, alert := range alerts {
+					if alert.Fired {
+						fired++
+					}
+				}
+				log.Infof("Alert report with %d fired alerts out of %d results written to %s", fired, len(alerts), output)
+			}
 			log.Info("👋 Exiting kube-burner ", uuid)
 			if err != nil {
-				os.Exit(1)
+				log.Exit(1)
 			}
 		},
 	}
 	cmd.Flags().StringVar(&uuid, "uuid", uid.NewV4().String(), "Benchmark UUID")
+	cmd.Flags().StringVar(&uuidFromFile, "uuid-from-file", "", "File holding the benchmark UUID, as written by init --uuid-file")
+	cmd.MarkFlagsMutuallyExclusive("uuid", "uuid-from-file")
 	cmd.Flags().StringVarP(&url, "prometheus-url", "u", "", "Prometheus URL")
 	cmd.Flags().StringVarP(&token, "token", "t", "", "Prometheus Bearer token")
 	cmd.Flags().StringVar(&username, "username", "", "Prometheus username for authentication")
 	cmd.Flags().StringVarP(&password, "password", "p", "", "Prometheus password for basic authentication")
 	cmd.Flags().StringVarP(&alertProfile, "alert-profile", "a", "alerts.yaml", "Alert profile file or URL")
 	cmd.Flags().BoolVar(&skipTLSVerify, "skip-tls-verify", true, "Verify prometheus TLS certificate")
+	cmd.Flags().StringVar(&caCert, "ca-cert", "", "PEM bundle of the CAs the Prometheus certificate is verified against, enables the verification unless --skip-tls-verify is given")
 	cmd.Flags().DurationVarP(&prometheusStep, "step", "s", 30*time.Second, "Prometheus step size")
 	cmd.Flags().Int64VarP(&start, "start", "", time.Now().Unix()-3600, "Epoch start time")
 	cmd.Flags().Int64VarP(&end, "end", "", time.Now().Unix(), "Epoch end time")
+	cmd.Flags().StringSliceVar(&jobSummaries, "job-summary", []string{}, "jobSummary files or URLs, alerts are evaluated within the time window of each job, overrides --start and --end")
 	cmd.Flags().StringVar(&metricsDirectory, "metrics-directory", "", "Directory to dump the alert files in, enables local indexing when specified")
 	cmd.Flags().StringVar(&esServer, "es-server", "", "Elastic Search endpoint")
 	cmd.Flags().StringVar(&esIndex, "es-index", "", "Elastic Search index")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write a JSON report of the fired alerts to this file")
 	cmd.MarkFlagRequired("prometheus-url")
 	cmd.MarkFlagRequired("alert-profile")
 	cmd.Flags().SortFlags = false
@@ -477,12 +1060,19 @@ func main() {
 		initCmd(),
 		measureCmd(),
 		destroyCmd(),
+		verifyCmd(),
 		indexCmd(),
+		snapshotCmd(),
 		alertCmd(),
 		importCmd(),
+		mergeCmd(),
+		validateCmd(),
 		openShiftCmd(),
 	)
 	logLevel := rootCmd.PersistentFlags().String("log-level", "info", "Allowed values: debug, info, warn, error, fatal")
+	logFile := rootCmd.PersistentFlags().String("log-file", "", "Write logs to this file in addition to stderr")
+	logFormat := rootCmd.PersistentFlags().String("log-format", "text", "Log format, allowed values: text, json")
+	proxy := rootCmd.PersistentFlags().String("proxy", "", "Proxy URL used by the Prometheus and indexer clients, overrides HTTP_PROXY and HTTPS_PROXY")
 	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
 		log.SetReportCaller(true)
 		formatter := &log.TextFormatter{
@@ -493,15 +1083,25 @@ func main() {
 				return "", fmt.Sprintf("%s:%d", path.Base(f.File), f.Line)
 			},
 		}
-		log.SetFormatter(formatter)
+		if *logFormat != "text" && *logFormat != "json" {
+			log.Fatalf("Unknown log format %s", *logFormat)
+		}
+		if err := util.SetupLogging(*logFile, *logFormat == "json", formatter); err != nil {
+			log.Fatal(err.Error())
+		}
 		lvl, err := log.ParseLevel(*logLevel)
 		if err != nil {
 			log.Fatalf("Unknown log level %s", *logLevel)
 		}
 		log.SetLevel(lvl)
+		if err := util.SetHTTPProxy(*proxy); err != nil {
+			log.Fatal(err.Error())
+		}
 	}
 	rootCmd.AddCommand(completionCmd)
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		log.Exit(1)
 	}
+	// Run the exit handlers so the log file gets closed
+	log.Exit(0)
 }
@@ -17,7 +17,6 @@ package main
 import (
 	"embed"
 	_ "embed"
-	"os"
 	"time"
 
 	"github.com/cloud-bulldozer/go-commons/indexers"
@@ -69,13 +68,14 @@ func openShiftCmd() *cobra.Command {
 			if err := wh.ExtractWorkload(cmd.Name(), workloads.MetricsProfileMap[cmd.Name()]); err != nil {
 				log.Fatal(err)
 			}
-			os.Exit(0)
+			log.Exit(0)
 		}
 		err := wh.GatherMetadata(*userMetadata)
 		if err != nil {
 			log.Fatal(err.Error())
 		}
 		wh.SetKubeBurnerFlags()
+		wh.Context = interruptContext()
 	}
 	ocpCmd.AddCommand(
 		workloads.NewClusterDensity(&wh, "cluster-density-v2"),
@@ -21,6 +21,7 @@ import (
 	"math"
 	"path"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -29,29 +30,36 @@ import (
 	"github.com/cloud-bulldozer/kube-burner/pkg/util"
 	"github.com/prometheus/common/model"
 	log "github.com/sirupsen/logrus"
-	"gopkg.in/yaml.v3"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 )
 
 type severityLevel string
 
 const (
+	sevInfo         severityLevel = "info"
 	sevWarn         severityLevel = "warning"
 	sevError        severityLevel = "error"
 	sevCritical     severityLevel = "critical"
 	alertMetricName               = "alert"
 )
 
-// alertProfile expression list
-type alertProfile []struct {
+// alertDefinition describes an alert or an include directive
+type alertDefinition struct {
+	// Name of the alert, a later definition with the same name overrides it
+	Name string `yaml:"name"`
 	// PromQL expression to evaluate
 	Expr string `yaml:"expr"`
 	// Informative comment reported when the alarm is triggered
 	Description string `yaml:"description"`
 	// Alert Severity
 	Severity severityLevel `yaml:"severity"`
+	// Include path or URL of another alert profile to merge at this position
+	Include string `yaml:"include"`
 }
 
+// alertProfile expression list
+type alertProfile []alertDefinition
+
 // alert definition
 type alert struct {
 	Timestamp   time.Time     `json:"timestamp"`
@@ -59,6 +67,35 @@ type alert struct {
 	Severity    severityLevel `json:"severity"`
 	Description string        `json:"description"`
 	MetricName  string        `json:"metricName"`
+	JobName     string        `json:"jobName,omitempty"`
+	// labels of the series that fired the alert
+	labels map[string]string
+}
+
+// AlertResult describes the outcome of an alert evaluated within a job, there's one result per matched series of
+// the fired alerts, and one without series for the alerts that didn't fire or couldn't be evaluated
+type AlertResult struct {
+	// Expr rendered PromQL expression of the alert
+	Expr string `json:"expr"`
+	// Description rendered alert description, only set when the alert fired
+	Description string `json:"description,omitempty"`
+	// Severity of the alert
+	Severity string `json:"severity"`
+	// JobName job within which the alert was evaluated
+	JobName string `json:"jobName,omitempty"`
+	// Timestamp first time the alert fired for the matched series
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	// Labels of the matched series
+	Labels map[string]string `json:"labels,omitempty"`
+	// Fired true when the alert expression matched series
+	Fired bool `json:"fired"`
+	// Error set when the alert couldn't be evaluated, e.g. its query failed
+	Error string `json:"error,omitempty"`
+}
+
+// Critical returns true when the alert has critical severity
+func (r AlertResult) Critical() bool {
+	return r.Severity == string(sevCritical)
 }
 
 // AlertManager configuration
@@ -67,6 +104,10 @@ type AlertManager struct {
 	prometheus   *prometheus.Prometheus
 	indexer      *indexers.Indexer
 	uuid         string
+	// indexed keys of the alerts already indexed, so the alerts fired by both the live and the post-run evaluations,
+	// or by consecutive live evaluations, are indexed once
+	indexed     map[string]struct{}
+	indexedLock sync.Mutex
 }
 
 var baseTemplate = []string{
@@ -86,6 +127,7 @@ func NewAlertManager(alertProfileCfg, uuid string, indexer *indexers.Indexer, pr
 		prometheus: prometheusClient,
 		uuid:       uuid,
 		indexer:    indexer,
+		indexed:    make(map[string]struct{}),
 	}
 	if err := a.readProfile(alertProfileCfg, embedConfig); err != nil {
 		return &a, err
@@ -94,34 +136,96 @@ func NewAlertManager(alertProfileCfg, uuid string, indexer *indexers.Indexer, pr
 }
 
 func (a *AlertManager) readProfile(alertProfileCfg string, embedConfig bool) error {
-	var f io.Reader
 	var err error
+	read := util.ReadConfig
 	if embedConfig {
 		alertProfileCfg = path.Join(path.Dir(a.prometheus.ConfigSpec.EmbedFSDir), alertProfileCfg)
-		f, err = util.ReadEmbedConfig(a.prometheus.ConfigSpec.EmbedFS, alertProfileCfg)
-	} else {
-		f, err = util.ReadConfig(alertProfileCfg)
-	}
-	if err != nil {
-		log.Fatalf("Error reading alert profile %s: %s", alertProfileCfg, err)
+		read = func(location string) (io.Reader, error) {
+			return util.ReadEmbedConfig(a.prometheus.ConfigSpec.EmbedFS, location)
+		}
 	}
-	yamlDec := yaml.NewDecoder(f)
-	yamlDec.KnownFields(true)
-	if err = yamlDec.Decode(&a.alertProfile); err != nil {
-		return fmt.Errorf("error decoding alert profile %s: %s", alertProfileCfg, err)
+	if a.alertProfile, err = loadProfile(alertProfileCfg, read, embedConfig); err != nil {
+		return err
 	}
 	return a.validateTemplates()
 }
 
-// Evaluate evaluates expressions
-func (a *AlertManager) Evaluate(start, end time.Time) error {
+// runWindow holds the times of the run and the job the alert expressions can reference
+type runWindow struct {
+	runStart time.Time
+	runEnd   time.Time
+	jobStart time.Time
+	jobEnd   time.Time
+}
+
+// EvaluateJobs evaluates expressions within the time window of each job and returns their results,
+// the returned error aggregates the errors of all jobs. The run spans from the start of the first job to the end of the last one
+func (a *AlertManager) EvaluateJobs(jobList []prometheus.Job) ([]AlertResult, error) {
+	errs := []error{}
+	results := []AlertResult{}
+	var failedJobs []string
+	var window runWindow
+	for _, job := range jobList {
+		if window.runStart.IsZero() || job.Start.Before(window.runStart) {
+			window.runStart = job.Start
+		}
+		if job.End.After(window.runEnd) {
+			window.runEnd = job.End
+		}
+	}
+	for _, job := range jobList {
+		log.Infof("Evaluating alerts for prometheus %v in job %s", a.prometheus.Endpoint, job.JobConfig.Name)
+		window.jobStart, window.jobEnd = job.Start, job.End
+		jobResults, err := a.evaluate(job.JobConfig.Name, job.Start, job.End, window)
+		results = append(results, jobResults...)
+		if err != nil {
+			failedJobs = append(failedJobs, job.JobConfig.Name)
+			errs = append(errs, err)
+		}
+	}
+	if len(failedJobs) > 0 {
+		log.Errorf("Alerts failed in jobs: %s", strings.Join(failedJobs, ", "))
+	} else {
+		log.Infof("No failed alerts in %d jobs", len(jobList))
+	}
+	return results, utilerrors.NewAggregate(errs)
+}
+
+// Evaluate evaluates expressions within the time window of the given job and returns their results, the run spans the job
+func (a *AlertManager) Evaluate(job prometheus.Job) ([]AlertResult, error) {
+	log.Infof("Evaluating alerts for prometheus %v in job %s", a.prometheus.Endpoint, job.JobConfig.Name)
+	window := runWindow{runStart: job.Start, runEnd: job.End, jobStart: job.Start, jobEnd: job.End}
+	return a.evaluate(job.JobConfig.Name, job.Start, job.End, window)
+}
+
+// EvaluateLive evaluates expressions within the given time window of the running job and returns their results.
+// The run and the job started at the given times and span until the end of the window
+func (a *AlertManager) EvaluateLive(jobName string, runStart, jobStart, start, end time.Time) ([]AlertResult, error) {
+	log.Debugf("Evaluating live alerts for prometheus %v in job %s", a.prometheus.Endpoint, jobName)
+	window := runWindow{runStart: runStart, runEnd: end, jobStart: jobStart, jobEnd: end}
+	return a.evaluate(jobName, start, end, window)
+}
+
+// expressionVars returns the variables available to the alert expressions: the environment variables, elapsed, the duration
+// of the evaluated time range in seconds, RunDuration, the duration of the run in seconds, and JobStart and JobEnd, the
+// times of the job as Unix timestamps, usable with the @ modifier
+func expressionVars(start, end time.Time, window runWindow) map[string]interface{} {
+	vars := util.EnvToMap()
+	// Prometheus rejects empty ranges
+	vars["elapsed"] = fmt.Sprintf("%ds", int(math.Max(end.Sub(start).Seconds(), 1)))
+	runDuration := int(math.Max(window.runEnd.Sub(window.runStart).Seconds(), 1))
+	vars["RunDuration"] = fmt.Sprintf("%ds", runDuration)
+	vars["JobStart"] = window.jobStart.Unix()
+	vars["JobEnd"] = window.jobEnd.Unix()
+	return vars
+}
+
+func (a *AlertManager) evaluate(jobName string, start, end time.Time, window runWindow) ([]AlertResult, error) {
 	errs := []error{}
-	log.Infof("Evaluating alerts for prometheus: %v", a.prometheus.Endpoint)
+	results := []AlertResult{}
 	var alertList []interface{}
-	elapsed := int(end.Sub(start).Minutes())
 	var renderedQuery bytes.Buffer
-	vars := util.EnvToMap()
-	vars["elapsed"] = fmt.Sprintf("%dm", elapsed)
+	vars := expressionVars(start, end, window)
 	for _, alert := range a.alertProfile {
 		t, _ := template.New("").Parse(alert.Expr)
 		t.Execute(&renderedQuery, vars)
@@ -130,23 +234,63 @@ func (a *AlertManager) Evaluate(start, end time.Time) error {
 		log.Debugf("Evaluating expression: '%s'", expr)
 		v, err := a.prometheus.Client.QueryRange(expr, start, end, a.prometheus.Step)
 		if err != nil {
-			log.Warnf("Error performing query %s: %s", expr, err)
+			err = fmt.Errorf("error performing query %s: %s", expr, err)
+			log.Error(err.Error())
+			errs = append(errs, err)
+			results = append(results, AlertResult{
+				Expr:     expr,
+				Severity: string(alert.Severity),
+				JobName:  jobName,
+				Error:    err.Error(),
+			})
 			continue
 		}
-		alertData, err := parseMatrix(v, alert.Description, alert.Severity)
+		alertData, err := parseMatrix(v, alert.Description, alert.Severity, jobName)
 		if err != nil {
 			log.Error(err.Error())
 			errs = append(errs, err)
 		}
+		if err == nil && len(alertData) == 0 {
+			results = append(results, AlertResult{
+				Expr:     expr,
+				Severity: string(alert.Severity),
+				JobName:  jobName,
+			})
+		}
 		for _, alertSet := range alertData {
 			alertSet.UUID = a.uuid
-			alertList = append(alertList, alertSet)
+			alertSet.JobName = jobName
+			timestamp := alertSet.Timestamp
+			results = append(results, AlertResult{
+				Fired:       true,
+				Expr:        expr,
+				Description: alertSet.Description,
+				Severity:    string(alertSet.Severity),
+				JobName:     alertSet.JobName,
+				Timestamp:   &timestamp,
+				Labels:      alertSet.labels,
+			})
+			if a.firstFired(alert, alertSet) {
+				alertList = append(alertList, alertSet)
+			}
 		}
 	}
 	if len(alertList) > 0 && a.indexer != nil {
 		a.index(alertList)
 	}
-	return utilerrors.NewAggregate(errs)
+	return results, utilerrors.NewAggregate(errs)
+}
+
+// firstFired returns true the first time the given alert fires for the matched series within the job
+func (a *AlertManager) firstFired(definition alertDefinition, firedAlert alert) bool {
+	key := fmt.Sprintf("%s/%s/%s/%v", firedAlert.JobName, definition.Severity, definition.Expr, firedAlert.labels)
+	a.indexedLock.Lock()
+	defer a.indexedLock.Unlock()
+	if _, ok := a.indexed[key]; ok {
+		return false
+	}
+	a.indexed[key] = struct{}{}
+	return true
 }
 
 func (a *AlertManager) validateTemplates() error {
@@ -158,7 +302,8 @@ func (a *AlertManager) validateTemplates() error {
 	return nil
 }
 
-func parseMatrix(value model.Value, description string, severity severityLevel) ([]alert, error) {
+// parseMatrix returns the alerts fired by the given query result, error and critical alerts are returned as errors
+func parseMatrix(value model.Value, description string, severity severityLevel, jobName string) ([]alert, error) {
 	var renderedDesc bytes.Buffer
 	var templateData descriptionTemplate
 	// The same query can fire multiple alerts, so we have to return an array of them
@@ -183,12 +328,13 @@ func parseMatrix(value model.Value, description string, severity severityLevel)
 				log.Error(msg.Error())
 				errs = append(errs, err)
 			}
-			msg := fmt.Sprintf("alert at %v: '%s'", val.Timestamp.Time().UTC().Format(time.RFC3339), renderedDesc.String())
+			msg := fmt.Sprintf("alert at %v in job %s: '%s'", val.Timestamp.Time().UTC().Format(time.RFC3339), jobName, renderedDesc.String())
 			alertSet = append(alertSet, alert{
 				Timestamp:   val.Timestamp.Time().UTC(),
 				Severity:    severity,
 				Description: renderedDesc.String(),
 				MetricName:  alertMetricName,
+				labels:      templateData.Labels,
 			})
 			switch severity {
 			case sevWarn:
@@ -196,7 +342,8 @@ func parseMatrix(value model.Value, description string, severity severityLevel)
 			case sevError:
 				errs = append(errs, fmt.Errorf(msg))
 			case sevCritical:
-				log.Fatalf("🚨 %s", msg)
+				log.Errorf("🚨 %s", msg)
+				errs = append(errs, fmt.Errorf(msg))
 			default:
 				log.Infof("🚨 %s", msg)
 			}
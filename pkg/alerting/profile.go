@@ -0,0 +1,121 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// profileReader returns a reader for the given alert profile location
+type profileReader func(location string) (io.Reader, error)
+
+// loadProfile decodes the alert profile at the given location, resolving its include directives depth-first.
+// Definitions are merged in order, a named definition replaces the earlier one with the same name
+func loadProfile(location string, read profileReader, embedConfig bool) (alertProfile, error) {
+	var profile alertProfile
+	byName := make(map[string]int)
+	if err := loadProfileInto(location, read, embedConfig, nil, &profile, byName); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+func loadProfileInto(location string, read profileReader, embedConfig bool, stack []string, profile *alertProfile, byName map[string]int) error {
+	for _, parent := range stack {
+		if parent == location {
+			return fmt.Errorf("alert profile include cycle: %s -> %s", strings.Join(stack, " -> "), location)
+		}
+	}
+	stack = append(stack, location)
+	f, err := read(location)
+	if err != nil {
+		return fmt.Errorf("error reading alert profile %s: %s", location, err)
+	}
+	var definitions alertProfile
+	yamlDec := yaml.NewDecoder(f)
+	yamlDec.KnownFields(true)
+	if err = yamlDec.Decode(&definitions); err != nil && err != io.EOF {
+		return fmt.Errorf("error decoding alert profile %s: %s", location, err)
+	}
+	for _, definition := range definitions {
+		if definition.Include != "" {
+			if definition.Expr != "" || definition.Name != "" || definition.Description != "" || definition.Severity != "" {
+				return fmt.Errorf("alert profile %s: include entries can't define alert fields", location)
+			}
+			included := resolveInclude(location, definition.Include, embedConfig)
+			log.Debugf("Including alert profile %s from %s", included, location)
+			if err := loadProfileInto(included, read, embedConfig, stack, profile, byName); err != nil {
+				return err
+			}
+			continue
+		}
+		if definition.Expr == "" {
+			return fmt.Errorf("alert profile %s: alert %q has no expr", location, definition.Description)
+		}
+		switch definition.Severity {
+		case "", sevInfo, sevWarn, sevError, sevCritical:
+		default:
+			return fmt.Errorf("alert profile %s: unknown severity %q", location, definition.Severity)
+		}
+		if definition.Name == "" {
+			*profile = append(*profile, definition)
+			continue
+		}
+		if idx, ok := byName[definition.Name]; ok {
+			log.Debugf("Alert %s overridden by %s", definition.Name, location)
+			(*profile)[idx] = definition
+			continue
+		}
+		byName[definition.Name] = len(*profile)
+		*profile = append(*profile, definition)
+	}
+	return nil
+}
+
+// resolveInclude resolves the include location relative to the profile including it
+func resolveInclude(parent, include string, embedConfig bool) string {
+	if embedConfig {
+		if path.IsAbs(include) {
+			return path.Clean(include)
+		}
+		return path.Join(path.Dir(parent), include)
+	}
+	if strings.HasPrefix(include, util.GitPrefix) || filepath.IsAbs(include) {
+		return include
+	}
+	if u, err := url.Parse(include); err == nil && u.IsAbs() {
+		return include
+	}
+	if parent == util.StdinConfig || strings.HasPrefix(parent, util.GitPrefix) {
+		return include
+	}
+	if u, err := url.Parse(parent); err == nil && u.IsAbs() {
+		ref, err := url.Parse(include)
+		if err != nil {
+			return include
+		}
+		return u.ResolveReference(ref).String()
+	}
+	return filepath.Join(filepath.Dir(parent), include)
+}
@@ -0,0 +1,39 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// WriteReport writes the given alert results as a JSON array into the given file, an empty array is written when none was evaluated
+func WriteReport(fileName string, alerts []AlertResult) error {
+	if alerts == nil {
+		alerts = []AlertResult{}
+	}
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("error creating alert report %s: %s", fileName, err)
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(alerts); err != nil {
+		return fmt.Errorf("error writing alert report %s: %s", fileName, err)
+	}
+	return nil
+}
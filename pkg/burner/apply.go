@@ -0,0 +1,91 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements"
+	log "github.com/sirupsen/logrus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// applyRequest applies the object using server-side apply with the field manager of the object,
+// retrying retryable errors with exponential backoff. Conflicts are reported along with the conflicting field managers
+func (ex *Executor) applyRequest(obj object, ns string, newObject *unstructured.Unstructured) error {
+	if newObject.GetName() == "" {
+		return fmt.Errorf("%s objects must have a name to be applied, generateName is not supported", newObject.GetKind())
+	}
+	data, err := newObject.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	patchOptions := metav1.PatchOptions{
+		FieldManager: obj.FieldManager,
+		Force:        &obj.ForceConflicts,
+	}
+	return ex.retryRequest(func() error {
+		var err error
+		start := time.Now()
+		ex.objectMarkers.submitted()
+		if ns != "" {
+			_, err = DynamicClient.Resource(obj.gvr).Namespace(ns).Patch(context.TODO(), newObject.GetName(), types.ApplyPatchType, data, patchOptions)
+		} else {
+			_, err = DynamicClient.Resource(obj.gvr).Patch(context.TODO(), newObject.GetName(), types.ApplyPatchType, data, patchOptions)
+		}
+		measurements.RecordAPICall("apply", newObject.GetKind(), ns, newObject.GetName(), start, err)
+		if err != nil {
+			if kerrors.IsUnauthorized(err) {
+				abortRun(fmt.Sprintf("authorization error applying %s/%s: %s", newObject.GetKind(), newObject.GetName(), err))
+				return err
+			}
+			if kerrors.IsConflict(err) {
+				err = fmt.Errorf("conflicts with other field managers, enable forceConflicts to take ownership of the fields: %s", applyConflicts(err))
+			}
+			if ns != "" {
+				log.Errorf("Error applying object %s/%s in namespace %s: %s", newObject.GetKind(), newObject.GetName(), ns, err)
+			} else {
+				log.Errorf("Error applying object %s/%s: %s", newObject.GetKind(), newObject.GetName(), err)
+			}
+			return err
+		}
+		ex.objectMarkers.created()
+		if ns != "" {
+			log.Debugf("Applied %s/%s in namespace %s", newObject.GetKind(), newObject.GetName(), ns)
+		} else {
+			log.Debugf("Applied %s/%s", newObject.GetKind(), newObject.GetName())
+		}
+		return nil
+	}, &ex.retries.create)
+}
+
+// applyConflicts returns the conflicts of a server-side apply conflict error, each of them naming the field manager and the field
+func applyConflicts(err error) string {
+	status, ok := err.(kerrors.APIStatus)
+	if !ok || status.Status().Details == nil || len(status.Status().Details.Causes) == 0 {
+		return err.Error()
+	}
+	var conflicts []string
+	for _, cause := range status.Status().Details.Causes {
+		conflicts = append(conflicts, fmt.Sprintf("%s: %s", cause.Field, cause.Message))
+	}
+	return strings.Join(conflicts, ", ")
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	log "github.com/sirupsen/logrus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	requestRetriesMetric = "requestRetries"
+	retryBackoffFactor   = 2
+)
+
+// requestRetries counts the create and patch requests retried due to retryable errors
+type requestRetries struct {
+	create atomic.Int64
+	patch  atomic.Int64
+}
+
+// requestRetriesSummary holds the number of requests retried by a job
+type requestRetriesSummary struct {
+	Timestamp     time.Time              `json:"timestamp"`
+	UUID          string                 `json:"uuid"`
+	MetricName    string                 `json:"metricName"`
+	JobName       string                 `json:"jobName"`
+	CreateRetries int64                  `json:"createRetries"`
+	PatchRetries  int64                  `json:"patchRetries"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// isRetryable returns true for the errors caused by an overloaded or unavailable API server
+func isRetryable(err error) bool {
+	return kerrors.IsTooManyRequests(err) ||
+		kerrors.IsInternalError(err) ||
+		kerrors.IsServiceUnavailable(err) ||
+		utilnet.IsConnectionRefused(err)
+}
+
+// isRetryablePatch returns true for the retryable errors and for conflicts, as the patched objects may be updated concurrently
+func isRetryablePatch(err error) bool {
+	return isRetryable(err) || kerrors.IsConflict(err)
+}
+
+// retryRequest runs the request, retrying it up to maxRetries times with exponential backoff starting at
+// retryBackoff, and capped at retryBackoffCap, when it fails with a retryable error. Non-retryable errors are returned immediately
+func (ex *Executor) retryRequest(request func() error, retries *atomic.Int64) error {
+	return ex.retryRequestOn(isRetryable, request, retries)
+}
+
+// retryRequestOn is retryRequest with the given function deciding which errors are retryable. The retries are counted
+// here rather than with wait.ExponentialBackoff, which stops retrying as soon as the backoff reaches its cap
+func (ex *Executor) retryRequestOn(retryable func(error) bool, request func() error, retries *atomic.Int64) error {
+	backoff := wait.Backoff{
+		Duration: ex.RetryBackoff,
+		Factor:   retryBackoffFactor,
+		Steps:    ex.MaxRetries,
+		Cap:      ex.RetryBackoffCap,
+	}
+	for attempt := 0; ; attempt++ {
+		err := request()
+		if err == nil || !retryable(err) {
+			return err
+		}
+		if attempt == ex.MaxRetries {
+			return fmt.Errorf("giving up after %d retries: %w", ex.MaxRetries, err)
+		}
+		log.Debugf("Retrying request after retryable error: %s", err)
+		retries.Add(1)
+		time.Sleep(backoff.Step())
+	}
+}
+
+// indexRequestRetries indexes the number of requests retried by the job
+func (ex *Executor) indexRequestRetries(indexer *indexers.Indexer, metadata map[string]interface{}) {
+	if ex.retries == nil || ex.SkipIndexing {
+		return
+	}
+	summary := requestRetriesSummary{
+		Timestamp:     time.Now().UTC(),
+		UUID:          ex.uuid,
+		MetricName:    requestRetriesMetric,
+		JobName:       ex.Name,
+		CreateRetries: ex.retries.create.Load(),
+		PatchRetries:  ex.retries.patch.Load(),
+		Metadata:      metadata,
+	}
+	log.Infof("Job %s: %d create and %d patch requests retried", ex.Name, summary.CreateRetries, summary.PatchRetries)
+	log.Infof("Indexing metric %s", requestRetriesMetric)
+	resp, err := (*indexer).Index([]interface{}{summary}, indexers.IndexingOpts{MetricName: requestRetriesMetric})
+	if err != nil {
+		log.Error(err)
+	} else {
+		log.Info(resp)
+	}
+}
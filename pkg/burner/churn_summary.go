@@ -0,0 +1,53 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	log "github.com/sirupsen/logrus"
+)
+
+const churnSummaryMetric = "churnSummary"
+
+// churnSummary holds the number of delete and re-create cycles completed by a churn job
+type churnSummary struct {
+	Timestamp         time.Time              `json:"timestamp"`
+	EndTimestamp      time.Time              `json:"endTimestamp"`
+	UUID              string                 `json:"uuid"`
+	MetricName        string                 `json:"metricName"`
+	JobName           string                 `json:"jobName"`
+	ChurnPercent      int                    `json:"churnPercent"`
+	CyclesCompleted   int                    `json:"cyclesCompleted"`
+	ChurnedIterations int                    `json:"churnedIterations"`
+	Metadata          map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// indexChurnSummary indexes the churn cycles completed by the job
+func (ex *Executor) indexChurnSummary(indexer *indexers.Indexer, metadata map[string]interface{}) {
+	if ex.churn == nil || ex.SkipIndexing {
+		return
+	}
+	summary := *ex.churn
+	summary.Metadata = metadata
+	log.Infof("Indexing metric %s", churnSummaryMetric)
+	resp, err := (*indexer).Index([]interface{}{summary}, indexers.IndexingOpts{MetricName: churnSummaryMetric})
+	if err != nil {
+		log.Error(err)
+	} else {
+		log.Info(resp)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const churnTeardownMetric = "churnTeardownWave"
+
+// churnTeardownWave holds the pacing and the deletion latency of a churn teardown wave, times are in seconds
+type churnTeardownWave struct {
+	Timestamp       time.Time              `json:"timestamp"`
+	UUID            string                 `json:"uuid"`
+	MetricName      string                 `json:"metricName"`
+	JobName         string                 `json:"jobName"`
+	ChurnCycle      int                    `json:"churnCycle"`
+	Wave            int                    `json:"wave"`
+	Namespaces      int                    `json:"namespaces"`
+	Delay           float64                `json:"delay"`
+	DeletionLatency float64                `json:"deletionLatency"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// teardownChurnWaves deletes the given namespaces in waves of churnTeardownWaveSize namespaces, separated by a random
+// delay up to churnTeardownWaveJitter. Waves don't wait for the previous ones to be gone, so deletions are spread out
+// over time, and it returns once all the namespaces are gone
+func (ex *Executor) teardownChurnWaves(ctx context.Context, namespaces []string, cycle int) {
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	for wave, i := 0, 0; i < len(namespaces); wave, i = wave+1, i+ex.ChurnTeardownWaveSize {
+		var delay time.Duration
+		if wave > 0 && ex.ChurnTeardownWaveJitter > 0 {
+			delay = time.Duration(rand.Int63n(int64(ex.ChurnTeardownWaveJitter)))
+			time.Sleep(delay)
+		}
+		end := i + ex.ChurnTeardownWaveSize
+		if end > len(namespaces) {
+			end = len(namespaces)
+		}
+		waveNamespaces := namespaces[i:end]
+		log.Infof("Churn teardown wave %d: deleting %d namespaces", wave, len(waveNamespaces))
+		result := churnTeardownWave{
+			Timestamp:  time.Now().UTC(),
+			UUID:       ex.uuid,
+			MetricName: churnTeardownMetric,
+			JobName:    ex.Name,
+			ChurnCycle: cycle,
+			Wave:       wave,
+			Namespaces: len(waveNamespaces),
+			Delay:      delay.Seconds(),
+		}
+		for _, ns := range waveNamespaces {
+			err := ClientSet.CoreV1().Namespaces().Delete(ctx, ns, metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				log.Errorf("Error deleting namespace %s: %v", ns, err)
+			}
+		}
+		wg.Add(1)
+		go func(result churnTeardownWave, waveNamespaces []string) {
+			defer wg.Done()
+			if err := waitForNamespacesGone(ctx, waveNamespaces); err != nil {
+				log.Errorf("Error waiting for churn teardown wave %d: %v", result.Wave, err)
+				return
+			}
+			result.DeletionLatency = time.Since(result.Timestamp).Seconds()
+			log.Infof("Churn teardown wave %d: %d namespaces deleted in %.2fs", result.Wave, result.Namespaces, result.DeletionLatency)
+			lock.Lock()
+			ex.churnTeardownWaves = append(ex.churnTeardownWaves, result)
+			lock.Unlock()
+		}(result, waveNamespaces)
+	}
+	wg.Wait()
+}
+
+// waitForNamespacesGone waits for the given namespaces to be deleted
+func waitForNamespacesGone(ctx context.Context, namespaces []string) error {
+	pending := namespaces
+	return wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		var remaining []string
+		for _, ns := range pending {
+			_, err := ClientSet.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				continue
+			}
+			remaining = append(remaining, ns)
+		}
+		pending = remaining
+		return len(pending) == 0, nil
+	})
+}
+
+// indexChurnTeardown indexes the churn teardown waves of the job
+func (ex *Executor) indexChurnTeardown(indexer *indexers.Indexer, metadata map[string]interface{}) {
+	var docs []interface{}
+	if len(ex.churnTeardownWaves) == 0 || ex.SkipIndexing {
+		return
+	}
+	for _, wave := range ex.churnTeardownWaves {
+		wave.Metadata = metadata
+		docs = append(docs, wave)
+	}
+	log.Infof("Indexing metric %s", churnTeardownMetric)
+	resp, err := (*indexer).Index(docs, indexers.IndexingOpts{MetricName: churnTeardownMetric})
+	if err != nil {
+		log.Error(err)
+	} else {
+		log.Info(resp)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	log "github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// verifyCleanup runs the given cleanup verifications in parallel, returning an error for each one not passing within its timeout
+func verifyCleanup(verifications []config.CleanupVerification) error {
+	var errs []error
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, verification := range verifications {
+		wg.Add(1)
+		go func(verification config.CleanupVerification) {
+			defer wg.Done()
+			if err := runCleanupVerification(verification); err != nil {
+				log.Errorf("Cleanup verification %s failed: %s", verification.Name, err)
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("cleanup verification %s failed: %s", verification.Name, err))
+				mu.Unlock()
+				return
+			}
+			log.Infof("Cleanup verification %s passed", verification.Name)
+		}(verification)
+	}
+	wg.Wait()
+	return utilerrors.NewAggregate(errs)
+}
+
+// runCleanupVerification retries the verification command until it succeeds or its timeout is reached
+func runCleanupVerification(verification config.CleanupVerification) error {
+	var output string
+	var cmdErr error
+	log.Infof("Running cleanup verification %s, timeout: %v", verification.Name, verification.Timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), verification.Timeout)
+	defer cancel()
+	err := wait.PollUntilContextCancel(ctx, verification.Interval, true, func(ctx context.Context) (bool, error) {
+		var outb bytes.Buffer
+		cmd := exec.CommandContext(ctx, "/bin/sh", "-c", verification.Command)
+		cmd.Stdout = &outb
+		cmd.Stderr = &outb
+		cmdErr = cmd.Run()
+		output = strings.TrimSpace(outb.String())
+		if cmdErr != nil {
+			log.Debugf("Cleanup verification %s not passing yet: %s, output: %s", verification.Name, cmdErr, output)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s after %v: %v, output: %s", err, verification.Timeout, cmdErr, output)
+	}
+	return nil
+}
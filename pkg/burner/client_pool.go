@@ -0,0 +1,98 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+// dynamicClientPool implements dynamic.Interface distributing the requests across a pool of clients in round-robin
+type dynamicClientPool struct {
+	clients  []dynamic.Interface
+	next     uint64
+	requests []uint64
+	inFlight int64
+	peak     int64
+	// busy accumulates the duration of all requests, in nanoseconds
+	busy  int64
+	start time.Time
+}
+
+// countingRoundTripper keeps track of the requests performed by a client of the pool
+type countingRoundTripper struct {
+	rt     http.RoundTripper
+	pool   *dynamicClientPool
+	client int
+}
+
+func newDynamicClientPool(restConfigs []*rest.Config) *dynamicClientPool {
+	pool := &dynamicClientPool{
+		requests: make([]uint64, len(restConfigs)),
+		start:    time.Now(),
+	}
+	for i, restConfig := range restConfigs {
+		client := i
+		poolConfig := rest.CopyConfig(restConfig)
+		poolConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &countingRoundTripper{rt: rt, pool: pool, client: client}
+		})
+		pool.clients = append(pool.clients, dynamic.NewForConfigOrDie(poolConfig))
+	}
+	return pool
+}
+
+// Resource returns a resource interface from the next client of the pool
+func (p *dynamicClientPool) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	next := atomic.AddUint64(&p.next, 1) - 1
+	return p.clients[next%uint64(len(p.clients))].Resource(resource)
+}
+
+// report logs the number of requests performed by each client and the effective parallelism,
+// calculated as the accumulated request time divided by the elapsed time
+func (p *dynamicClientPool) report() {
+	var total uint64
+	requests := make([]uint64, len(p.requests))
+	for i := range p.requests {
+		requests[i] = atomic.LoadUint64(&p.requests[i])
+		total += requests[i]
+	}
+	elapsed := time.Since(p.start)
+	parallelism := float64(atomic.LoadInt64(&p.busy)) / float64(elapsed.Nanoseconds())
+	log.Infof("Client pool of %d clients performed %d requests %v, peak parallelism: %d, effective parallelism: %.2f",
+		len(p.clients), total, requests, atomic.LoadInt64(&p.peak), parallelism)
+}
+
+func (c *countingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	inFlight := atomic.AddInt64(&c.pool.inFlight, 1)
+	for {
+		peak := atomic.LoadInt64(&c.pool.peak)
+		if inFlight <= peak || atomic.CompareAndSwapInt64(&c.pool.peak, peak, inFlight) {
+			break
+		}
+	}
+	start := time.Now()
+	resp, err := c.rt.RoundTrip(req)
+	atomic.AddInt64(&c.pool.busy, int64(time.Since(start)))
+	atomic.AddInt64(&c.pool.inFlight, -1)
+	atomic.AddUint64(&c.pool.requests[c.client], 1)
+	return resp, err
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	log "github.com/sirupsen/logrus"
+)
+
+const concurrencySweepMetric = "concurrencySweep"
+
+// concurrencyLevel holds the results of a concurrency sweep level, latencies are in milliseconds
+type concurrencyLevel struct {
+	Timestamp    time.Time              `json:"timestamp"`
+	EndTimestamp time.Time              `json:"endTimestamp"`
+	UUID         string                 `json:"uuid"`
+	MetricName   string                 `json:"metricName"`
+	JobName      string                 `json:"jobName"`
+	Concurrency  int                    `json:"concurrency"`
+	Iterations   int                    `json:"iterations"`
+	Objects      int                    `json:"objects"`
+	ElapsedTime  float64                `json:"elapsedTime"`
+	CreationRate float64                `json:"creationRate"`
+	P99          int                    `json:"P99"`
+	P95          int                    `json:"P95"`
+	P50          int                    `json:"P50"`
+	Max          int                    `json:"max"`
+	Avg          int                    `json:"avg"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// latencyRecorder records the latency of the create requests
+type latencyRecorder struct {
+	lock      sync.Mutex
+	latencies []time.Duration
+	first     time.Time
+	last      time.Time
+}
+
+func (l *latencyRecorder) record(start time.Time) {
+	end := time.Now()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.latencies = append(l.latencies, end.Sub(start))
+	if l.first.IsZero() || start.Before(l.first) {
+		l.first = start
+	}
+	if end.After(l.last) {
+		l.last = end
+	}
+}
+
+// RunConcurrencySweep runs the creation job once per concurrency level, limiting the number of concurrent
+// create requests to the level value, and returns the achieved creation rate and latencies of each level
+func (ex *Executor) RunConcurrencySweep(waitListNamespaces *[]string) []concurrencyLevel {
+	var levels []concurrencyLevel
+	iterationStart := 0
+	for _, concurrency := range ex.ConcurrencySweep.Levels {
+		log.Infof("Running %d iterations with %d concurrent create requests", ex.ConcurrencySweep.Iterations, concurrency)
+		ex.createSem = make(chan struct{}, concurrency)
+		ex.createLatencies = &latencyRecorder{}
+		levelStart := time.Now().UTC()
+		ex.RunCreateJob(iterationStart, iterationStart+ex.ConcurrencySweep.Iterations, waitListNamespaces)
+		level := ex.createLatencies.summary()
+		level.Timestamp = levelStart
+		level.EndTimestamp = time.Now().UTC()
+		level.UUID = ex.uuid
+		level.MetricName = concurrencySweepMetric
+		level.JobName = ex.Name
+		level.Concurrency = concurrency
+		level.Iterations = ex.ConcurrencySweep.Iterations
+		log.Infof("Concurrency %d: %d objects created at %.2f objects/s, P99 latency: %dms", concurrency, level.Objects, level.CreationRate, level.P99)
+		levels = append(levels, level)
+		iterationStart += ex.ConcurrencySweep.Iterations
+	}
+	ex.createSem = nil
+	ex.createLatencies = nil
+	return levels
+}
+
+// summary calculates the creation rate and the latency quantiles of the recorded requests
+func (l *latencyRecorder) summary() concurrencyLevel {
+	var level concurrencyLevel
+	var sum time.Duration
+	length := len(l.latencies)
+	if length == 0 {
+		return level
+	}
+	sort.Slice(l.latencies, func(i, j int) bool { return l.latencies[i] < l.latencies[j] })
+	for _, latency := range l.latencies {
+		sum += latency
+	}
+	quantile := func(q float64) int {
+		return int(l.latencies[int(math.Ceil(float64(length)*q))-1].Milliseconds())
+	}
+	level.Objects = length
+	level.ElapsedTime = l.last.Sub(l.first).Seconds()
+	if level.ElapsedTime > 0 {
+		level.CreationRate = float64(length) / level.ElapsedTime
+	}
+	level.P50, level.P95, level.P99 = quantile(0.5), quantile(0.95), quantile(0.99)
+	level.Max = int(l.latencies[length-1].Milliseconds())
+	level.Avg = int((sum / time.Duration(length)).Milliseconds())
+	return level
+}
+
+// indexConcurrencySweep indexes the results of each concurrency sweep level
+func indexConcurrencySweep(indexer *indexers.Indexer, levels []concurrencyLevel, metadata map[string]interface{}) {
+	var docs []interface{}
+	if len(levels) == 0 {
+		return
+	}
+	for _, level := range levels {
+		level.Metadata = metadata
+		docs = append(docs, level)
+	}
+	log.Infof("Indexing metric %s", concurrencySweepMetric)
+	indexingOpts := indexers.IndexingOpts{
+		MetricName: fmt.Sprintf("%s-%s", concurrencySweepMetric, levels[0].JobName),
+	}
+	resp, err := (*indexer).Index(docs, indexingOpts)
+	if err != nil {
+		log.Error(err)
+	} else {
+		log.Info(resp)
+	}
+}
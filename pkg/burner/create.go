@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/time/rate"
 
@@ -38,17 +39,22 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 )
 
-func setupCreateJob(jobConfig config.Job) Executor {
-	var err error
+func setupCreateJob(jobConfig config.Job) (Executor, error) {
 	var f io.Reader
-	mapper := newRESTMapper()
+	mapper, err := newRESTMapper()
 	if err != nil {
-		log.Fatalf("Error creating wait clientSet: %s", err.Error())
+		return Executor{}, err
 	}
 	log.Debugf("Preparing create job: %s", jobConfig.Name)
-	ex := Executor{}
+	ex := Executor{
+		nsLabeler:         newNamespaceLabeler(jobConfig),
+		failedIterations:  newIterationTracker(),
+		runOnceNamespaces: make(map[int]string),
+		runOnceLock:       &sync.Mutex{},
+	}
 	for _, o := range jobConfig.Objects {
 		if o.Replicas < 1 {
 			log.Warnf("Object template %s has replicas %d < 1, skipping", o.ObjectTemplate, o.Replicas)
@@ -63,27 +69,31 @@ func setupCreateJob(jobConfig config.Job) Executor {
 			f, err = util.ReadEmbedConfig(embedFS, objectTemplate)
 		}
 		if err != nil {
-			log.Fatalf("Error reading template %s: %s", o.ObjectTemplate, err)
+			return Executor{}, fmt.Errorf("error reading template %s: %s", o.ObjectTemplate, err)
 		}
 		t, err := io.ReadAll(f)
 		if err != nil {
-			log.Fatalf("Error reading template %s: %s", o.ObjectTemplate, err)
+			return Executor{}, fmt.Errorf("error reading template %s: %s", o.ObjectTemplate, err)
 		}
 		// Deserialize YAML
 		uns := &unstructured.Unstructured{}
 		cleanTemplate, err := prepareTemplate(t)
 		if err != nil {
-			log.Fatalf("Error preparing template %s: %s", o.ObjectTemplate, err)
+			return Executor{}, fmt.Errorf("error preparing template %s: %s", o.ObjectTemplate, err)
+		}
+		_, gvk, err := yamlToUnstructured(cleanTemplate, uns)
+		if err != nil {
+			return Executor{}, fmt.Errorf("template %s: %s", o.ObjectTemplate, err)
 		}
-		_, gvk := yamlToUnstructured(cleanTemplate, uns)
-		mapping, err := mapper.RESTMapping(gvk.GroupKind())
+		mapping, err := restMapping(mapper, *gvk)
 		if err != nil {
-			log.Fatal(err)
+			return Executor{}, fmt.Errorf("template %s: %s", o.ObjectTemplate, err)
 		}
 		obj := object{
 			gvr:        mapping.Resource,
 			objectSpec: t,
 			kind:       gvk.Kind,
+			index:      len(ex.objects),
 			Object:     o,
 		}
 		// If any of the objects is namespaced, we configure the job to create namepaces
@@ -94,7 +104,15 @@ func setupCreateJob(jobConfig config.Job) Executor {
 		log.Infof("Job %s: %d iterations with %d %s replicas", jobConfig.Name, jobConfig.JobIterations, obj.Replicas, gvk.Kind)
 		ex.objects = append(ex.objects, obj)
 	}
-	return ex
+	ex.objectSelector = newObjectSelector(jobConfig, ex.objects)
+	ex.objectOrderer = newObjectOrderer(jobConfig)
+	ex.objectPool = newObjectPool(jobConfig)
+	if ex.dependencies, err = newObjectDependencies(jobConfig.Name, ex.objects); err != nil {
+		return Executor{}, err
+	}
+	ex.nsStagger = newNamespaceStagger(jobConfig)
+	ex.objectMarkers = &objectMarkers{}
+	return ex, nil
 }
 
 // RunCreateJob executes a creation job
@@ -114,8 +132,12 @@ func (ex *Executor) RunCreateJob(iterationStart, iterationEnd int, waitListNames
 	}
 	if !ex.NamespacedIterations {
 		ns = ex.Namespace
-		if err = createNamespace(ns, nsLabels); err != nil {
-			log.Fatal(err.Error())
+		nsStart := time.Now()
+		err = createNamespace(ns, ex.nsLabeler.labels(nsLabels))
+		measurements.RecordNamespaceCreation(ns, nsStart, err)
+		if err != nil {
+			abortRun(fmt.Sprintf("job %s: %s", ex.Name, err))
+			return
 		}
 		*waitListNamespaces = append(*waitListNamespaces, ns)
 	}
@@ -124,24 +146,59 @@ func (ex *Executor) RunCreateJob(iterationStart, iterationEnd int, waitListNames
 	percent := 1
 	var namespacesCreated = make(map[string]bool)
 	var namespacesWaited = make(map[string]bool)
+	progress := ex.startProgress(iterationEnd - iterationStart)
 	for i := iterationStart; i < iterationEnd; i++ {
+		if interrupted() {
+			log.Warnf("Run interrupted, job %s stopped at iteration %d", ex.Name, i)
+			break
+		}
+		if jobTimedOut() {
+			log.Warnf("Timeout budget exhausted, job %s stopped at iteration %d", ex.Name, i)
+			break
+		}
 		if i == iterationStart+iterationProgress*percent {
 			log.Infof("%v/%v iterations completed", i-iterationStart, iterationEnd-iterationStart)
 			percent++
 		}
-		log.Debugf("Creating object replicas from iteration %d", i)
+		log.WithField(util.LogFieldIteration, i).Debugf("Creating object replicas from iteration %d", i)
 		if ex.NamespacedIterations {
-			ns = ex.generateNamespace(i)
+			if ns, err = ex.generateNamespace(i); err != nil {
+				abortRun(err.Error())
+				break
+			}
 			if !namespacesCreated[ns] {
-				if err = createNamespace(ns, nsLabels); err != nil {
-					log.Error(err.Error())
-					continue
+				if len(ex.reusedNamespaces) > 0 {
+					log.Debugf("Reusing namespace %s", ns)
+				} else {
+					ex.nsStagger.wait()
+					nsStart := time.Now()
+					err = createNamespace(ns, ex.nsLabeler.labels(nsLabels))
+					measurements.RecordNamespaceCreation(ns, nsStart, err)
+					if err != nil {
+						log.WithField(util.LogFieldIteration, i).Error(err.Error())
+						ex.failedIterations.fail(i)
+						continue
+					}
 				}
 				namespacesCreated[ns] = true
 				*waitListNamespaces = append(*waitListNamespaces, ns)
 			}
 		}
-		for objectIndex, obj := range ex.objects {
+		pick := ex.objectSelector.pick()
+		// Objects are created by dependency level, each level once the previous ones are created
+		var levels [][]func() error
+		replicaWg := &wg
+		if ex.dependencies != nil {
+			replicaWg = &sync.WaitGroup{}
+		}
+		for _, objectIndex := range ex.objectOrderer.order(len(ex.objects)) {
+			obj := ex.objects[objectIndex]
+			if !selected(pick, objectIndex, obj) {
+				continue
+			}
+			if obj.RunOnce && !ex.claimRunOnce(objectIndex, ns) {
+				continue
+			}
 			labels := map[string]string{
 				"kube-burner-uuid":  ex.uuid,
 				"kube-burner-job":   ex.Name,
@@ -149,13 +206,42 @@ func (ex *Executor) RunCreateJob(iterationStart, iterationEnd int, waitListNames
 				"kube-burner-runid": ex.runid,
 			}
 			ex.objects[objectIndex].labelSelector = labels
-			ex.replicaHandler(labels, obj, ns, i, &wg)
+			iteration, ns := i, ns
+			level := ex.dependencies.level(objectIndex)
+			for len(levels) <= level {
+				levels = append(levels, nil)
+			}
+			levels[level] = append(levels[level], func() error {
+				ex.waitForDependencies(obj, ns, iteration, waitRateLimiter)
+				// The pool workers create the replicas themselves, so objectConcurrency bounds the requests in flight.
+				// With objectOrdering, every object is created before moving to the next one, keeping the order
+				if ex.objectPool != nil || ex.ObjectOrdering != "" {
+					return ex.createReplicas(labels, obj, ns, iteration)
+				}
+				ex.replicaHandler(labels, obj, ns, iteration, replicaWg)
+				return nil
+			})
+		}
+		for _, tasks := range levels {
+			if err := ex.objectPool.run(tasks); err != nil {
+				var failed int
+				if agg, ok := err.(utilerrors.Aggregate); ok {
+					failed = len(utilerrors.Flatten(agg).Errors())
+				}
+				log.WithField(util.LogFieldIteration, i).Errorf("%d objects of iteration %d failed to be created", failed, i)
+			}
+			if ex.dependencies != nil {
+				replicaWg.Wait()
+			}
 		}
 		if !ex.WaitWhenFinished && ex.PodWait {
 			if !ex.NamespacedIterations || !namespacesWaited[ns] {
 				log.Infof("Waiting up to %s for actions to be completed in namespace %s", ex.MaxWaitTimeout, ns)
 				wg.Wait()
+				start := time.Now()
 				ex.waitForObjects(ns, waitRateLimiter)
+				ex.timer.since(phaseWaiting, start)
+				chromeTracer.addSpan("wait", "wait", ex.Name, i, start, map[string]interface{}{"namespace": ns})
 				namespacesWaited[ns] = true
 			}
 		}
@@ -166,25 +252,29 @@ func (ex *Executor) RunCreateJob(iterationStart, iterationEnd int, waitListNames
 	}
 	// Wait for all replicas to be created
 	wg.Wait()
+	progress.stop()
 	if ex.WaitWhenFinished {
 		log.Infof("Waiting up to %s for actions to be completed", ex.MaxWaitTimeout)
 		// This semaphore is used to limit the maximum number of concurrent goroutines
 		sem := make(chan int, int(restConfig.QPS))
 		for i := iterationStart; i < iterationEnd; i++ {
 			if ex.NamespacedIterations {
-				ns = ex.generateNamespace(i)
-				if namespacesWaited[ns] {
+				// Namespaces failing to render were never created
+				if ns, err = ex.generateNamespace(i); err != nil || namespacesWaited[ns] {
 					continue
 				}
 				namespacesWaited[ns] = true
 			}
 			sem <- 1
 			wg.Add(1)
-			go func(ns string) {
+			go func(ns string, iteration int) {
+				start := time.Now()
 				ex.waitForObjects(ns, waitRateLimiter)
+				ex.timer.since(phaseWaiting, start)
+				chromeTracer.addSpan("wait", "wait", ex.Name, iteration, start, map[string]interface{}{"namespace": ns})
 				<-sem
 				wg.Done()
-			}(ns)
+			}(ns, i)
 			// Wait for all namespaces to be ready
 			if !ex.NamespacedIterations {
 				break
@@ -197,9 +287,20 @@ func (ex *Executor) RunCreateJob(iterationStart, iterationEnd int, waitListNames
 // Simple integer division on the iteration allows us to batch iterations into
 // namespaces. Division means namespaces are populated to their desired number
 // of iterations before the next namespace is created.
-func (ex *Executor) generateNamespace(iteration int) string {
+func (ex *Executor) generateNamespace(iteration int) (string, error) {
 	nsIndex := iteration / ex.IterationsPerNamespace
-	return fmt.Sprintf("%s-%d", ex.Namespace, nsIndex)
+	// Reused namespaces are filled round-robin
+	if len(ex.reusedNamespaces) > 0 {
+		return ex.reusedNamespaces[nsIndex%len(ex.reusedNamespaces)], nil
+	}
+	if ex.NamespacePattern != "" {
+		ns, err := config.RenderNamespacePattern(templateEngine, ex.NamespacePattern, nsIndex, ex.uuid, ex.Name)
+		if err != nil {
+			return "", fmt.Errorf("error rendering namespace pattern of job %s: %s", ex.Name, err)
+		}
+		return ns, nil
+	}
+	return fmt.Sprintf("%s-%d", ex.Namespace, nsIndex), nil
 }
 
 func (ex *Executor) replicaHandler(labels map[string]string, obj object, ns string, iteration int, replicaWg *sync.WaitGroup) {
@@ -208,107 +309,291 @@ func (ex *Executor) replicaHandler(labels map[string]string, obj object, ns stri
 		wg.Add(1)
 		go func(r int) {
 			defer wg.Done()
-			var newObject = new(unstructured.Unstructured)
-			templateData := map[string]interface{}{
-				jobName:      ex.Name,
-				jobIteration: iteration,
-				jobUUID:      ex.uuid,
-				replica:      r,
-			}
-			for k, v := range obj.InputVars {
-				templateData[k] = v
-			}
-			ex.limiter.Wait(context.TODO())
-			renderedObj, err := util.RenderTemplate(obj.objectSpec, templateData, util.MissingKeyError)
+			newObject, segment, err := ex.prepareReplica(obj, labels, iteration, r)
 			if err != nil {
-				log.Fatalf("Template error in %s: %s", obj.ObjectTemplate, err)
+				return
 			}
-			// Re-decode rendered object
-			yamlToUnstructured(renderedObj, newObject)
-			for k, v := range newObject.GetLabels() {
-				labels[k] = v
-			}
-			newObject.SetLabels(labels)
-			setMetadataLabels(newObject, labels)
-			json.Marshal(newObject.Object)
 			// replicaWg is necessary because we want to wait for all replicas
 			// to be created before running any other action such as verify objects,
 			// wait for ready, etc. Without this wait group, running for example,
 			// verify objects can lead into a race condition when some objects
 			// hasn't been created yet
 			replicaWg.Add(1)
-			go func(n string) {
-				if !obj.Namespaced {
-					n = ""
-				}
-				createRequest(obj.gvr, n, newObject, ex.MaxWaitTimeout)
-				replicaWg.Done()
-			}(ns)
+			go func() {
+				defer replicaWg.Done()
+				ex.createReplica(obj, ns, newObject, iteration, segment)
+			}()
 		}(r)
 	}
 	wg.Wait()
 }
 
-func createRequest(gvr schema.GroupVersionResource, ns string, obj *unstructured.Unstructured, timeout time.Duration) {
-	var uns *unstructured.Unstructured
+// createReplicas creates the replicas of the object one after another, returning once all the create requests finished.
+// It's used by the workers of the object pool, so the pool bounds the create requests in flight
+func (ex *Executor) createReplicas(labels map[string]string, obj object, ns string, iteration int) error {
+	var errs []error
+	for r := 1; r <= obj.Replicas; r++ {
+		newObject, segment, err := ex.prepareReplica(obj, labels, iteration, r)
+		if err != nil {
+			return err
+		}
+		errs = append(errs, ex.createReplica(obj, ns, newObject, iteration, segment))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// prepareReplica waits for the job rate limiter and renders the given replica, returning the rate plan segment it was
+// admitted in. Template errors abort the run
+func (ex *Executor) prepareReplica(obj object, labels map[string]string, iteration, r int) (*unstructured.Unstructured, int, error) {
+	throttlingStart := time.Now()
+	segment := ex.ratePlan.admit()
+	ex.limiter.Wait(context.TODO())
+	ex.timer.since(phaseThrottling, throttlingStart)
+	templatingStart := time.Now()
+	newObject, err := ex.renderObject(obj, labels, iteration, r)
+	ex.timer.since(phaseTemplating, templatingStart)
+	if err != nil {
+		ex.failedIterations.fail(iteration)
+		abortRun(err.Error())
+		return nil, segment, err
+	}
+	return newObject, segment, nil
+}
+
+// createReplica sends the create, or apply, request of the rendered replica, recording its outcome
+func (ex *Executor) createReplica(obj object, ns string, newObject *unstructured.Unstructured, iteration, segment int) error {
+	if !obj.Namespaced {
+		ns = ""
+	}
+	if ex.createSem != nil {
+		ex.createSem <- struct{}{}
+	}
+	start := time.Now()
 	var err error
-	RetryWithExponentialBackOff(func() (bool, error) {
+	if obj.ObjectOperation == config.ApplyOperation {
+		err = ex.applyRequest(obj, ns, newObject)
+	} else {
+		err = ex.createRequest(obj.gvr, ns, newObject)
+	}
+	ex.timer.since(phaseAPICalls, start)
+	if err != nil {
+		log.WithField(util.LogFieldIteration, iteration).Errorf("Error creating %s/%s: %s", newObject.GetKind(), newObject.GetName(), err)
+		ex.failedIterations.fail(iteration)
+		ex.failureEvents.captureCreateFailure(ns, newObject)
+		// The runOnce object is retried in the next iteration
+		if obj.RunOnce {
+			ex.releaseRunOnce(obj.index)
+		}
+		err = fmt.Errorf("%s/%s: %s", newObject.GetKind(), newObject.GetName(), err)
+	} else {
+		if ex.created != nil {
+			ex.created.Add(1)
+		}
+		ex.creationRate.record(time.Now())
+		ex.ratePlan.record(segment, time.Now())
+	}
+	if ex.createSem != nil {
+		<-ex.createSem
+		ex.createLatencies.record(start)
+	}
+	chromeTracer.addSpan("create "+newObject.GetKind(), "create", ex.Name, iteration, start, map[string]interface{}{
+		"name":      newObject.GetName(),
+		"namespace": ns,
+	})
+	return err
+}
+
+// renderObject renders the given replica of the object template for the given iteration
+func (ex *Executor) renderObject(obj object, labels map[string]string, iteration, r int) (*unstructured.Unstructured, error) {
+	var newObject = new(unstructured.Unstructured)
+	templateData := map[string]interface{}{
+		jobName:       ex.Name,
+		jobIteration:  iteration,
+		jobUUID:       ex.uuid,
+		replica:       r,
+		nameSuffixVar: nameSuffix(ex.NameSeed, ex.Name, iteration),
+		values:        templateValues,
+		env:           templateEnv,
+	}
+	for k, v := range obj.InputVars {
+		templateData[k] = v
+	}
+	renderedObj, err := util.RenderTemplateWithEngine(templateEngine, obj.objectSpec, templateData, util.MissingKeyError)
+	if err != nil {
+		return nil, fmt.Errorf("job %s: template error in %s, iteration %d, replica %d: %s", ex.Name, obj.ObjectTemplate, iteration, r, err)
+	}
+	// Re-decode rendered object
+	if _, _, err := yamlToUnstructured(renderedObj, newObject); err != nil {
+		return nil, fmt.Errorf("job %s: %s in %s, iteration %d, replica %d", ex.Name, err, obj.ObjectTemplate, iteration, r)
+	}
+	if obj.ResourceSweep != nil {
+		setSweepResources(newObject, obj.ResourceSweep, iteration, ex.JobIterations)
+	}
+	if ex.ReadinessDelay > 0 {
+		setReadinessDelay(newObject, ex.ReadinessDelay, ex.ReadinessProbeCommand)
+	}
+	objectLabels := make(map[string]string, len(labels)+len(injectedLabels))
+	for k, v := range injectedLabels {
+		objectLabels[k] = v
+	}
+	for k, v := range labels {
+		objectLabels[k] = v
+	}
+	for k, v := range newObject.GetLabels() {
+		objectLabels[k] = v
+	}
+	newObject.SetLabels(objectLabels)
+	setMetadataLabels(newObject, objectLabels)
+	if len(injectedAnnotations) > 0 {
+		annotations := make(map[string]string, len(injectedAnnotations))
+		for k, v := range injectedAnnotations {
+			annotations[k] = v
+		}
+		for k, v := range newObject.GetAnnotations() {
+			annotations[k] = v
+		}
+		newObject.SetAnnotations(annotations)
+	}
+	// The custom waitOptions are recorded in the object, so verify checks it with the same criteria
+	if obj.WaitOptions.ForCondition != "" || len(obj.WaitOptions.ForConditions) > 0 || obj.WaitOptions.ForJSONPath != "" {
+		waitOptions, err := json.Marshal(obj.WaitOptions)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: error encoding the waitOptions of %s: %s", ex.Name, obj.ObjectTemplate, err)
+		}
+		annotations := newObject.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+		annotations[waitOptionsAnnotation] = string(waitOptions)
+		newObject.SetAnnotations(annotations)
+	}
+	return newObject, nil
+}
+
+// createRequest creates the object, retrying retryable errors with exponential backoff
+func (ex *Executor) createRequest(gvr schema.GroupVersionResource, ns string, obj *unstructured.Unstructured) error {
+	var uns *unstructured.Unstructured
+	return ex.retryRequest(func() error {
+		var err error
+		start := time.Now()
+		ex.objectMarkers.submitted()
 		if ns != "" {
 			uns, err = DynamicClient.Resource(gvr).Namespace(ns).Create(context.TODO(), obj, metav1.CreateOptions{})
 		} else {
 			uns, err = DynamicClient.Resource(gvr).Create(context.TODO(), obj, metav1.CreateOptions{})
 		}
+		measurements.RecordAPICall("create", obj.GetKind(), ns, obj.GetName(), start, err)
 		if err != nil {
 			if kerrors.IsUnauthorized(err) {
-				log.Fatalf("Authorization error creating %s/%s: %s", obj.GetKind(), obj.GetName(), err)
-				return true, err
+				abortRun(fmt.Sprintf("authorization error creating %s/%s: %s", obj.GetKind(), obj.GetName(), err))
+				return err
 			} else if kerrors.IsAlreadyExists(err) {
 				if ns != "" {
 					log.Errorf("%s/%s in namespace %s already exists", obj.GetKind(), obj.GetName(), ns)
 				} else {
 					log.Errorf("%s/%s already exists", obj.GetKind(), obj.GetName())
 				}
-				return true, nil
-			} else if err != nil {
-				if ns != "" {
-					log.Errorf("Error creating object %s/%s in namespace %s: %s", obj.GetKind(), obj.GetName(), ns, err)
-				} else {
-					log.Errorf("Error creating object %s/%s: %s", obj.GetKind(), obj.GetName(), err)
-				}
+				return nil
+			}
+			if ns != "" {
+				log.Errorf("Error creating object %s/%s in namespace %s: %s", obj.GetKind(), obj.GetName(), ns, err)
+			} else {
+				log.Errorf("Error creating object %s/%s: %s", obj.GetKind(), obj.GetName(), err)
 			}
-			log.Error("Retrying object creation")
-			return false, nil
+			return err
 		}
+		ex.objectMarkers.created()
 		if ns != "" {
 			log.Debugf("Created %s/%s in namespace %s", uns.GetKind(), uns.GetName(), ns)
 		} else {
 			log.Debugf("Created %s/%s", uns.GetKind(), uns.GetName())
 		}
-		return true, err
-	}, 1*time.Second, 3, 0, timeout)
+		return nil
+	}, &ex.retries.create)
+}
+
+// claimRunOnce records the given runOnce object as created in the given namespace, returning false when it already was
+func (ex *Executor) claimRunOnce(objectIndex int, ns string) bool {
+	ex.runOnceLock.Lock()
+	defer ex.runOnceLock.Unlock()
+	if _, created := ex.runOnceNamespaces[objectIndex]; created {
+		return false
+	}
+	ex.runOnceNamespaces[objectIndex] = ns
+	return true
+}
+
+// releaseRunOnce marks the given runOnce object as not created after a failed creation, so it's created again
+func (ex *Executor) releaseRunOnce(objectIndex int) {
+	ex.runOnceLock.Lock()
+	defer ex.runOnceLock.Unlock()
+	delete(ex.runOnceNamespaces, objectIndex)
+}
+
+// runOnceNamespace returns the namespace the given runOnce object was created in
+func (ex *Executor) runOnceNamespace(objectIndex int) string {
+	ex.runOnceLock.Lock()
+	defer ex.runOnceLock.Unlock()
+	return ex.runOnceNamespaces[objectIndex]
+}
+
+// forgetChurnedRunOnceObjects marks the runOnce objects deleted by a churn cycle as not created, so they're re-created
+// along with the churned iterations. Namespaced ones are deleted with their namespace, cluster-scoped ones when the whole job is churned
+func (ex *Executor) forgetChurnedRunOnceObjects(deletedNamespaces []string, allChurned bool) {
+	ex.runOnceLock.Lock()
+	defer ex.runOnceLock.Unlock()
+	for objectIndex, ns := range ex.runOnceNamespaces {
+		if !ex.objects[objectIndex].Namespaced {
+			if allChurned {
+				delete(ex.runOnceNamespaces, objectIndex)
+			}
+			continue
+		}
+		for _, deletedNs := range deletedNamespaces {
+			if ns == deletedNs {
+				delete(ex.runOnceNamespaces, objectIndex)
+				break
+			}
+		}
+	}
 }
 
 // RunCreateJobWithChurn executes a churn creation job
 func (ex *Executor) RunCreateJobWithChurn() {
-	var err error
 	// Determine the number of job iterations to churn (min 1)
 	numToChurn := int(math.Max(float64(ex.ChurnPercent*ex.JobIterations/100), 1))
 	now := time.Now().UTC()
 	rand.NewSource(now.UnixNano())
+	ex.churn = &churnSummary{
+		Timestamp:    now,
+		UUID:         ex.uuid,
+		MetricName:   churnSummaryMetric,
+		JobName:      ex.Name,
+		ChurnPercent: ex.ChurnPercent,
+	}
 	// Create timer for the churn duration
 	timer := time.After(ex.ChurnDuration)
 	// Patch to label namespaces for deletion
 	delPatch := []byte(`[{"op":"add","path":"/metadata/labels/churndelete","value": "delete"}]`)
-	for {
+	for cycle := 0; ; cycle++ {
 		select {
 		case <-timer:
-			log.Info("Churn job complete")
+			ex.churn.EndTimestamp = time.Now().UTC()
+			log.Infof("Churn job complete, %d cycles completed", ex.churn.CyclesCompleted)
+			return
+		case <-jobCtx.Done():
+			ex.churn.EndTimestamp = time.Now().UTC()
+			if jobTimedOut() {
+				log.Warnf("Timeout budget exhausted, churn job stopped after %d cycles", ex.churn.CyclesCompleted)
+			} else {
+				log.Warnf("Run interrupted, churn job stopped after %d cycles", ex.churn.CyclesCompleted)
+			}
 			return
 		default:
 			log.Debugf("Next churn loop, workload churning started %v ago", time.Since(now))
 		}
 		// Max amount of churn is 100% of namespaces
-		randStart := 1
+		randStart := 0
 		if ex.JobIterations-numToChurn+1 > 0 {
 			randStart = rand.Intn(ex.JobIterations - numToChurn + 1)
 		} else {
@@ -318,8 +603,8 @@ func (ex *Executor) RunCreateJobWithChurn() {
 		var namespacesToDelete []string
 		// delete numToChurn namespaces starting at randStart
 		for i := randStart; i < numToChurn+randStart; i++ {
-			ns := ex.generateNamespace(i)
-			if namespacesPatched[ns] {
+			ns, err := ex.generateNamespace(i)
+			if err != nil || namespacesPatched[ns] {
 				continue
 			}
 			// Label namespaces to be deleted
@@ -334,13 +619,30 @@ func (ex *Executor) RunCreateJobWithChurn() {
 		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
 		defer cancel()
 		// Cleanup namespaces based on the labels we added
+		var cleanupErrs []error
 		if ex.ChurnDeletionStrategy == "gvr" {
-			CleanupNamespaceResourcesUsingGVR(ctx, ex.objects, namespacesToDelete, ex.Name)
+			cleanupErrs = append(cleanupErrs, CleanupNamespaceResourcesUsingGVR(ctx, ex.objects, namespacesToDelete, ex.Name))
+		}
+		if ex.ChurnTeardownWaveSize > 0 {
+			ex.teardownChurnWaves(ctx, namespacesToDelete, cycle)
+		} else {
+			cleanupErrs = append(cleanupErrs, CleanupNamespaces(ctx, metav1.ListOptions{LabelSelector: "churndelete=delete"}, true))
+		}
+		// When the whole job is churned, its cluster-scoped objects are deleted as well so they're re-created from scratch
+		if numToChurn == ex.JobIterations {
+			cleanupErrs = append(cleanupErrs, CleanupNonNamespacedResourcesUsingGVR(ctx, []Executor{*ex}, true))
+		}
+		if err := utilerrors.NewAggregate(cleanupErrs); err != nil {
+			ex.churn.EndTimestamp = time.Now().UTC()
+			abortRun(fmt.Sprintf("job %s: churn cycle %d: %s", ex.Name, cycle, err))
+			return
 		}
-		CleanupNamespaces(ctx, metav1.ListOptions{LabelSelector: "churndelete=delete"}, true)
+		ex.forgetChurnedRunOnceObjects(namespacesToDelete, numToChurn == ex.JobIterations)
 		log.Info("Re-creating deleted objects")
 		// Re-create objects that were deleted
 		ex.RunCreateJob(randStart, numToChurn+randStart, &[]string{})
+		ex.churn.CyclesCompleted++
+		ex.churn.ChurnedIterations += numToChurn
 		log.Infof("Sleeping for %v", ex.ChurnDelay)
 		time.Sleep(ex.ChurnDelay)
 	}
@@ -0,0 +1,127 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	log "github.com/sirupsen/logrus"
+)
+
+const creationRateMetric = "creationRate"
+
+// creationRateTracker records the time of the first and last objects created by a job paced with a rate
+type creationRateTracker struct {
+	sync.Mutex
+	objects     int64
+	first, last time.Time
+}
+
+// creationRateSummary holds the target and achieved creation rates of a job
+type creationRateSummary struct {
+	Timestamp     time.Time              `json:"timestamp"`
+	UUID          string                 `json:"uuid"`
+	MetricName    string                 `json:"metricName"`
+	JobName       string                 `json:"jobName"`
+	TargetRate    float64                `json:"targetRate"`
+	AchievedRate  float64                `json:"achievedRate"`
+	Objects       int64                  `json:"objects"`
+	FirstCreation time.Time              `json:"firstCreation"`
+	LastCreation  time.Time              `json:"lastCreation"`
+	Segments      []rateSegmentSummary   `json:"segments,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// record records an object created at the given time
+func (c *creationRateTracker) record(t time.Time) {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	if c.objects == 0 || t.Before(c.first) {
+		c.first = t
+	}
+	if t.After(c.last) {
+		c.last = t
+	}
+	c.objects++
+}
+
+// achieved returns the objects created per second between the first and the last creation
+func (c *creationRateTracker) achieved() float64 {
+	c.Lock()
+	defer c.Unlock()
+	elapsed := c.last.Sub(c.first).Seconds()
+	if c.objects < 2 || elapsed <= 0 {
+		return 0
+	}
+	// A rate of r objects per second creates n objects in (n-1)/r seconds
+	return float64(c.objects-1) / elapsed
+}
+
+func (ex *Executor) creationRateSummary(metadata map[string]interface{}) creationRateSummary {
+	var segments []rateSegmentSummary
+	if ex.ratePlan != nil {
+		segments = ex.ratePlan.timeline()
+	}
+	achieved := ex.creationRate.achieved()
+	ex.creationRate.Lock()
+	defer ex.creationRate.Unlock()
+	return creationRateSummary{
+		Timestamp:     time.Now().UTC(),
+		UUID:          ex.uuid,
+		MetricName:    creationRateMetric,
+		JobName:       ex.Name,
+		TargetRate:    ex.Rate,
+		AchievedRate:  achieved,
+		Objects:       ex.creationRate.objects,
+		FirstCreation: ex.creationRate.first,
+		LastCreation:  ex.creationRate.last,
+		Segments:      segments,
+		Metadata:      metadata,
+	}
+}
+
+// logCreationRate reports the achieved creation rate of a job paced with a rate, or of every segment of its rate plan
+func (ex *Executor) logCreationRate() {
+	if ex.creationRate == nil {
+		return
+	}
+	if ex.ratePlan != nil {
+		for i, segment := range ex.ratePlan.timeline() {
+			log.Infof("Job %s: rate plan segment %d, %d objects created from %s to %s, target creation rate %.2f objects/s, achieved %.2f objects/s",
+				ex.Name, i, segment.Objects, segment.FirstCreation.Format(time.RFC3339), segment.LastCreation.Format(time.RFC3339), segment.TargetRate, segment.AchievedRate)
+		}
+		return
+	}
+	log.Infof("Job %s: target creation rate %.2f objects/s, achieved %.2f objects/s", ex.Name, ex.Rate, ex.creationRate.achieved())
+}
+
+// indexCreationRate indexes the target and achieved creation rates of a job paced with a rate
+func (ex *Executor) indexCreationRate(indexer *indexers.Indexer, metadata map[string]interface{}) {
+	if ex.creationRate == nil || ex.SkipIndexing {
+		return
+	}
+	log.Infof("Indexing metric %s", creationRateMetric)
+	resp, err := (*indexer).Index([]interface{}{ex.creationRateSummary(metadata)}, indexers.IndexingOpts{MetricName: creationRateMetric})
+	if err != nil {
+		log.Error(err)
+	} else {
+		log.Info(resp)
+	}
+}
@@ -16,6 +16,7 @@ package burner
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -29,41 +30,56 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 )
 
-func setupDeleteJob(jobConfig config.Job) Executor {
+func setupDeleteJob(jobConfig config.Job) (Executor, error) {
 	var ex Executor
 	log.Debugf("Preparing delete job: %s", jobConfig.Name)
-	mapper := newRESTMapper()
+	mapper, err := newRESTMapper()
+	if err != nil {
+		return ex, err
+	}
 	for _, o := range jobConfig.Objects {
 		if o.APIVersion == "" {
 			o.APIVersion = "v1"
 		}
 		gvk := schema.FromAPIVersionAndKind(o.APIVersion, o.Kind)
-		mapping, err := mapper.RESTMapping(gvk.GroupKind())
+		mapping, err := restMapping(mapper, gvk)
 		if err != nil {
-			log.Fatal(err)
+			return ex, err
 		}
 		if len(o.LabelSelector) == 0 {
-			log.Fatalf("Empty labelSelectors not allowed with: %s", o.Kind)
+			return ex, fmt.Errorf("empty labelSelectors not allowed with: %s", o.Kind)
 		}
 		obj := object{
 			gvr:           mapping.Resource,
 			labelSelector: o.LabelSelector,
+			Object:        o,
 		}
 		obj.Namespaced = mapping.Scope.Name() == meta.RESTScopeNameNamespace
-		log.Debugf("Job %s: Delete %s with selector %s", jobConfig.Name, gvk.Kind, labels.Set(obj.labelSelector))
+		log.Debugf("Job %s: Delete %s with selector %s", jobConfig.Name, gvk.Kind, deleteSelector(obj))
 		ex.objects = append(ex.objects, obj)
 	}
-	return ex
+	return ex, nil
 }
 
 // RunDeleteJob executes a deletion job
 func (ex *Executor) RunDeleteJob() {
 	var wg sync.WaitGroup
 	var itemList *unstructured.UnstructuredList
+	var deleteOptions metav1.DeleteOptions
+	deletionTimeout := ex.DeletionTimeout
+	if deletionTimeout == 0 {
+		deletionTimeout = ex.MaxWaitTimeout
+	}
+	if ex.ForegroundDeletion {
+		// Objects are kept until their dependents are deleted, so once they're gone their dependents are gone as well
+		foreground := metav1.DeletePropagationForeground
+		deleteOptions.PropagationPolicy = &foreground
+	}
 	for _, obj := range ex.objects {
-		labelSelector := labels.Set(obj.labelSelector).String()
+		labelSelector := deleteSelector(obj)
 		listOptions := metav1.ListOptions{
-			LabelSelector: labelSelector,
+			LabelSelector: labels.Set(obj.labelSelector).String(),
+			FieldSelector: obj.FieldSelector,
 		}
 		err := RetryWithExponentialBackOff(func() (done bool, err error) {
 			itemList, err = DynamicClient.Resource(obj.gvr).List(context.TODO(), listOptions)
@@ -77,19 +93,26 @@ func (ex *Executor) RunDeleteJob() {
 			continue
 		}
 		log.Infof("Found %d %s with selector %s, removing them", len(itemList.Items), obj.gvr.Resource, labelSelector)
+		if obj.FieldSelector != "" {
+			reportUnmatchedObjects(obj, len(itemList.Items))
+		}
 		for _, item := range itemList.Items {
 			wg.Add(1)
 			go func(item unstructured.Unstructured) {
 				defer wg.Done()
+				throttlingStart := time.Now()
 				ex.limiter.Wait(context.TODO())
+				ex.timer.since(phaseThrottling, throttlingStart)
 				var err error
+				start := time.Now()
 				if obj.Namespaced {
 					log.Debugf("Removing %s/%s from namespace %s", item.GetKind(), item.GetName(), item.GetNamespace())
-					err = DynamicClient.Resource(obj.gvr).Namespace(item.GetNamespace()).Delete(context.TODO(), item.GetName(), metav1.DeleteOptions{})
+					err = DynamicClient.Resource(obj.gvr).Namespace(item.GetNamespace()).Delete(context.TODO(), item.GetName(), deleteOptions)
 				} else {
 					log.Debugf("Removing %s/%s", item.GetKind(), item.GetName())
-					err = DynamicClient.Resource(obj.gvr).Delete(context.TODO(), item.GetName(), metav1.DeleteOptions{})
+					err = DynamicClient.Resource(obj.gvr).Delete(context.TODO(), item.GetName(), deleteOptions)
 				}
+				ex.timer.since(phaseAPICalls, start)
 				if err != nil {
 					log.Errorf("Error found removing %s/%s: %s", item.GetKind(), item.GetName(), err)
 				}
@@ -99,9 +122,11 @@ func (ex *Executor) RunDeleteJob() {
 				time.Sleep(ex.JobIterationDelay)
 			}
 		}
+		wg.Wait()
 		if ex.Job.WaitForDeletion {
-			wait.PollUntilContextCancel(context.TODO(), 2*time.Second, true, func(ctx context.Context) (done bool, err error) {
-				itemList, err = DynamicClient.Resource(obj.gvr).List(context.TODO(), listOptions)
+			waitStart := time.Now()
+			err = wait.PollUntilContextTimeout(jobCtx, 2*time.Second, deletionTimeout, true, func(ctx context.Context) (done bool, err error) {
+				itemList, err = DynamicClient.Resource(obj.gvr).List(ctx, listOptions)
 				if err != nil {
 					log.Error(err.Error())
 					return false, nil
@@ -112,6 +137,34 @@ func (ex *Executor) RunDeleteJob() {
 				}
 				return true, nil
 			})
+			ex.timer.since(phaseWaiting, waitStart)
+			if err != nil {
+				log.Errorf("Error waiting for %s labeled with %s to be deleted: %s", obj.gvr.Resource, labelSelector, err)
+			} else {
+				log.Infof("%s labeled with %s deleted in %v", obj.gvr.Resource, labelSelector, time.Since(waitStart).Round(time.Millisecond))
+			}
 		}
 	}
 }
+
+// deleteSelector returns the label selector of the object, followed by its field selector when it has one
+func deleteSelector(obj object) string {
+	selector := labels.Set(obj.labelSelector).String()
+	if obj.FieldSelector != "" {
+		selector = fmt.Sprintf("%s and fields %s", selector, obj.FieldSelector)
+	}
+	return selector
+}
+
+// reportUnmatchedObjects logs the objects matching the label selector but not the field selector, which are left intact
+func reportUnmatchedObjects(obj object, matched int) {
+	labelSelector := labels.Set(obj.labelSelector).String()
+	itemList, err := DynamicClient.Resource(obj.gvr).List(context.TODO(), metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		log.Errorf("Error found listing %s labeled with %s: %s", obj.gvr.Resource, labelSelector, err)
+		return
+	}
+	if unmatched := len(itemList.Items) - matched; unmatched > 0 {
+		log.Infof("%d %s labeled with %s don't match the field selector %s, leaving them intact", unmatched, obj.gvr.Resource, labelSelector, obj.FieldSelector)
+	}
+}
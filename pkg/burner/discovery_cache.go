@@ -0,0 +1,122 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/restmapper"
+)
+
+// discoveryCache holds the API resources discovered during the run, so the REST mappings of the objects, and whether
+// they're namespaced, are resolved without querying discovery for every job
+var discoveryCache struct {
+	sync.Mutex
+	groupResources []*restmapper.APIGroupResources
+	mapper         meta.RESTMapper
+	// static set when the mapper was built without discovery, it's never refreshed
+	static bool
+}
+
+// resetDiscoveryCache drops the discovery results of a previous run
+func resetDiscoveryCache() {
+	discoveryCache.Lock()
+	defer discoveryCache.Unlock()
+	discoveryCache.groupResources = nil
+	discoveryCache.mapper = nil
+	discoveryCache.static = false
+}
+
+// useStaticDiscovery replaces discovery with the given mapper, e.g. in dry runs, which don't reach the API server
+func useStaticDiscovery(mapper meta.RESTMapper) {
+	discoveryCache.Lock()
+	defer discoveryCache.Unlock()
+	discoveryCache.groupResources = nil
+	discoveryCache.mapper = mapper
+	discoveryCache.static = true
+}
+
+// cachedDiscovery returns the cached API group resources and their RESTMapper, discovering them when the cache is empty
+// or refresh is set, e.g. to find the kinds of CRDs installed during the run. Groups failing discovery, like broken
+// aggregated API services, are skipped
+func cachedDiscovery(refresh bool) ([]*restmapper.APIGroupResources, meta.RESTMapper, error) {
+	discoveryCache.Lock()
+	defer discoveryCache.Unlock()
+	if discoveryCache.mapper != nil && (!refresh || discoveryCache.static) {
+		return discoveryCache.groupResources, discoveryCache.mapper, nil
+	}
+	var client discovery.DiscoveryInterface = discoveryClient
+	if discoveryClient == nil {
+		client = ClientSet.Discovery()
+	}
+	groupResources, err := restmapper.GetAPIGroupResources(client)
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, nil, err
+		}
+		failedDiscoveryGroups = err.(*discovery.ErrGroupDiscoveryFailed).Groups
+		logFailedDiscoveryGroups(failedDiscoveryGroups)
+	}
+	log.Debugf("Discovered %d API groups", len(groupResources))
+	discoveryCache.groupResources = groupResources
+	discoveryCache.mapper = restmapper.NewDiscoveryRESTMapper(groupResources)
+	return discoveryCache.groupResources, discoveryCache.mapper, nil
+}
+
+// clusterScopedResources returns the preferred version of the listable cluster-scoped resources, refreshing the cache
+func clusterScopedResources() ([]schema.GroupVersionResource, error) {
+	return listableResources(false)
+}
+
+// namespacedResources returns the preferred version of the listable namespaced resources, refreshing the cache
+func namespacedResources() ([]schema.GroupVersionResource, error) {
+	return listableResources(true)
+}
+
+func listableResources(namespaced bool) ([]schema.GroupVersionResource, error) {
+	groupResources, _, err := cachedDiscovery(true)
+	if err != nil {
+		return nil, err
+	}
+	var resources []schema.GroupVersionResource
+	listable := discovery.SupportsAllVerbs{Verbs: []string{"list"}}
+	for _, group := range groupResources {
+		version := group.Group.PreferredVersion.Version
+		for _, resource := range group.VersionedResources[version] {
+			// Subresources can't be listed on their own
+			if resource.Namespaced != namespaced || strings.Contains(resource.Name, "/") || !listable.Match(group.Group.Name+"/"+version, &resource) {
+				continue
+			}
+			resources = append(resources, schema.GroupVersionResource{
+				Group:    group.Group.Name,
+				Version:  version,
+				Resource: resource.Name,
+			})
+		}
+	}
+	return resources, nil
+}
+
+// staticDiscovery returns true when the mapper was built without discovery
+func staticDiscovery() bool {
+	discoveryCache.Lock()
+	defer discoveryCache.Unlock()
+	return discoveryCache.static
+}
@@ -0,0 +1,199 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// clusterScopedKinds built-in kinds that aren't namespaced
+var clusterScopedKinds = map[string]bool{
+	"APIService":                       true,
+	"CertificateSigningRequest":        true,
+	"ClusterRole":                      true,
+	"ClusterRoleBinding":               true,
+	"ComponentStatus":                  true,
+	"CSIDriver":                        true,
+	"CSINode":                          true,
+	"CustomResourceDefinition":         true,
+	"FlowSchema":                       true,
+	"IngressClass":                     true,
+	"MutatingWebhookConfiguration":     true,
+	"Namespace":                        true,
+	"Node":                             true,
+	"PersistentVolume":                 true,
+	"PriorityClass":                    true,
+	"PriorityLevelConfiguration":       true,
+	"RuntimeClass":                     true,
+	"StorageClass":                     true,
+	"ValidatingAdmissionPolicy":        true,
+	"ValidatingAdmissionPolicyBinding": true,
+	"ValidatingWebhookConfiguration":   true,
+	"VolumeAttachment":                 true,
+}
+
+// staticRESTMapper maps the built-in kinds of the client-go scheme to their resources without querying discovery.
+// Other kinds are resolved by restMapping
+func staticRESTMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(scheme.Scheme.PrioritizedVersionsAllGroups())
+	for gvk := range scheme.Scheme.AllKnownTypes() {
+		if strings.HasSuffix(gvk.Kind, "List") || gvk.Version == runtime.APIVersionInternal {
+			continue
+		}
+		scope := meta.RESTScopeNamespace
+		if clusterScopedKinds[gvk.Kind] {
+			scope = meta.RESTScopeRoot
+		}
+		// Endpoints is already plural
+		if gvk.Kind == "Endpoints" {
+			mapper.AddSpecific(gvk, gvk.GroupVersion().WithResource("endpoints"), gvk.GroupVersion().WithResource("endpoints"), scope)
+			continue
+		}
+		mapper.Add(gvk, scope)
+	}
+	return mapper
+}
+
+// dryRun renders the objects of every job without sending any create, patch or delete request to the API server.
+// Rendered objects are logged, or written into the output directory when configured
+func dryRun(configSpec config.Spec, timeout time.Duration) (int, error) {
+	log.Infof("Dry run with UUID %s, no objects will be created, patched or deleted", configSpec.GlobalConfig.UUID)
+	executorList, err := newExecutorList(configSpec, configSpec.GlobalConfig.UUID, timeout)
+	if err != nil {
+		return 1, err
+	}
+	for _, ex := range executorList {
+		switch ex.JobType {
+		case config.CreationJob:
+			iterations := make([]int, ex.JobIterations)
+			for i := range iterations {
+				iterations[i] = i
+			}
+			if configSpec.Retry != nil {
+				iterations = configSpec.Retry.Iterations[ex.Name]
+			}
+			if err := ex.dryRunCreateJob(iterations, configSpec.DryRun.OutputDir); err != nil {
+				return 1, err
+			}
+		case config.DeletionJob, config.PatchJob:
+			for _, obj := range ex.objects {
+				log.Infof("Job %s: %s job would %s %s with selector %s", ex.Name, ex.JobType, ex.JobType, obj.gvr.Resource, labels.Set(obj.labelSelector))
+			}
+		case config.BaselineJob:
+			log.Infof("Job %s: baseline job would collect measurements for %v", ex.Name, ex.BaselineDuration)
+		case config.PauseJob:
+			log.Infof("Job %s: pause job would pause for %v with a %v%% jitter", ex.Name, ex.PauseDuration, ex.Jitter)
+		}
+	}
+	return 0, nil
+}
+
+// dryRunCreateJob renders all the object replicas of the given iterations
+func (ex *Executor) dryRunCreateJob(iterations []int, outputDir string) error {
+	var jobDir string
+	namespaces := make(map[string]bool)
+	kinds := make(map[string]int)
+	runOnceRendered := make(map[int]bool)
+	if outputDir != "" {
+		jobDir = path.Join(outputDir, ex.Name)
+		if err := os.MkdirAll(jobDir, 0744); err != nil {
+			return fmt.Errorf("error creating dry run output directory %s: %s", jobDir, err)
+		}
+	}
+	for _, i := range iterations {
+		ns := ex.Namespace
+		if ex.NamespacedIterations {
+			var err error
+			if ns, err = ex.generateNamespace(i); err != nil {
+				return err
+			}
+		}
+		namespaces[ns] = true
+		pick := ex.objectSelector.pick()
+		for _, objectIndex := range ex.objectOrderer.order(len(ex.objects)) {
+			obj := ex.objects[objectIndex]
+			if !selected(pick, objectIndex, obj) {
+				continue
+			}
+			if obj.RunOnce {
+				if runOnceRendered[objectIndex] {
+					continue
+				}
+				runOnceRendered[objectIndex] = true
+			}
+			labels := map[string]string{
+				"kube-burner-uuid":  ex.uuid,
+				"kube-burner-job":   ex.Name,
+				"kube-burner-index": strconv.Itoa(objectIndex),
+				"kube-burner-runid": ex.runid,
+			}
+			for r := 1; r <= obj.Replicas; r++ {
+				newObject, err := ex.renderObject(obj, labels, i, r)
+				if err != nil {
+					return err
+				}
+				if obj.Namespaced {
+					newObject.SetNamespace(ns)
+				}
+				kinds[newObject.GetKind()]++
+				if err := ex.writeDryRunObject(newObject, jobDir, fmt.Sprintf("%d-%d-%d-%s.yml", i, objectIndex, r, newObject.GetKind())); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	var summary []string
+	for kind, count := range kinds {
+		summary = append(summary, fmt.Sprintf("%d %s", count, kind))
+	}
+	sort.Strings(summary)
+	log.Infof("Job %s: %d iterations would create %d namespaces and %v", ex.Name, len(iterations), len(namespaces), summary)
+	ex.logObjectSelectionDistribution()
+	if ex.Churn {
+		log.Infof("Job %s: churn would re-create %d%% of the iterations every %v for %v", ex.Name, ex.ChurnPercent, ex.ChurnDelay, ex.ChurnDuration)
+	}
+	return nil
+}
+
+// writeDryRunObject logs the rendered object, or writes it into the given directory
+func (ex *Executor) writeDryRunObject(obj *unstructured.Unstructured, dir, fileName string) error {
+	rendered, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return fmt.Errorf("error marshaling %s/%s: %s", obj.GetKind(), obj.GetName(), err)
+	}
+	if dir == "" {
+		log.Infof("Rendered %s/%s:\n%s", obj.GetKind(), obj.GetName(), rendered)
+		return nil
+	}
+	if err := os.WriteFile(path.Join(dir, fileName), rendered, 0644); err != nil {
+		return fmt.Errorf("error writing rendered object: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+const (
+	failureEventsMetric = "failureEvents"
+	createFailure       = "create"
+	waitFailure         = "wait"
+)
+
+// failureEvent is a kubernetes event captured after a failed creation or readiness wait
+type failureEvent struct {
+	Timestamp      time.Time              `json:"timestamp"`
+	UUID           string                 `json:"uuid"`
+	MetricName     string                 `json:"metricName"`
+	JobName        string                 `json:"jobName"`
+	Failure        string                 `json:"failure"`
+	Namespace      string                 `json:"namespace"`
+	Kind           string                 `json:"kind"`
+	Name           string                 `json:"name,omitempty"`
+	InvolvedObject string                 `json:"involvedObject"`
+	Type           string                 `json:"type"`
+	Reason         string                 `json:"reason"`
+	Message        string                 `json:"message"`
+	Count          int32                  `json:"count"`
+	LastSeen       time.Time              `json:"lastSeen"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// failureEventRecorder captures the most recent events related to failed creations and readiness waits
+type failureEventRecorder struct {
+	lock      sync.Mutex
+	maxEvents int
+	events    []failureEvent
+}
+
+// newFailureEventRecorder returns a recorder fetching up to maxEvents per failure, or nil when disabled
+func newFailureEventRecorder(maxEvents int) *failureEventRecorder {
+	if maxEvents < 1 {
+		return nil
+	}
+	return &failureEventRecorder{maxEvents: maxEvents}
+}
+
+// captureCreateFailure captures the events of the object that failed to be created
+func (r *failureEventRecorder) captureCreateFailure(ns string, obj *unstructured.Unstructured) {
+	if r == nil {
+		return
+	}
+	selector := fields.Set{
+		"involvedObject.kind": obj.GetKind(),
+		"involvedObject.name": obj.GetName(),
+	}
+	r.capture(createFailure, ns, obj.GetKind(), obj.GetName(), selector)
+}
+
+// captureWaitFailure captures the warning events of the namespace where the objects of the given kind didn't become ready.
+// Readiness waits are performed per namespace, and the events explaining the failure usually belong
+// to objects owned by the waited ones, like the pods of a deployment
+func (r *failureEventRecorder) captureWaitFailure(ns, kind string) {
+	if r == nil {
+		return
+	}
+	r.capture(waitFailure, ns, kind, "", fields.Set{"type": corev1.EventTypeWarning})
+}
+
+func (r *failureEventRecorder) capture(failure, ns, kind, name string, selector fields.Set) {
+	events, err := ClientSet.CoreV1().Events(ns).List(context.TODO(), metav1.ListOptions{
+		FieldSelector: selector.AsSelector().String(),
+	})
+	if err != nil {
+		log.Errorf("Error fetching events of failed %s %s/%s: %s", failure, kind, name, err)
+		return
+	}
+	items := events.Items
+	sort.Slice(items, func(i, j int) bool {
+		return eventLastSeen(items[i]).After(eventLastSeen(items[j]))
+	})
+	if len(items) > r.maxEvents {
+		items = items[:r.maxEvents]
+	}
+	if len(items) == 0 {
+		log.Warnf("No events found for failed %s of %s %s", failure, kind, failedObjectName(ns, name))
+		return
+	}
+	log.Warnf("Recent events for failed %s of %s %s:", failure, kind, failedObjectName(ns, name))
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, event := range items {
+		involvedObject := fmt.Sprintf("%s/%s", event.InvolvedObject.Kind, event.InvolvedObject.Name)
+		log.Warnf("  %s %s %s: %s", event.Type, involvedObject, event.Reason, event.Message)
+		r.events = append(r.events, failureEvent{
+			Timestamp:      time.Now().UTC(),
+			MetricName:     failureEventsMetric,
+			Failure:        failure,
+			Namespace:      ns,
+			Kind:           kind,
+			Name:           name,
+			InvolvedObject: involvedObject,
+			Type:           event.Type,
+			Reason:         event.Reason,
+			Message:        event.Message,
+			Count:          event.Count,
+			LastSeen:       eventLastSeen(event),
+		})
+	}
+}
+
+// eventLastSeen returns the last time the event was observed, considering both the core/v1 and events.k8s.io fields
+func eventLastSeen(event corev1.Event) time.Time {
+	switch {
+	case event.Series != nil:
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+func failedObjectName(ns, name string) string {
+	switch {
+	case name == "":
+		return fmt.Sprintf("in namespace %s", ns)
+	case ns == "":
+		return name
+	default:
+		return fmt.Sprintf("%s in namespace %s", name, ns)
+	}
+}
+
+// indexFailureEvents indexes the events captured during the job
+func (ex *Executor) indexFailureEvents(indexer *indexers.Indexer, metadata map[string]interface{}) {
+	var docs []interface{}
+	if ex.failureEvents == nil || ex.SkipIndexing {
+		return
+	}
+	for _, event := range ex.failureEvents.events {
+		event.UUID = ex.uuid
+		event.JobName = ex.Name
+		event.Metadata = metadata
+		docs = append(docs, event)
+	}
+	if len(docs) == 0 {
+		return
+	}
+	log.Infof("Indexing metric %s", failureEventsMetric)
+	resp, err := (*indexer).Index(docs, indexers.IndexingOpts{MetricName: failureEventsMetric})
+	if err != nil {
+		log.Error(err)
+	} else {
+		log.Info(resp)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// RunContext describes the run the hooks are called within
+type RunContext struct {
+	// Context is cancelled when the run is interrupted
+	Context context.Context
+	// UUID of the run
+	UUID string
+	// Metadata user metadata of the run
+	Metadata map[string]interface{}
+}
+
+// Hooks is implemented by programs embedding kube-burner to run custom logic during the run. An error returned by
+// PreJob skips the job, errors returned by the rest of callbacks are reported in the run result
+type Hooks interface {
+	// PreJob is called before the job starts
+	PreJob(rc RunContext, job config.Job) error
+	// PostJob is called once the job finishes, before its measurements are stopped
+	PostJob(rc RunContext, job config.Job, result JobResult) error
+	// PreDelete is called before a deletion job and, when garbage collection is enabled, for each creation job before its objects are removed
+	PreDelete(rc RunContext, job config.Job) error
+	// PostRun is called once the run finishes, right before Run returns
+	PostRun(rc RunContext, result Result) error
+}
+
+// NoopHooks implements Hooks doing nothing, it can be embedded to implement only some of the callbacks
+type NoopHooks struct{}
+
+// PreJob does nothing
+func (NoopHooks) PreJob(RunContext, config.Job) error { return nil }
+
+// PostJob does nothing
+func (NoopHooks) PostJob(RunContext, config.Job, JobResult) error { return nil }
+
+// PreDelete does nothing
+func (NoopHooks) PreDelete(RunContext, config.Job) error { return nil }
+
+// PostRun does nothing
+func (NoopHooks) PostRun(RunContext, Result) error { return nil }
+
+var hooksLock sync.Mutex
+var registeredHooks []Hooks
+
+// RegisterHooks registers hooks called by the following calls to Run, in registration order
+func RegisterHooks(hooks ...Hooks) {
+	hooksLock.Lock()
+	defer hooksLock.Unlock()
+	registeredHooks = append(registeredHooks, hooks...)
+}
+
+// ResetHooks removes all the registered hooks
+func ResetHooks() {
+	hooksLock.Lock()
+	defer hooksLock.Unlock()
+	registeredHooks = nil
+}
+
+// callHooks calls the given callback of every registered hook and returns the first error
+func callHooks(stage string, call func(Hooks) error) error {
+	hooksLock.Lock()
+	hooks := append([]Hooks{}, registeredHooks...)
+	hooksLock.Unlock()
+	for _, h := range hooks {
+		if err := call(h); err != nil {
+			err = fmt.Errorf("%s hook: %s", stage, err)
+			log.Error(err.Error())
+			return err
+		}
+	}
+	return nil
+}
+
+// runPreDeleteHooks calls the PreDelete hooks for the creation jobs of the run
+func runPreDeleteHooks(rc RunContext, jobList []Executor) []error {
+	var errs []error
+	for _, job := range jobList {
+		if job.JobType != config.CreationJob {
+			continue
+		}
+		if err := callHooks("preDelete", func(h Hooks) error { return h.PreDelete(rc, job.Job) }); err != nil {
+			errs = append(errs, fmt.Errorf("job %s: %s", job.Name, err))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// runCtx is cancelled when the run is interrupted, waits use jobCtx, derived from it, so they return as soon as it happens
+var runCtx = context.Background()
+
+// cancelRun cancels runCtx, it's also used to abort the run from within, i.e. when a critical alert fires
+var cancelRun = func() {}
+
+// abortReason is set when the run is aborted from within rather than interrupted through the context given to Run
+var abortReason struct {
+	sync.Mutex
+	reason string
+}
+
+// startRun derives runCtx from the context given to Run, so the run stops creating objects and finishes gracefully
+// once it's cancelled. The returned function releases runCtx
+func startRun(ctx context.Context) func() {
+	runCtx, cancelRun = context.WithCancel(ctx)
+	abortReason.Lock()
+	abortReason.reason = ""
+	abortReason.Unlock()
+	return cancelRun
+}
+
+// interrupted returns true when the run has been interrupted or aborted
+func interrupted() bool {
+	return runCtx.Err() != nil
+}
+
+// abortRun stops the run gracefully as an interruption would, recording the reason. Only the first reason is kept
+func abortRun(reason string) {
+	abortReason.Lock()
+	defer abortReason.Unlock()
+	if abortReason.reason != "" || interrupted() {
+		return
+	}
+	log.Errorf("Aborting run: %s", reason)
+	abortReason.reason = reason
+	cancelRun()
+}
+
+// aborted returns the reason the run was aborted for, empty when it wasn't
+func aborted() string {
+	abortReason.Lock()
+	defer abortReason.Unlock()
+	return abortReason.reason
+}
@@ -20,9 +20,12 @@ import (
 	"embed"
 	"errors"
 	"fmt"
+	"math"
 	"os/exec"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cloud-bulldozer/go-commons/indexers"
@@ -31,6 +34,7 @@ import (
 	"github.com/cloud-bulldozer/kube-burner/pkg/config"
 	"github.com/cloud-bulldozer/kube-burner/pkg/measurements"
 	"github.com/cloud-bulldozer/kube-burner/pkg/prometheus"
+	"github.com/cloud-bulldozer/kube-burner/pkg/util"
 	"github.com/cloud-bulldozer/kube-burner/pkg/util/metrics"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/time/rate"
@@ -49,6 +53,8 @@ type object struct {
 	labelSelector map[string]string
 	patchType     string
 	kind          string
+	// index of the object within the job, as in the kube-burner-index label
+	index int
 	config.Object
 }
 
@@ -59,46 +65,186 @@ type Executor struct {
 	uuid    string
 	runid   string
 	limiter *rate.Limiter
+	// createSem limits the number of concurrent create requests when set
+	createSem       chan struct{}
+	createLatencies *latencyRecorder
+	nsLabeler       *namespaceLabeler
+	// nsStagger spaces out the namespace creations when set
+	nsStagger *namespaceStagger
+	// objectMarkers records when the first object was submitted and the last one created and ready
+	objectMarkers *objectMarkers
+	// failedIterations keeps track of the iterations with objects that couldn't be created
+	failedIterations *iterationTracker
+	timer            *phaseTimer
+	// failureEvents captures the events related to failed creations and readiness waits
+	failureEvents *failureEventRecorder
+	// churnTeardownWaves holds the teardown waves of the churn cycles
+	churnTeardownWaves []churnTeardownWave
+	// churn holds the cycles completed by the churn loop
+	churn *churnSummary
+	// retries counts the requests retried due to retryable errors
+	retries *requestRetries
+	// created counts the objects successfully created
+	created *atomic.Int64
+	// objectSelector picks the object created in each iteration when the job uses weighted selection
+	objectSelector *objectSelector
+	// objectOrderer shuffles the objects of each iteration when the job uses shuffled ordering
+	objectOrderer *objectOrderer
+	// objectPool creates the objects of each iteration concurrently when the job sets objectConcurrency
+	objectPool *objectPool
+	// dependencies orders the objects of each iteration by their dependencies when any object depends on another one
+	dependencies *objectDependencies
+	// runOnceNamespaces holds the namespace where each runOnce object, by object index, was created
+	runOnceNamespaces map[int]string
+	runOnceLock       *sync.Mutex
+	// creationRate tracks the achieved creation rate when the job is paced with a rate
+	creationRate *creationRateTracker
+	// ratePlan paces the creation across the segments of the job rate plan
+	ratePlan *ratePlan
+	// reusedNamespaces existing namespaces the iterations are distributed across when the job reuses namespaces
+	reusedNamespaces []string
 }
 
 const (
 	jobName              = "JobName"
 	replica              = "Replica"
+	nameSuffixVar        = "NameSuffix"
 	jobIteration         = "Iteration"
 	jobUUID              = "UUID"
+	values               = "Values"
+	env                  = "Env"
 	rcTimeout            = 2
+	rcLeak               = 3
+	rcInterrupted        = 4
+	rcAborted            = 5
 	garbageCollectionJob = "garbage-collection"
 )
 
 var ClientSet *kubernetes.Clientset
 var DynamicClient dynamic.Interface
+
+// DeletionConcurrency maximum number of namespaces deleted in parallel
+var DeletionConcurrency = 10
 var discoveryClient *discovery.DiscoveryClient
+
+// failedDiscoveryGroups holds the group versions whose discovery failed
+var failedDiscoveryGroups map[schema.GroupVersion]error
 var restConfig *rest.Config
 var embedFS embed.FS
 var embedFSDir string
 
+// templateValues and templateEnv are exposed to the object templates as .Values and .Env
+var templateValues, templateEnv map[string]interface{}
+
+// templateEngine functions available to the object templates and namespace patterns
+var templateEngine util.TemplateEngine
+
+// injectedLabels and injectedAnnotations are added to every object and namespace created
+var injectedLabels, injectedAnnotations map[string]string
+
+// Run executes the jobs of the given configuration and returns the result of the run. It never exits the process,
+// so it can be called repeatedly by programs embedding kube-burner, the returned error aggregates Result.Errors.
+// Cancelling ctx interrupts the run, which stops gracefully
+//
 //nolint:gocyclo
-func Run(configSpec config.Spec, prometheusClients []*prometheus.Prometheus, alertMs []*alerting.AlertManager, indexer *indexers.Indexer, timeout time.Duration, metadata map[string]interface{}) (int, error) {
+func Run(ctx context.Context, configSpec config.Spec, prometheusClients []*prometheus.Prometheus, alertMs []*alerting.AlertManager, indexer *indexers.Indexer, timeout time.Duration, metadata map[string]interface{}) (Result, error) {
 	var err error
 	var rc int
+	var resultLock sync.Mutex
+	var jobResults []JobResult
 	var prometheusJobList []prometheus.Job
+	// summaryJobList holds the jobs whose summary is indexed, along with the markers of their objects
+	var summaryJobList []prometheus.Job
+	jobMarkers := make(map[string]*objectMarkers)
 	var jobList []Executor
+	var leaks *leakChecker
 	embedFS = configSpec.EmbedFS
+	templateValues, templateEnv = configSpec.GlobalConfig.Values, configSpec.GlobalConfig.Env
+	templateEngine = util.TemplateEngine(configSpec.GlobalConfig.TemplateEngine)
+	injectedLabels, injectedAnnotations = configSpec.GlobalConfig.InjectLabels, configSpec.GlobalConfig.InjectAnnotations
+	progressInterval = configSpec.ProgressInterval
+	DeletionConcurrency = configSpec.GlobalConfig.DeletionConcurrency
 	embedFSDir = configSpec.EmbedFSDir
+	// Repositories cloned to read object templates are removed once the run finishes
+	defer util.CleanupGitCheckouts()
 	errs := []error{}
 	res := make(chan int, 1)
 	uuid := configSpec.GlobalConfig.UUID
 	globalConfig := configSpec.GlobalConfig
 	globalWaitMap := make(map[string][]string)
 	executorMap := make(map[string]Executor)
+	util.SetLogContext(util.LogFieldUUID, uuid)
+	defer util.ClearLogContext(util.LogFieldUUID)
 	log.Infof("🔥 Starting kube-burner (%s@%s) with UUID %s", version.Version, version.GitCommit, uuid)
+	if configSpec.DryRun != nil {
+		rc, err = dryRun(configSpec, timeout)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return Result{UUID: uuid, ReturnCode: rc, Errors: errs}, err
+	}
+	if maxObjects := globalConfig.MaxObjects; maxObjects > 0 && configSpec.TotalObjects > maxObjects {
+		if !configSpec.Force {
+			err = fmt.Errorf("the configuration creates %d objects, more than maxObjects %d, use --force to run it anyway", configSpec.TotalObjects, maxObjects)
+			return Result{UUID: uuid, ReturnCode: 1, Errors: []error{err}}, err
+		}
+		log.Warnf("The configuration creates %d objects, more than maxObjects %d, running it anyway as --force was given", configSpec.TotalObjects, maxObjects)
+	}
+	// Tracing state from a previous in-process run must not leak into this one
+	chromeTracer = nil
+	resetDiscoveryCache()
+	stopRun := startRun(ctx)
+	defer stopRun()
+	hookCtx := RunContext{Context: runCtx, UUID: uuid, Metadata: metadata}
+	gc := &gcDecision{globalConfig: globalConfig, uuid: uuid}
 	go func() {
 		var innerRC int
-		measurements.NewMeasurementFactory(configSpec, indexer, metadata)
-		jobList = newExecutorList(configSpec, uuid, timeout)
+		if err := measurements.NewMeasurementFactory(configSpec, indexer, metadata); err != nil {
+			errs = append(errs, err)
+			res <- 1
+			return
+		}
+		if globalConfig.IndexerConfig.Type != "" {
+			indexResolvedConfig(indexer, configSpec, metadata)
+		}
+		jobList, err = newExecutorList(configSpec, uuid, timeout)
+		if err != nil {
+			errs = append(errs, err)
+			res <- 1
+			return
+		}
+		if leaks, err = newLeakChecker(globalConfig.LeakCheck); err != nil {
+			errs = append(errs, err)
+			res <- 1
+			return
+		}
+		budget := newTimeoutBudget(jobList, timeout)
+		cancelJob := func() {}
+		if globalConfig.Trace.File != "" {
+			chromeTracer = newTracer(globalConfig.Trace)
+		}
+		var alertIndexer *indexers.Indexer
+		if globalConfig.IndexerConfig.Type != "" {
+			alertIndexer = indexer
+		}
+		liveAlerts := newLiveAlerting(alertMs, globalConfig.LiveAlertInterval, alertIndexer, uuid, metadata)
+		liveAlerts.start()
+		defer liveAlerts.stop()
 		// Iterate job list
 		for jobPosition, job := range jobList {
 			var waitListNamespaces []string
+			util.SetLogContext(util.LogFieldJob, job.Name)
+			cancelJob()
+			jobCtx = runCtx
+			liveAlerts.setJob(job.Name)
+			if interrupted() {
+				log.Warnf("Run interrupted, skipping job %s", job.Name)
+				continue
+			}
+			if configSpec.Retry != nil && len(configSpec.Retry.Iterations[job.Name]) == 0 {
+				log.Infof("Job %s has no failed iterations, skipping it", job.Name)
+				continue
+			}
 			if job.QPS == 0 || job.Burst == 0 {
 				log.Infof("QPS or Burst rates not set, using default client-go values: %v %v", rest.DefaultQPS, rest.DefaultBurst)
 				job.QPS = rest.DefaultQPS
@@ -107,20 +253,52 @@ func Run(configSpec config.Spec, prometheusClients []*prometheus.Prometheus, ale
 				log.Infof("QPS: %v", job.QPS)
 				log.Infof("Burst: %v", job.Burst)
 			}
-			ClientSet, restConfig, err = config.GetClientSet(job.QPS, job.Burst)
-			if err != nil {
-				log.Fatalf("Error creating clientSet: %s", err)
+			// The creation rate governs the job, so the client must not throttle it
+			if maxRate := job.maxRate(); maxRate > float64(job.QPS) {
+				job.QPS = float32(math.Ceil(maxRate))
+				job.Burst = int(math.Max(float64(job.Burst), float64(job.QPS)))
+				log.Infof("Raising client QPS and Burst to %v and %v to honor the creation rate of %v objects/s", job.QPS, job.Burst, maxRate)
 			}
-			discoveryClient = discovery.NewDiscoveryClientForConfigOrDie(restConfig)
+			var restConfigs []*rest.Config
+			ClientSet, restConfigs, err = config.GetClientPool(job.QPS, job.Burst, globalConfig.ClientPoolSize)
 			if err != nil {
-				log.Fatalf("Error creating clientSet: %s", err)
+				errs = append(errs, fmt.Errorf("error creating clientSet: %s", err))
+				res <- 1
+				return
+			}
+			restConfig = restConfigs[0]
+			if discoveryClient, err = discovery.NewDiscoveryClientForConfig(restConfig); err != nil {
+				errs = append(errs, fmt.Errorf("error creating discovery client: %s", err))
+				res <- 1
+				return
 			}
-			DynamicClient = dynamic.NewForConfigOrDie(restConfig)
+			clientPool := newDynamicClientPool(restConfigs)
+			DynamicClient = clientPool
 			if job.PreLoadImages && job.JobType == config.CreationJob {
 				if err = preLoadImages(job); err != nil {
-					log.Fatal(err.Error())
+					errs = append(errs, err)
+					res <- 1
+					return
 				}
 			}
+			if job.PreJobCheck != nil {
+				if err := job.runPreJobCheck(prometheusClients); err != nil {
+					log.Error(err.Error())
+					errs = append(errs, fmt.Errorf("job %s: %s", job.Name, err))
+					innerRC = 1
+					continue
+				}
+			}
+			if err := callHooks("preJob", func(h Hooks) error { return h.PreJob(hookCtx, job.Job) }); err != nil {
+				errs = append(errs, fmt.Errorf("job %s: %s", job.Name, err))
+				innerRC = 1
+				continue
+			}
+			if budget != nil {
+				job.MaxWaitTimeout = budget.allocate(jobPosition)
+				log.Infof("Job %s timeout budget: %v", job.Name, job.MaxWaitTimeout.Round(time.Second))
+				jobCtx, cancelJob = context.WithTimeout(runCtx, job.MaxWaitTimeout)
+			}
 			prometheusJob := prometheus.Job{
 				Start:     time.Now().UTC(),
 				JobConfig: job.Job,
@@ -128,14 +306,27 @@ func Run(configSpec config.Spec, prometheusClients []*prometheus.Prometheus, ale
 			measurements.SetJobConfig(&job.Job)
 			log.Infof("Triggering job: %s", job.Name)
 			measurements.Start()
+			var podLogs *podLogSampler
 			switch job.JobType {
 			case config.CreationJob:
-				if job.Cleanup {
+				// Objects from the previous run must be kept when retrying
+				if job.Cleanup && configSpec.Retry == nil {
 					ctx, cancel := context.WithTimeout(context.Background(), globalConfig.GCTimeout)
 					defer cancel()
-					CleanupNamespaces(ctx, metav1.ListOptions{LabelSelector: fmt.Sprintf("kube-burner-job=%s", job.Name)}, true)
-					CleanupNonNamespacedResourcesUsingGVR(ctx, jobList, true)
+					listOptions := metav1.ListOptions{LabelSelector: fmt.Sprintf("kube-burner-job=%s", job.Name)}
+					err := utilerrors.NewAggregate([]error{
+						CleanupNamespaces(ctx, listOptions, true),
+						cleanupReusedNamespaces(ctx, []Executor{job}, listOptions),
+						CleanupNonNamespacedResourcesUsingGVR(ctx, jobList, true),
+					})
+					if err != nil {
+						errs = append(errs, fmt.Errorf("job %s: %s", job.Name, err))
+						cancelJob()
+						res <- 1
+						return
+					}
 				}
+				podLogs = job.startPodLogs(globalConfig.IndexerConfig.MetricsDirectory)
 				if job.Churn {
 					log.Info("Churning enabled")
 					log.Infof("Churn duration: %v", job.ChurnDuration)
@@ -143,9 +334,20 @@ func Run(configSpec config.Spec, prometheusClients []*prometheus.Prometheus, ale
 					log.Infof("Churn delay: %v", job.ChurnDelay)
 					log.Infof("Churn deletion strategy: %v", job.ChurnDeletionStrategy)
 				}
-				job.RunCreateJob(0, job.JobIterations, &waitListNamespaces)
+				if configSpec.Retry != nil {
+					job.RunFailedIterations(configSpec.Retry.Iterations[job.Name], &waitListNamespaces)
+				} else if job.ConcurrencySweep != nil {
+					levels := job.RunConcurrencySweep(&waitListNamespaces)
+					if globalConfig.IndexerConfig.Type != "" {
+						indexConcurrencySweep(indexer, levels, metadata)
+					}
+				} else {
+					job.RunCreateJob(0, job.JobIterations, &waitListNamespaces)
+				}
 				// If object verification is enabled
-				if job.VerifyObjects && !job.Verify() {
+				// Objects created by a retry with a different UUID can't be verified along with the ones of the previous run
+				verifyObjects := job.VerifyObjects && (configSpec.Retry == nil || configSpec.Retry.UUID == uuid)
+				if verifyObjects && !job.Verify() {
 					err := errors.New("object verification failed")
 					// If errorOnVerify is enabled. Set RC to 1 and append error
 					if job.ErrorOnVerify {
@@ -154,15 +356,45 @@ func Run(configSpec config.Spec, prometheusClients []*prometheus.Prometheus, ale
 					}
 					log.Error(err.Error())
 				}
-				if job.Churn {
+				job.logObjectSelectionDistribution()
+				job.logCreationRate()
+				if job.Churn && configSpec.Retry == nil {
 					job.RunCreateJobWithChurn()
 				}
+				if globalConfig.IndexerConfig.Type != "" {
+					job.indexNamespaceLabelDistribution(indexer, metadata)
+					job.indexObjectSelectionDistribution(indexer, metadata)
+					job.indexFailureEvents(indexer, metadata)
+					job.indexChurnTeardown(indexer, metadata)
+					job.indexChurnSummary(indexer, metadata)
+					job.indexRequestRetries(indexer, metadata)
+					job.indexCreationRate(indexer, metadata)
+				}
 				globalWaitMap[strconv.Itoa(jobPosition)+job.Name] = waitListNamespaces
 				executorMap[strconv.Itoa(jobPosition)+job.Name] = job
 			case config.DeletionJob:
+				if err := callHooks("preDelete", func(h Hooks) error { return h.PreDelete(hookCtx, job.Job) }); err != nil {
+					errs = append(errs, fmt.Errorf("job %s: %s", job.Name, err))
+					innerRC = 1
+				}
 				job.RunDeleteJob()
 			case config.PatchJob:
 				job.RunPatchJob()
+				if globalConfig.IndexerConfig.Type != "" {
+					job.indexRequestRetries(indexer, metadata)
+				}
+			case config.BaselineJob:
+				log.Infof("Collecting baseline for %v", job.BaselineDuration)
+				select {
+				case <-time.After(job.BaselineDuration):
+				case <-jobCtx.Done():
+					log.Warnf("Job %s: baseline collection stopped before %v", job.Name, job.BaselineDuration)
+				}
+			case config.PauseJob:
+				interval := job.RunPauseJob()
+				if globalConfig.IndexerConfig.Type != "" {
+					job.indexPauseInterval(indexer, interval, metadata)
+				}
 			}
 			if job.BeforeCleanup != "" {
 				log.Infof("Waiting for beforeCleanup command %s to finish", job.BeforeCleanup)
@@ -180,23 +412,52 @@ func Run(configSpec config.Spec, prometheusClients []*prometheus.Prometheus, ale
 				log.Infof("Pausing for %v before finishing job", job.JobPause)
 				time.Sleep(job.JobPause)
 			}
+			podLogs.stop()
+			if jobTimedOut() {
+				err := fmt.Errorf("job %s: timeout budget of %v exhausted", job.Name, job.MaxWaitTimeout.Round(time.Second))
+				log.Error(err.Error())
+				errs = append(errs, err)
+				innerRC = 1
+			}
 
 			prometheusJob.End = time.Now().UTC()
+			job.timer.start, job.timer.end = prometheusJob.Start, prometheusJob.End
+			jobResult := job.jobResult()
+			resultLock.Lock()
+			jobResults = append(jobResults, jobResult)
+			resultLock.Unlock()
+			if err := callHooks("postJob", func(h Hooks) error { return h.PostJob(hookCtx, job.Job, jobResult) }); err != nil {
+				errs = append(errs, fmt.Errorf("job %s: %s", job.Name, err))
+				innerRC = 1
+			}
+			if globalConfig.ClientPoolSize > 1 {
+				clientPool.report()
+			}
 			// Don't append to Prometheus jobList when prometheus it's not initialized
 			if len(prometheusClients) > 0 {
 				prometheusJobList = append(prometheusJobList, prometheusJob)
 			}
+			// Job summaries are indexed even without Prometheus
+			summaryJobList = append(summaryJobList, prometheusJob)
+			jobMarkers[job.Name] = job.objectMarkers
 			// We stop and index measurements per job
 			if !globalConfig.WaitWhenFinished {
 				elapsedTime := prometheusJob.End.Sub(prometheusJob.Start).Round(time.Second)
 				log.Infof("Job %s took %v", job.Name, elapsedTime)
-				if err = measurements.Stop(); err != nil {
+				indexingStart := time.Now()
+				err = measurements.Stop()
+				job.timer.since(phaseIndexing, indexingStart)
+				if err != nil {
 					errs = append(errs, err)
 					log.Error(err.Error())
 					innerRC = 1
 				}
 			}
 		}
+		cancelJob()
+		jobCtx = runCtx
+		liveAlerts.stop()
+		util.ClearLogContext(util.LogFieldJob)
 		if globalConfig.WaitWhenFinished {
 			runWaitList(globalWaitMap, executorMap)
 			if err = measurements.Stop(); err != nil {
@@ -205,31 +466,73 @@ func Run(configSpec config.Spec, prometheusClients []*prometheus.Prometheus, ale
 				innerRC = 1
 			}
 		}
+		chromeTracer.write()
+		writeFailedIterations(uuid, jobList)
+		// Alerts are evaluated before garbage collection, as the alerts fired decide whether the objects are kept
+		evaluateAlerts := func(jobs []prometheus.Job) {
+			for _, alertM := range alertMs {
+				// The alerts of an aborted run were already evaluated live
+				if alertM == nil || aborted() != "" {
+					continue
+				}
+				results, err := alertM.EvaluateJobs(jobs)
+				if err != nil {
+					errs = append(errs, err)
+					innerRC = 1
+				}
+				// Critical alerts abort the run as the live ones do, garbage collection and indexing are still handled
+				for _, result := range results {
+					if result.Fired && result.Critical() {
+						abortRun(fmt.Sprintf("critical alert fired at %s in job %s: '%s'", result.Timestamp.Format(time.RFC3339), result.JobName, result.Description))
+						break
+					}
+				}
+			}
+		}
+		evaluateAlerts(prometheusJobList)
+		gcRC := innerRC
+		if interrupted() {
+			gcRC = rcInterrupted
+		}
 		// We initialize garbage collection as soon as the benchmark finishes
-		if globalConfig.GC {
+		if globalConfig.GC && !gc.preserve(gcRC, errs) {
+			if hookErrs := runPreDeleteHooks(hookCtx, jobList); len(hookErrs) > 0 {
+				errs = append(errs, hookErrs...)
+				innerRC = 1
+			}
 			// If gcMetrics is enabled, garbage collection must be blocker
 			if globalConfig.GCMetrics {
 				cleanupStart := time.Now().UTC()
 				ctx, cancel := context.WithTimeout(context.Background(), globalConfig.GCTimeout)
 				defer cancel()
-				CleanupNamespaces(ctx, metav1.ListOptions{LabelSelector: fmt.Sprintf("kube-burner-uuid=%v", uuid)}, true)
-				CleanupNonNamespacedResourcesUsingGVR(ctx, jobList, true)
+				if err := cleanupRun(ctx, jobList, uuid); err != nil {
+					errs = append(errs, err)
+					innerRC = 1
+				}
+				if err := verifyCleanup(globalConfig.CleanupVerifications); err != nil {
+					errs = append(errs, err)
+					innerRC = 1
+				}
 				// We add an extra dummy job to prometheusJobList to index metrics from this stage
 				cleanupEnd := time.Now().UTC()
-				prometheusJobList = append(prometheusJobList, prometheus.Job{
+				gcJob := prometheus.Job{
 					Start: cleanupStart,
 					End:   cleanupEnd,
 					JobConfig: config.Job{
 						Name: garbageCollectionJob,
 					},
-				})
+				}
+				prometheusJobList = append(prometheusJobList, gcJob)
+				summaryJobList = append(summaryJobList, gcJob)
+				evaluateAlerts([]prometheus.Job{gcJob})
 			} else {
 				go CleanupNonNamespacedResourcesUsingGVR(context.TODO(), jobList, true)
 				go CleanupNamespaces(context.TODO(), metav1.ListOptions{LabelSelector: fmt.Sprintf("kube-burner-uuid=%v", uuid)}, false)
+				go cleanupReusedNamespaces(context.TODO(), jobList, metav1.ListOptions{LabelSelector: fmt.Sprintf("kube-burner-uuid=%v", uuid)})
 			}
 		}
 		if globalConfig.IndexerConfig.Type != "" {
-			for _, job := range prometheusJobList {
+			for _, job := range summaryJobList {
 				// elapsedTime is recalculated for every job of the list
 				elapsedTime := job.End.Sub(job.Start).Round(time.Second).Seconds()
 				jobTimings := timings{
@@ -240,30 +543,27 @@ func Run(configSpec config.Spec, prometheusClients []*prometheus.Prometheus, ale
 				if job.JobConfig.SkipIndexing {
 					log.Infof("Skipping job summary indexing in job: %s", job.JobConfig.Name)
 				} else {
-					indexjobSummaryInfo(indexer, uuid, jobTimings, job.JobConfig, metadata)
+					indexjobSummaryInfo(indexer, uuid, jobTimings, jobMarkers[job.JobConfig.Name].summary(job.Start), job.JobConfig, metadata)
 				}
 			}
+			indexSelfTiming(indexer, jobList, metadata)
 		}
 		docsToIndex := make(map[string][]interface{})
-		for idx, prometheusClient := range prometheusClients {
-			// If alertManager is configured
-			if alertMs[idx] != nil {
-				if err := alertMs[idx].Evaluate(prometheusJobList[0].Start, prometheusJobList[len(jobList)-1].End); err != nil {
-					errs = append(errs, err)
-					innerRC = 1
-				}
-			}
+		for _, prometheusClient := range prometheusClients {
 			prometheusClient.JobList = prometheusJobList
 			// If prometheus is enabled query metrics from the start of the first job to the end of the last one
 			if globalConfig.IndexerConfig.Type != "" {
-				prometheusClient.ScrapeJobsMetrics(docsToIndex)
-				if globalConfig.IndexerConfig.Type == indexers.LocalIndexer && globalConfig.IndexerConfig.CreateTarball {
-					metrics.CreateTarball(globalConfig.IndexerConfig, globalConfig.IndexerConfig.TarballName)
+				prometheusClient.ScrapeJobsMetrics(runCtx, docsToIndex)
+				for _, indexerConfig := range globalConfig.IndexerConfigs() {
+					if indexerConfig.Type == indexers.LocalIndexer && indexerConfig.CreateTarball {
+						metrics.CreateTarball(indexerConfig, indexerConfig.TarballName)
+					}
 				}
 			}
 		}
 		log.Infof("Indexing metrics with UUID %s", uuid)
 		metrics.IndexDatapoints(docsToIndex, globalConfig.IndexerConfig.Type, indexer)
+		metrics.LogIndexingSummary(indexer)
 		log.Infof("Finished execution with UUID: %s", uuid)
 		res <- innerRC
 	}()
@@ -275,52 +575,178 @@ func Run(configSpec config.Spec, prometheusClients []*prometheus.Prometheus, ale
 		errs = append(errs, err)
 		rc = rcTimeout
 	}
+	if interrupted() {
+		if reason := aborted(); reason != "" {
+			errs = append(errs, fmt.Errorf("run aborted: %s", reason))
+			rc = rcAborted
+		} else {
+			errs = append(errs, errors.New("run interrupted"))
+			rc = rcInterrupted
+		}
+		// Garbage collection already removes the objects of the run
+		if !globalConfig.GC && cleanupOnInterrupt(jobList) {
+			ctx, cancel := context.WithTimeout(context.Background(), globalConfig.GCTimeout)
+			defer cancel()
+			log.Info("Cleaning up the objects created by the interrupted run")
+			if err := cleanupRun(ctx, jobList, uuid); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	// The decision was already made once the jobs finished, unless the run timed out before
+	preserved := globalConfig.GC && gc.preserve(rc, errs)
 	// When GC is enabled and GCMetrics is disabled, we assume previous GC operation run in background, so we have to ensure there's no garbage left
-	if globalConfig.GC && !globalConfig.GCMetrics {
+	if globalConfig.GC && !globalConfig.GCMetrics && !preserved {
 		// Use timeout/4 to garbage collect namespaces
 		ctx, cancel := context.WithTimeout(context.Background(), globalConfig.GCTimeout)
 		defer cancel()
 		log.Info("Garbage collecting remaining namespaces")
-		CleanupNamespaces(ctx, metav1.ListOptions{LabelSelector: fmt.Sprintf("kube-burner-uuid=%v", uuid)}, true)
-		CleanupNonNamespacedResourcesUsingGVR(ctx, jobList, true)
+		if err := cleanupRun(ctx, jobList, uuid); err != nil {
+			errs = append(errs, err)
+			if rc == 0 {
+				rc = 1
+			}
+		}
+		if err := verifyCleanup(globalConfig.CleanupVerifications); err != nil {
+			errs = append(errs, err)
+			if rc == 0 {
+				rc = 1
+			}
+		}
+	}
+	// The objects kept for inspection would be reported as leaks
+	if !preserved {
+		if err := leaks.check(); err != nil {
+			log.Error(err.Error())
+			errs = append(errs, err)
+			if rc == 0 {
+				rc = rcLeak
+			}
+		}
 	}
-	return rc, utilerrors.NewAggregate(errs)
+	resultLock.Lock()
+	defer resultLock.Unlock()
+	result := Result{
+		UUID:       uuid,
+		ReturnCode: rc,
+		Jobs:       jobResults,
+		Errors:     errs,
+	}
+	if err := callHooks("postRun", func(h Hooks) error { return h.PostRun(hookCtx, result) }); err != nil {
+		result.Errors = append(result.Errors, err)
+		if result.ReturnCode == 0 {
+			result.ReturnCode = 1
+		}
+	}
+	return result, utilerrors.NewAggregate(result.Errors)
+}
+
+// cleanupRun deletes the namespaces and objects created by the jobs of the run with the given UUID
+func cleanupRun(ctx context.Context, jobList []Executor, uuid string) error {
+	listOptions := metav1.ListOptions{LabelSelector: fmt.Sprintf("kube-burner-uuid=%v", uuid)}
+	return utilerrors.NewAggregate([]error{
+		CleanupNamespaces(ctx, listOptions, true),
+		cleanupReusedNamespaces(ctx, jobList, listOptions),
+		CleanupNonNamespacedResourcesUsingGVR(ctx, jobList, true),
+	})
+}
+
+// cleanupOnInterrupt returns true when any of the creation jobs has cleanup enabled
+func cleanupOnInterrupt(jobList []Executor) bool {
+	for _, job := range jobList {
+		if job.JobType == config.CreationJob && job.Cleanup {
+			return true
+		}
+	}
+	return false
 }
 
 // newExecutorList Returns a list of executors
-func newExecutorList(configSpec config.Spec, uuid string, timeout time.Duration) []Executor {
+func newExecutorList(configSpec config.Spec, uuid string, timeout time.Duration) ([]Executor, error) {
 	var ex Executor
 	var executorList []Executor
-	_, restConfig, err := config.GetClientSet(100, 100) // Hardcoded QPS/Burst
-	if err != nil {
-		log.Fatalf("Error creating clientSet: %s", err)
+	var clientSet *kubernetes.Clientset
+	// Dry runs don't reach the API server, the objects are resolved with static mappings instead of discovery
+	dryRun := configSpec.DryRun != nil
+	if dryRun {
+		useStaticDiscovery(staticRESTMapper())
+	} else {
+		var restConfig *rest.Config
+		var err error
+		clientSet, restConfig, err = config.GetClientSet(100, 100) // Hardcoded QPS/Burst
+		if err != nil {
+			return nil, fmt.Errorf("error creating clientSet: %s", err)
+		}
+		if discoveryClient, err = discovery.NewDiscoveryClientForConfig(restConfig); err != nil {
+			return nil, fmt.Errorf("error creating discovery client: %s", err)
+		}
 	}
-	discoveryClient = discovery.NewDiscoveryClientForConfigOrDie(restConfig)
 	for _, job := range configSpec.Jobs {
+		if dryRun {
+			if len(job.RequiresAPI) > 0 || objectsRequireAPIs(job.Objects) {
+				log.Infof("Job %s: dry run, assuming the required APIs are available", job.Name)
+			}
+		} else {
+			run, err := checkRequiredAPIs(&job)
+			if err != nil {
+				return nil, err
+			}
+			if !run {
+				continue
+			}
+		}
+		var err error
 		switch job.JobType {
 		case config.CreationJob:
-			ex = setupCreateJob(job)
+			ex, err = setupCreateJob(job)
+			if err == nil && len(job.ReuseNamespaces) > 0 {
+				if dryRun {
+					// Patterns can't be resolved without listing the namespaces, they're taken as names
+					ex.reusedNamespaces = job.ReuseNamespaces
+				} else if ex.reusedNamespaces, err = resolveReusedNamespaces(clientSet, job.ReuseNamespaces); err != nil {
+					return nil, fmt.Errorf("job %s: %s", job.Name, err)
+				}
+				log.Infof("Job %s: reusing namespaces %s", job.Name, strings.Join(ex.reusedNamespaces, ", "))
+			}
 		case config.DeletionJob:
-			ex = setupDeleteJob(job)
+			ex, err = setupDeleteJob(job)
 		case config.PatchJob:
-			ex = setupPatchJob(job)
+			ex, err = setupPatchJob(job)
+		case config.BaselineJob, config.PauseJob:
+			ex = Executor{}
 		default:
-			log.Fatalf("Unknown jobType: %s", job.JobType)
+			return nil, fmt.Errorf("unknown jobType: %s", job.JobType)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %s", job.Name, err)
 		}
 		for _, j := range executorList {
 			if job.Name == j.Job.Name {
-				log.Fatalf("Job names must be unique: %s", job.Name)
+				return nil, fmt.Errorf("job names must be unique: %s", job.Name)
 			}
 		}
 		job.MaxWaitTimeout = timeout
-		// Limits the number of workers to QPS and Burst
-		ex.limiter = rate.NewLimiter(rate.Limit(job.QPS), job.Burst)
+		// Limits the number of workers to QPS and Burst, or to the object creation rate when set
+		if job.Rate > 0 {
+			ex.limiter = rate.NewLimiter(rate.Limit(job.Rate), 1)
+			ex.creationRate = &creationRateTracker{}
+		} else if len(job.RatePlan) > 0 {
+			ex.limiter = rate.NewLimiter(rate.Inf, 1)
+			ex.creationRate = &creationRateTracker{}
+			ex.ratePlan = newRatePlan(job.RatePlan, ex.limiter)
+		} else {
+			ex.limiter = rate.NewLimiter(rate.Limit(job.QPS), job.Burst)
+		}
 		ex.Job = job
+		ex.timer = &phaseTimer{}
+		ex.failureEvents = newFailureEventRecorder(job.FailureEvents)
+		ex.retries = &requestRetries{}
+		ex.created = &atomic.Int64{}
 		ex.uuid = uuid
 		ex.runid = configSpec.GlobalConfig.RUNID
 		executorList = append(executorList, ex)
 	}
-	return executorList
+	return executorList, nil
 }
 
 // Runs on wait list at the end of benchmark
@@ -343,3 +769,34 @@ func runWaitList(globalWaitMap map[string][]string, executorMap map[string]Execu
 		wg.Wait()
 	}
 }
+
+// gcDecision decides once whether the objects of the run are garbage collected, so the garbage collection started
+// when the jobs finish and the final one agree
+type gcDecision struct {
+	sync.Mutex
+	globalConfig config.GlobalConfig
+	uuid         string
+	decided      bool
+	preserved    bool
+}
+
+// preserve returns true when the run failed and its objects must be kept for inspection. Only the first call decides,
+// the next ones return the same decision
+func (d *gcDecision) preserve(rc int, errs []error) bool {
+	d.Lock()
+	defer d.Unlock()
+	if d.decided {
+		return d.preserved
+	}
+	d.decided = true
+	d.preserved = preserveOnFailure(d.globalConfig, rc, errs)
+	if d.preserved {
+		log.Warnf("Run failed, skipping garbage collection so the created objects can be inspected. They can be removed with: kube-burner destroy --uuid %s", d.uuid)
+	}
+	return d.preserved
+}
+
+// preserveOnFailure returns true when the run failed and its objects must be kept for inspection instead of garbage collected
+func preserveOnFailure(globalConfig config.GlobalConfig, rc int, errs []error) bool {
+	return !globalConfig.CleanupOnFailure && (rc != 0 || len(errs) > 0)
+}
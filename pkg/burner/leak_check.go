@@ -0,0 +1,173 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/metadata"
+)
+
+// objectCountWorkers number of resources counted in parallel
+const objectCountWorkers = 10
+
+// objectCounts holds the number of objects of each kind, indexed by group/version/kind
+type objectCounts map[string]int
+
+// leakChecker compares the cluster object counts after garbage collection against a baseline taken before the run
+type leakChecker struct {
+	config   config.LeakCheck
+	client   metadata.Interface
+	baseline objectCounts
+}
+
+// newLeakChecker takes the baseline of the cluster object counts, it returns nil when the leak check is disabled
+func newLeakChecker(leakCheck config.LeakCheck) (*leakChecker, error) {
+	if !leakCheck.Enabled {
+		return nil, nil
+	}
+	_, restConfig, err := config.GetClientSet(100, 100)
+	if err != nil {
+		return nil, fmt.Errorf("error creating clientSet: %s", err)
+	}
+	l := &leakChecker{config: leakCheck}
+	if l.client, err = metadata.NewForConfig(restConfig); err != nil {
+		return nil, fmt.Errorf("error creating metadata client: %s", err)
+	}
+	log.Info("Taking the baseline of the cluster object counts")
+	if l.baseline, err = l.countObjects(); err != nil {
+		return nil, fmt.Errorf("error taking the object count baseline: %s", err)
+	}
+	return l, nil
+}
+
+// check counts the cluster objects again, returning an error listing the kinds whose count exceeds the baseline plus the tolerance
+func (l *leakChecker) check() error {
+	if l == nil {
+		return nil
+	}
+	log.Info("Checking the cluster object counts against the baseline")
+	counts, err := l.countObjects()
+	if err != nil {
+		return fmt.Errorf("error counting cluster objects: %s", err)
+	}
+	var leaks []string
+	for kind, count := range counts {
+		if residual := count - l.baseline[kind]; residual > l.config.Tolerance {
+			log.Errorf("%s: %d objects, baseline %d, %d residual objects exceed the tolerance of %d", kind, count, l.baseline[kind], residual, l.config.Tolerance)
+			leaks = append(leaks, kind)
+		}
+	}
+	if len(leaks) > 0 {
+		sort.Strings(leaks)
+		return fmt.Errorf("object counts didn't return to the baseline: %s", strings.Join(leaks, ", "))
+	}
+	log.Info("Cluster object counts returned to the baseline")
+	return nil
+}
+
+// countObjects counts the objects of all the listable resources, excluding the configured ones
+func (l *leakChecker) countObjects() (objectCounts, error) {
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	var errs []string
+	counts := make(objectCounts)
+	excluded := make(map[string]bool)
+	for _, resource := range l.config.Exclude {
+		excluded[resource] = true
+	}
+	resourceLists, err := discoveryClient.ServerPreferredResources()
+	if err != nil {
+		// Partial results are returned when discovery fails for some groups
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return counts, err
+		}
+		logFailedDiscoveryGroups(err.(*discovery.ErrGroupDiscoveryFailed).Groups)
+	}
+	listable := discovery.SupportsAllVerbs{Verbs: []string{"list"}}
+	resources := make(chan schema.GroupVersionResource)
+	kinds := make(map[schema.GroupVersionResource]string)
+	for i := 0; i < objectCountWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for gvr := range resources {
+				count, err := l.countResource(gvr)
+				lock.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("%s: %s", gvr, err))
+				} else {
+					counts[kinds[gvr]] = count
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			groupResource := schema.GroupResource{Group: gv.Group, Resource: resource.Name}.String()
+			if excluded[groupResource] || strings.Contains(resource.Name, "/") || !listable.Match(gv.String(), &resource) {
+				continue
+			}
+			gvr := gv.WithResource(resource.Name)
+			lock.Lock()
+			kinds[gvr] = gvkString(gv.WithKind(resource.Kind))
+			lock.Unlock()
+			resources <- gvr
+		}
+	}
+	close(resources)
+	wg.Wait()
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return counts, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return counts, nil
+}
+
+// gvkString returns the kind in group/version/kind format, or version/kind for the core group
+func gvkString(gvk schema.GroupVersionKind) string {
+	return gvk.GroupVersion().String() + "/" + gvk.Kind
+}
+
+// countResource counts the objects of the given resource, listing only their metadata
+func (l *leakChecker) countResource(gvr schema.GroupVersionResource) (int, error) {
+	var count int
+	listOptions := metav1.ListOptions{Limit: objectLimit}
+	for {
+		objList, err := l.client.Resource(gvr).List(context.TODO(), listOptions)
+		if err != nil {
+			return count, err
+		}
+		count += len(objList.Items)
+		listOptions.Continue = objList.GetContinue()
+		if listOptions.Continue == "" {
+			return count, nil
+		}
+	}
+}
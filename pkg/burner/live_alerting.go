@@ -0,0 +1,174 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	"github.com/cloud-bulldozer/kube-burner/pkg/alerting"
+	log "github.com/sirupsen/logrus"
+)
+
+const runAbortMetric = "runAborted"
+
+// runAbort document indexed when a critical alert aborts the run
+type runAbort struct {
+	Timestamp  time.Time              `json:"timestamp"`
+	UUID       string                 `json:"uuid"`
+	MetricName string                 `json:"metricName"`
+	JobName    string                 `json:"jobName,omitempty"`
+	Reason     string                 `json:"reason"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// liveAlerting periodically evaluates the alert profiles while the jobs run, aborting the run when a critical alert fires
+type liveAlerting struct {
+	alertMs  []*alerting.AlertManager
+	interval time.Duration
+	indexer  *indexers.Indexer
+	uuid     string
+	metadata map[string]interface{}
+	lock     sync.Mutex
+	jobName  string
+	jobStart time.Time
+	runStart time.Time
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// newLiveAlerting returns nil when live alerting is disabled or no alert profile is configured
+func newLiveAlerting(alertMs []*alerting.AlertManager, interval time.Duration, indexer *indexers.Indexer, uuid string, metadata map[string]interface{}) *liveAlerting {
+	if interval <= 0 {
+		return nil
+	}
+	var enabled []*alerting.AlertManager
+	for _, alertM := range alertMs {
+		if alertM != nil {
+			enabled = append(enabled, alertM)
+		}
+	}
+	if len(enabled) == 0 {
+		log.Warn("liveAlertInterval is set but no alert profile is configured, live alerting disabled")
+		return nil
+	}
+	return &liveAlerting{
+		alertMs:  enabled,
+		interval: interval,
+		indexer:  indexer,
+		uuid:     uuid,
+		metadata: metadata,
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// start evaluates the alerts every interval, each evaluation covers the time elapsed since the previous one
+func (l *liveAlerting) start() {
+	if l == nil {
+		return
+	}
+	log.Infof("🔔 Evaluating alerts every %v during the run", l.interval)
+	go func() {
+		defer close(l.done)
+		ticker := time.NewTicker(l.interval)
+		defer ticker.Stop()
+		windowStart := time.Now().UTC()
+		l.runStart = windowStart
+		for {
+			select {
+			case <-l.stopCh:
+				return
+			case <-ticker.C:
+				windowEnd := time.Now().UTC()
+				if l.evaluate(windowStart, windowEnd) {
+					return
+				}
+				windowStart = windowEnd
+			}
+		}
+	}()
+}
+
+// evaluate returns true when a critical alert fired and the run was aborted
+func (l *liveAlerting) evaluate(start, end time.Time) bool {
+	jobName, jobStart := l.job()
+	if jobStart.IsZero() {
+		jobStart = l.runStart
+	}
+	for _, alertM := range l.alertMs {
+		results, _ := alertM.EvaluateLive(jobName, l.runStart, jobStart, start, end)
+		for _, result := range results {
+			if !result.Fired || !result.Critical() {
+				continue
+			}
+			reason := fmt.Sprintf("critical alert fired at %s in job %s: '%s'", result.Timestamp.Format(time.RFC3339), jobName, result.Description)
+			abortRun(reason)
+			l.index(jobName, reason)
+			return true
+		}
+	}
+	return false
+}
+
+// setJob sets the job the following evaluations are reported within, the job starts now
+func (l *liveAlerting) setJob(jobName string) {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.jobName = jobName
+	l.jobStart = time.Now().UTC()
+}
+
+func (l *liveAlerting) job() (string, time.Time) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.jobName, l.jobStart
+}
+
+// stop stops the evaluations, waiting for the ongoing one to finish. It can be called several times
+func (l *liveAlerting) stop() {
+	if l == nil {
+		return
+	}
+	l.stopOnce.Do(func() { close(l.stopCh) })
+	<-l.done
+}
+
+func (l *liveAlerting) index(jobName, reason string) {
+	if l.indexer == nil {
+		return
+	}
+	doc := runAbort{
+		Timestamp:  time.Now().UTC(),
+		UUID:       l.uuid,
+		MetricName: runAbortMetric,
+		JobName:    jobName,
+		Reason:     reason,
+		Metadata:   l.metadata,
+	}
+	log.Infof("Indexing metric %s", runAbortMetric)
+	resp, err := (*l.indexer).Index([]interface{}{doc}, indexers.IndexingOpts{MetricName: runAbortMetric})
+	if err != nil {
+		log.Error(err)
+	} else {
+		log.Info(resp)
+	}
+}
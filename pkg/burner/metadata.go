@@ -32,6 +32,7 @@ type timings struct {
 
 type jobSummary struct {
 	timings
+	objectMarkersSummary
 	UUID       string                 `json:"uuid"`
 	MetricName string                 `json:"metricName"`
 	JobConfig  config.Job             `json:"jobConfig"`
@@ -39,18 +40,32 @@ type jobSummary struct {
 	Version    string                 `json:"version"`
 }
 
-const jobSummaryMetric = "jobSummary"
+type resolvedConfig struct {
+	Timestamp  time.Time              `json:"timestamp"`
+	UUID       string                 `json:"uuid"`
+	MetricName string                 `json:"metricName"`
+	ConfigHash string                 `json:"configHash"`
+	Config     map[string]interface{} `json:"config"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	Version    string                 `json:"version"`
+}
+
+const (
+	jobSummaryMetric     = "jobSummary"
+	resolvedConfigMetric = "resolvedConfig"
+)
 
 // indexMetadataInfo Generates and indexes a document with metadata information of the passed job
-func indexjobSummaryInfo(indexer *indexers.Indexer, uuid string, jobTimings timings, jobConfig config.Job, metadata map[string]interface{}) {
+func indexjobSummaryInfo(indexer *indexers.Indexer, uuid string, jobTimings timings, markers objectMarkersSummary, jobConfig config.Job, metadata map[string]interface{}) {
 	metadataInfo := []interface{}{
 		jobSummary{
-			UUID:       uuid,
-			JobConfig:  jobConfig,
-			MetricName: jobSummaryMetric,
-			Metadata:   metadata,
-			Version:    fmt.Sprintf("%v@%v", version.Version, version.GitCommit),
-			timings:    jobTimings,
+			UUID:                 uuid,
+			JobConfig:            jobConfig,
+			MetricName:           jobSummaryMetric,
+			Metadata:             metadata,
+			Version:              fmt.Sprintf("%v@%v", version.Version, version.GitCommit),
+			timings:              jobTimings,
+			objectMarkersSummary: markers,
 		},
 	}
 	log.Infof("Indexing metric %s", jobSummaryMetric)
@@ -65,3 +80,31 @@ func indexjobSummaryInfo(indexer *indexers.Indexer, uuid string, jobTimings timi
 		log.Info(resp)
 	}
 }
+
+// indexResolvedConfig indexes a single document holding the resolved configuration and its fingerprint
+func indexResolvedConfig(indexer *indexers.Indexer, configSpec config.Spec, metadata map[string]interface{}) {
+	cfg, hash, err := config.ResolvedConfig(configSpec)
+	if err != nil {
+		log.Errorf("Error resolving configuration: %s", err)
+		return
+	}
+	log.Infof("Configuration fingerprint: %s", hash)
+	resolvedConfigInfo := []interface{}{
+		resolvedConfig{
+			Timestamp:  time.Now().UTC(),
+			UUID:       configSpec.GlobalConfig.UUID,
+			MetricName: resolvedConfigMetric,
+			ConfigHash: hash,
+			Config:     cfg,
+			Metadata:   metadata,
+			Version:    fmt.Sprintf("%v@%v", version.Version, version.GitCommit),
+		},
+	}
+	log.Infof("Indexing metric %s", resolvedConfigMetric)
+	resp, err := (*indexer).Index(resolvedConfigInfo, indexers.IndexingOpts{MetricName: resolvedConfigMetric})
+	if err != nil {
+		log.Error(err)
+	} else {
+		log.Info(resp)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+)
+
+const (
+	// Same alphabet used by the apiserver for generateName, without vowels nor ambiguous characters
+	nameSuffixAlphabet = "bcdfghjklmnpqrstvwxz2456789"
+	nameSuffixLength   = 5
+)
+
+// nameSuffix returns the random suffix of the given iteration, injected in the object templates as NameSuffix. It only
+// depends on the seed, the job and the iteration, so it's reproducible and shared by all the objects of the iteration
+func nameSuffix(seed int64, jobName string, iteration int) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s/%d", jobName, iteration)
+	r := rand.New(rand.NewSource(seed ^ int64(h.Sum64())))
+	suffix := make([]byte, nameSuffixLength)
+	for i := range suffix {
+		suffix[i] = nameSuffixAlphabet[r.Intn(len(nameSuffixAlphabet))]
+	}
+	return string(suffix)
+}
@@ -0,0 +1,138 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const namespaceLabelDistributionMetric = "namespaceLabelDistribution"
+
+// namespaceLabeler picks a label variant for each created namespace following the configured weights
+type namespaceLabeler struct {
+	variants    []config.NamespaceLabelVariant
+	totalWeight int
+	rand        *rand.Rand
+	counts      []int
+	lock        sync.Mutex
+}
+
+type labelVariantCount struct {
+	Labels     map[string]string `json:"labels"`
+	Weight     int               `json:"weight"`
+	Namespaces int               `json:"namespaces"`
+	Ratio      float64           `json:"ratio"`
+}
+
+type namespaceLabelDistribution struct {
+	Timestamp  time.Time              `json:"timestamp"`
+	UUID       string                 `json:"uuid"`
+	MetricName string                 `json:"metricName"`
+	JobName    string                 `json:"jobName"`
+	Seed       int64                  `json:"seed"`
+	Variants   []labelVariantCount    `json:"variants"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// newNamespaceLabeler returns nil when the job doesn't define namespace label variants
+func newNamespaceLabeler(jobConfig config.Job) *namespaceLabeler {
+	if len(jobConfig.NamespaceLabelVariants) == 0 {
+		return nil
+	}
+	labeler := &namespaceLabeler{
+		variants: jobConfig.NamespaceLabelVariants,
+		rand:     rand.New(rand.NewSource(jobConfig.NamespaceLabelSeed)),
+		counts:   make([]int, len(jobConfig.NamespaceLabelVariants)),
+	}
+	for _, variant := range labeler.variants {
+		labeler.totalWeight += variant.Weight
+	}
+	return labeler
+}
+
+// labels returns the given namespace labels along with the labels of the next variant
+func (n *namespaceLabeler) labels(nsLabels map[string]string) map[string]string {
+	if n == nil {
+		return nsLabels
+	}
+	n.lock.Lock()
+	pick := n.rand.Intn(n.totalWeight)
+	var variant int
+	for i, v := range n.variants {
+		if pick < v.Weight {
+			variant = i
+			break
+		}
+		pick -= v.Weight
+	}
+	n.counts[variant]++
+	n.lock.Unlock()
+	labels := make(map[string]string, len(nsLabels)+len(n.variants[variant].Labels))
+	for k, v := range nsLabels {
+		labels[k] = v
+	}
+	for k, v := range n.variants[variant].Labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// indexNamespaceLabelDistribution indexes the number of namespaces labeled with each variant
+func (ex *Executor) indexNamespaceLabelDistribution(indexer *indexers.Indexer, metadata map[string]interface{}) {
+	var total int
+	if ex.nsLabeler == nil || ex.SkipIndexing {
+		return
+	}
+	distribution := namespaceLabelDistribution{
+		Timestamp:  time.Now().UTC(),
+		UUID:       ex.uuid,
+		MetricName: namespaceLabelDistributionMetric,
+		JobName:    ex.Name,
+		Seed:       ex.NamespaceLabelSeed,
+		Metadata:   metadata,
+	}
+	for _, count := range ex.nsLabeler.counts {
+		total += count
+	}
+	for i, variant := range ex.nsLabeler.variants {
+		variantCount := labelVariantCount{
+			Labels:     variant.Labels,
+			Weight:     variant.Weight,
+			Namespaces: ex.nsLabeler.counts[i],
+		}
+		if total > 0 {
+			variantCount.Ratio = float64(variantCount.Namespaces) / float64(total)
+		}
+		log.Infof("Namespace label variant %v: %d namespaces", variant.Labels, variantCount.Namespaces)
+		distribution.Variants = append(distribution.Variants, variantCount)
+	}
+	log.Infof("Indexing metric %s", namespaceLabelDistributionMetric)
+	indexingOpts := indexers.IndexingOpts{
+		MetricName: fmt.Sprintf("%s-%s", namespaceLabelDistributionMetric, ex.Name),
+	}
+	resp, err := (*indexer).Index([]interface{}{distribution}, indexingOpts)
+	if err != nil {
+		log.Error(err)
+	} else {
+		log.Info(resp)
+	}
+}
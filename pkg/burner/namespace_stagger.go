@@ -0,0 +1,62 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+)
+
+// namespaceStagger spaces out the namespace creations of a job by namespaceCreationDelay plus a random jitter,
+// to avoid admission spikes. It's independent of the object creation rate: the time spent creating the objects
+// of the previous iterations counts towards the delay
+type namespaceStagger struct {
+	delay       time.Duration
+	jitter      time.Duration
+	lastCreated time.Time
+}
+
+// newNamespaceStagger returns nil when the job doesn't stagger its namespace creations
+func newNamespaceStagger(jobConfig config.Job) *namespaceStagger {
+	if jobConfig.NamespaceCreationDelay == 0 && jobConfig.NamespaceCreationJitter == 0 {
+		return nil
+	}
+	return &namespaceStagger{
+		delay:  jobConfig.NamespaceCreationDelay,
+		jitter: jobConfig.NamespaceCreationJitter,
+	}
+}
+
+// wait blocks until the next namespace can be created, it returns right away for the first namespace or when the run is interrupted
+func (s *namespaceStagger) wait() {
+	if s == nil {
+		return
+	}
+	if !s.lastCreated.IsZero() {
+		next := s.lastCreated.Add(s.delay)
+		if s.jitter > 0 {
+			next = next.Add(time.Duration(rand.Int63n(int64(s.jitter))))
+		}
+		if pause := time.Until(next); pause > 0 {
+			select {
+			case <-time.After(pause):
+			case <-runCtx.Done():
+			}
+		}
+	}
+	s.lastCreated = time.Now()
+}
@@ -17,6 +17,11 @@ package burner
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -24,21 +29,70 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 )
 
+// resolveReusedNamespaces returns the sorted list of existing namespaces matching the given names or glob patterns.
+// Names are fetched on their own, namespaces are only listed when a glob pattern is given
+func resolveReusedNamespaces(clientSet kubernetes.Interface, patterns []string) ([]string, error) {
+	var nsList *corev1.NamespaceList
+	matched := make(map[string]bool)
+	for _, pattern := range patterns {
+		if !strings.ContainsAny(pattern, `*?[\`) {
+			ns, err := clientSet.CoreV1().Namespaces().Get(context.TODO(), pattern, metav1.GetOptions{})
+			if errors.IsNotFound(err) || (err == nil && ns.Status.Phase == corev1.NamespaceTerminating) {
+				return nil, fmt.Errorf("namespace %s doesn't exist", pattern)
+			} else if err != nil {
+				return nil, fmt.Errorf("error getting namespace %s: %s", pattern, err)
+			}
+			matched[ns.Name] = true
+			continue
+		}
+		if nsList == nil {
+			var err error
+			if nsList, err = clientSet.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{}); err != nil {
+				return nil, fmt.Errorf("error listing namespaces: %s", err)
+			}
+		}
+		found := false
+		for _, ns := range nsList.Items {
+			// Patterns were already validated when parsing the configuration
+			if ok, _ := filepath.Match(pattern, ns.Name); ok && ns.Status.Phase != corev1.NamespaceTerminating {
+				matched[ns.Name] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no existing namespace matches %q", pattern)
+		}
+	}
+	namespaces := make([]string, 0, len(matched))
+	for ns := range matched {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
 func createNamespace(namespaceName string, nsLabels map[string]string) error {
+	labels := make(map[string]string, len(nsLabels)+len(injectedLabels))
+	for k, v := range injectedLabels {
+		labels[k] = v
+	}
+	for k, v := range nsLabels {
+		labels[k] = v
+	}
 	ns := corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{Name: namespaceName, Labels: nsLabels},
+		ObjectMeta: metav1.ObjectMeta{Name: namespaceName, Labels: labels, Annotations: injectedAnnotations},
 	}
 
 	return RetryWithExponentialBackOff(func() (done bool, err error) {
 		_, err = ClientSet.CoreV1().Namespaces().Create(context.TODO(), &ns, metav1.CreateOptions{})
 		if errors.IsForbidden(err) {
-			log.Fatalf("authorization error creating namespace %s: %s", ns.Name, err)
-			return false, err
+			return false, fmt.Errorf("authorization error creating namespace %s: %s", ns.Name, err)
 		}
 		if errors.IsAlreadyExists(err) {
 			log.Infof("Namespace %s already exists", ns.Name)
@@ -57,33 +111,93 @@ func createNamespace(namespaceName string, nsLabels map[string]string) error {
 	}, 5*time.Second, 3, 0, 5*time.Hour)
 }
 
-// CleanupNamespaces deletes namespaces with the given selector
-func CleanupNamespaces(ctx context.Context, l metav1.ListOptions, cleanupWait bool) {
-	ns, _ := ClientSet.CoreV1().Namespaces().List(ctx, l)
+// CleanupNamespaces deletes namespaces with the given selector, returning an error when they can't be listed or deleted, or aren't deleted in time
+func CleanupNamespaces(ctx context.Context, l metav1.ListOptions, cleanupWait bool) error {
+	ns, err := ClientSet.CoreV1().Namespaces().List(ctx, l)
+	if err != nil {
+		return fmt.Errorf("error listing namespaces labeled with %s: %v", l.LabelSelector, err)
+	}
 	if len(ns.Items) > 0 {
-		log.Infof("Deleting namespaces with label %s", l.LabelSelector)
-		for _, ns := range ns.Items {
-			err := ClientSet.CoreV1().Namespaces().Delete(ctx, ns.Name, metav1.DeleteOptions{})
-			if errors.IsNotFound(err) {
-				log.Debugf("Namespace %s not found", ns.Name)
-				continue
-			}
-			if err != nil {
-				log.Errorf("Error cleaning up namespaces: %v", err)
-			}
+		log.Infof("Deleting %d namespaces with label %s", len(ns.Items), l.LabelSelector)
+		deleted, err := deleteNamespaces(ctx, ns.Items)
+		if ctx.Err() != nil {
+			log.Errorf("Timeout deleting namespaces with label %s: %d deleted, %d remaining", l.LabelSelector, deleted, len(ns.Items)-deleted)
 		}
 		if cleanupWait {
-			waitForDeleteNamespaces(ctx, l)
+			if err := waitForDeleteNamespaces(ctx, l); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("error cleaning up namespaces: %v", err)
 		}
 		log.Infof("Deleting namespaces with label %s completed", l.LabelSelector)
 	}
+	return nil
+}
+
+// deleteNamespaces deletes the given namespaces with up to DeletionConcurrency workers, returning how many were deleted.
+// Workers stop picking namespaces once the context is done
+func deleteNamespaces(ctx context.Context, namespaces []corev1.Namespace) (int, error) {
+	var deleted int64
+	var errs []error
+	var errLock sync.Mutex
+	var wg sync.WaitGroup
+	workers := DeletionConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	queue := make(chan string, len(namespaces))
+	for _, ns := range namespaces {
+		queue <- ns.Name
+	}
+	close(queue)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range queue {
+				if ctx.Err() != nil {
+					return
+				}
+				err := ClientSet.CoreV1().Namespaces().Delete(ctx, name, metav1.DeleteOptions{})
+				if errors.IsNotFound(err) {
+					log.Debugf("Namespace %s not found", name)
+				} else if err != nil {
+					errLock.Lock()
+					errs = append(errs, fmt.Errorf("namespace %s: %v", name, err))
+					errLock.Unlock()
+					continue
+				}
+				atomic.AddInt64(&deleted, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	return int(deleted), utilerrors.NewAggregate(errs)
 }
 
 // Cleanup resources specific to kube-burner with in a given list of namespaces
-func CleanupNamespaceResourcesUsingGVR(ctx context.Context, objects []object, namespacesToDelete []string, jobName string) {
-	for _, namespace := range namespacesToDelete {
+func CleanupNamespaceResourcesUsingGVR(ctx context.Context, objects []object, namespacesToDelete []string, jobName string) error {
+	return cleanupNamespaceResources(ctx, objects, namespacesToDelete, metav1.ListOptions{LabelSelector: fmt.Sprintf("kube-burner-job=%s", jobName)})
+}
+
+// cleanupReusedNamespaces deletes the objects with the given selector from the namespaces reused by the jobs, keeping the namespaces
+func cleanupReusedNamespaces(ctx context.Context, executorList []Executor, l metav1.ListOptions) error {
+	for _, executor := range executorList {
+		if len(executor.reusedNamespaces) > 0 {
+			if err := cleanupNamespaceResources(ctx, executor.objects, executor.reusedNamespaces, l); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cleanupNamespaceResources deletes the namespaced objects of the given kinds with the given selector from a list of namespaces
+func cleanupNamespaceResources(ctx context.Context, objects []object, namespaces []string, l metav1.ListOptions) error {
+	for _, namespace := range namespaces {
 		log.Infof("Deleting resources in namespace %s", namespace)
-		l := metav1.ListOptions{LabelSelector: fmt.Sprintf("kube-burner-job=%s", jobName)}
 		deletedKinds := make(map[string]bool)
 		for _, obj := range objects {
 			if _, exists := deletedKinds[obj.kind]; exists {
@@ -106,41 +220,88 @@ func CleanupNamespaceResourcesUsingGVR(ctx context.Context, objects []object, na
 				deletedKinds[obj.kind] = true
 			}
 		}
-		waitForDeleteNamespacedResources(ctx, namespace, objects, l)
+		if err := waitForDeleteNamespacedResources(ctx, namespace, objects, l); err != nil {
+			return err
+		}
 		log.Infof("Deleting resources in namespace %s completed", namespace)
 	}
+	return nil
 }
 
 // Cleanup non-namespaced resources with the given selector
-func CleanupNonNamespacedResources(ctx context.Context, l metav1.ListOptions, cleanupWait bool) {
-	serverResources, _ := ClientSet.Discovery().ServerPreferredResources()
+func CleanupNonNamespacedResources(ctx context.Context, l metav1.ListOptions, cleanupWait bool) error {
+	resources, err := clusterScopedResources()
+	if err != nil {
+		log.Errorf("Error discovering server resources: %v", err)
+		return nil
+	}
 	log.Infof("Deleting non-namespace resources with label %s", l.LabelSelector)
-	for _, resourceList := range serverResources {
-		for _, resource := range resourceList.APIResources {
-			if !resource.Namespaced {
-				gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
-				if err != nil {
-					log.Errorf("Unable to scan the resource group version: %v", err)
-				}
-				resourceInterface := DynamicClient.Resource(schema.GroupVersionResource{
-					Group:    gv.Group,
-					Version:  gv.Version,
-					Resource: resource.Name,
-				})
-				resources, err := resourceInterface.List(ctx, l)
-				if err != nil {
-					log.Debugf("Unable to list resource: %s error: %v. Hence skipping it", resource.Name, err)
-					continue
+	for _, gvr := range resources {
+		resourceInterface := DynamicClient.Resource(gvr)
+		resourceList, err := resourceInterface.List(ctx, l)
+		if err != nil {
+			log.Debugf("Unable to list resource: %s error: %v. Hence skipping it", gvr.Resource, err)
+			continue
+		}
+		if err := deleteNonNamespacedResources(ctx, resourceList, resourceInterface, l, cleanupWait); err != nil {
+			return err
+		}
+	}
+	log.Infof("Deleting non-namespace resources with label %s completed", l.LabelSelector)
+	return nil
+}
+
+// CleanupNamespacedResources deletes the namespaced objects with the given selector living in namespaces not deleted by
+// CleanupNamespaces, i.e. the objects created in reused namespaces, returning an error when they're not deleted in time
+func CleanupNamespacedResources(ctx context.Context, l metav1.ListOptions, cleanupWait bool) error {
+	resources, err := namespacedResources()
+	if err != nil {
+		log.Errorf("Error discovering server resources: %v", err)
+		return nil
+	}
+	log.Infof("Deleting namespaced resources with label %s", l.LabelSelector)
+	for _, gvr := range resources {
+		resourceInterface := DynamicClient.Resource(gvr)
+		resourceList, err := resourceInterface.List(ctx, l)
+		if err != nil {
+			log.Debugf("Unable to list resource: %s error: %v. Hence skipping it", gvr.Resource, err)
+			continue
+		}
+		if len(resourceList.Items) == 0 {
+			continue
+		}
+		for _, item := range resourceList.Items {
+			go func(item unstructured.Unstructured) {
+				err := resourceInterface.Namespace(item.GetNamespace()).Delete(ctx, item.GetName(), metav1.DeleteOptions{})
+				if err != nil && !errors.IsNotFound(err) {
+					log.Errorf("Error deleting %s in namespace %s: %v", item.GetName(), item.GetNamespace(), err)
 				}
-				deleteNonNamespacedResources(ctx, resources, resourceInterface, l, cleanupWait)
+			}(item)
+		}
+		if !cleanupWait {
+			continue
+		}
+		err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+			resourceList, err := resourceInterface.List(ctx, l)
+			if err != nil {
+				return false, err
 			}
+			log.Debugf("Waiting for %d %s labeled with %s to be deleted", len(resourceList.Items), gvr.Resource, l.LabelSelector)
+			return len(resourceList.Items) == 0, nil
+		})
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("timeout waiting for %s labeled with %s to be deleted: %v", gvr.Resource, l.LabelSelector, err)
+			}
+			log.Errorf("Error waiting for %s to be deleted: %v", gvr.Resource, err)
 		}
 	}
-	log.Infof("Deleting non-namespace resources with label %s completed", l.LabelSelector)
+	log.Infof("Deleting namespaced resources with label %s completed", l.LabelSelector)
+	return nil
 }
 
 // Cleanup non-namespaced resources using executor list
-func CleanupNonNamespacedResourcesUsingGVR(ctx context.Context, executorList []Executor, cleanupWait bool) {
+func CleanupNonNamespacedResourcesUsingGVR(ctx context.Context, executorList []Executor, cleanupWait bool) error {
 	log.Info("Deleting non-namespace resources specific to this benchmark")
 	for _, executor := range executorList {
 		for _, object := range executor.objects {
@@ -152,15 +313,18 @@ func CleanupNonNamespacedResourcesUsingGVR(ctx context.Context, executorList []E
 					log.Debugf("Unable to list resources for object: %v error: %v. Hence skipping it", object.Object, err)
 					continue
 				}
-				deleteNonNamespacedResources(ctx, resources, resourceInterface, listOptions, cleanupWait)
+				if err := deleteNonNamespacedResources(ctx, resources, resourceInterface, listOptions, cleanupWait); err != nil {
+					return err
+				}
 			}
 		}
 	}
 	log.Info("Deleting non-namespace resources specific to this benchmark completed")
+	return nil
 }
 
 func deleteNonNamespacedResources(ctx context.Context, resources *unstructured.UnstructuredList, resourceInterface dynamic.NamespaceableResourceInterface,
-	listOptions metav1.ListOptions, cleanupWait bool) {
+	listOptions metav1.ListOptions, cleanupWait bool) error {
 	if len(resources.Items) > 0 {
 		for _, item := range resources.Items {
 			go func(item unstructured.Unstructured) {
@@ -171,18 +335,21 @@ func deleteNonNamespacedResources(ctx context.Context, resources *unstructured.U
 			}(item)
 		}
 		if cleanupWait {
-			waitForDeleteNonNamespacedResources(ctx, resourceInterface, listOptions)
+			return waitForDeleteNonNamespacedResources(ctx, resourceInterface, listOptions)
 		}
 	}
+	return nil
 }
 
-func waitForDeleteNamespaces(ctx context.Context, l metav1.ListOptions) {
+func waitForDeleteNamespaces(ctx context.Context, l metav1.ListOptions) error {
+	var remaining int
 	log.Info("Waiting for namespaces to be definitely deleted")
 	err := wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
 		ns, err := ClientSet.CoreV1().Namespaces().List(ctx, l)
 		if err != nil {
 			return false, err
 		}
+		remaining = len(ns.Items)
 		if len(ns.Items) == 0 {
 			return true, nil
 		}
@@ -191,13 +358,14 @@ func waitForDeleteNamespaces(ctx context.Context, l metav1.ListOptions) {
 	})
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			log.Fatalf("Timeout cleaning up namespaces: %v", err)
+			return fmt.Errorf("timeout cleaning up namespaces, %d namespaces labeled with %s remaining: %v", remaining, l.LabelSelector, err)
 		}
 		log.Errorf("Error cleaning up namespaces: %v", err)
 	}
+	return nil
 }
 
-func waitForDeleteNamespacedResources(ctx context.Context, namespace string, objects []object, l metav1.ListOptions) {
+func waitForDeleteNamespacedResources(ctx context.Context, namespace string, objects []object, l metav1.ListOptions) error {
 	err := wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
 		allDeleted := true
 		for _, obj := range objects {
@@ -219,13 +387,14 @@ func waitForDeleteNamespacedResources(ctx context.Context, namespace string, obj
 	})
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			log.Fatalf("Timeout waiting for objects to be deleted: %v", err)
+			return fmt.Errorf("timeout waiting for objects labeled with %s in namespace %s to be deleted: %v", l.LabelSelector, namespace, err)
 		}
 		log.Errorf("Error waiting for objects to be deleted: %v", err)
 	}
+	return nil
 }
 
-func waitForDeleteNonNamespacedResources(ctx context.Context, resourceInterface dynamic.NamespaceableResourceInterface, l metav1.ListOptions) {
+func waitForDeleteNonNamespacedResources(ctx context.Context, resourceInterface dynamic.NamespaceableResourceInterface, l metav1.ListOptions) error {
 	log.Info("Waiting for non-namespaced resources to be definitely deleted")
 	err := wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
 		resources, err := resourceInterface.List(ctx, l)
@@ -240,8 +409,9 @@ func waitForDeleteNonNamespacedResources(ctx context.Context, resourceInterface
 	})
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			log.Fatalf("Timeout cleaning up non-namespaced resources: %v", err)
+			return fmt.Errorf("timeout cleaning up non-namespaced resources: %v", err)
 		}
 		log.Errorf("Error cleaning up non-namespaced resources: %v", err)
 	}
+	return nil
 }
@@ -0,0 +1,105 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// objectDependencies orders the creation of the objects of each iteration by their dependencies: the objects of a
+// dependency level are created once the objects of the previous levels are
+type objectDependencies struct {
+	// levels dependency level of each object, by object index
+	levels []int
+	// indexes object index of each object name
+	indexes map[string]int
+}
+
+// newObjectDependencies returns nil when no object of the job depends on another one
+func newObjectDependencies(jobName string, objects []object) (*objectDependencies, error) {
+	var dependencies bool
+	configObjects := make([]config.Object, len(objects))
+	indexes := make(map[string]int)
+	for i, obj := range objects {
+		configObjects[i] = obj.Object
+		if obj.Name != "" {
+			indexes[obj.Name] = i
+		}
+	}
+	for i, obj := range configObjects {
+		// Objects skipped for having no replicas aren't waited for
+		var dependsOn []string
+		for _, dependency := range obj.DependsOn {
+			if _, exists := indexes[dependency]; !exists {
+				log.Warnf("Job %s: object %s depends on %s, which isn't created, ignoring dependency", jobName, obj.ObjectTemplate, dependency)
+				continue
+			}
+			dependsOn = append(dependsOn, dependency)
+		}
+		configObjects[i].DependsOn = dependsOn
+		dependencies = dependencies || len(dependsOn) > 0
+	}
+	if !dependencies {
+		return nil, nil
+	}
+	// Cycles were already rejected when parsing the configuration
+	levels, err := config.DependencyLevels(configObjects)
+	if err != nil {
+		return nil, fmt.Errorf("job %s: %s", jobName, err)
+	}
+	return &objectDependencies{
+		levels:  levels,
+		indexes: indexes,
+	}, nil
+}
+
+// level returns the dependency level of the given object, all objects are in the same level when there are no dependencies
+func (d *objectDependencies) level(objectIndex int) int {
+	if d == nil {
+		return 0
+	}
+	return d.levels[objectIndex]
+}
+
+// waitForDependencies waits for the objects the given one depends on to be ready in the namespace of the iteration,
+// or in the namespace they were created in when they're runOnce objects
+func (ex *Executor) waitForDependencies(obj object, ns string, iteration int, limiter *rate.Limiter) {
+	if ex.dependencies == nil || !obj.WaitForDependencies {
+		return
+	}
+	for _, dependency := range obj.DependsOn {
+		i, exists := ex.dependencies.indexes[dependency]
+		if !exists {
+			continue
+		}
+		depNs := ns
+		if ex.objects[i].RunOnce {
+			depNs = ex.runOnceNamespace(i)
+		}
+		start := time.Now()
+		log.Debugf("Waiting for dependency %s of %s in namespace %s", dependency, obj.ObjectTemplate, depNs)
+		if err := ex.waitForObject(ex.objects[i], depNs, limiter); err != nil {
+			log.Errorf("Error waiting for dependency %s of %s in namespace %s: %s", dependency, obj.ObjectTemplate, depNs, err)
+			ex.failureEvents.captureWaitFailure(depNs, ex.objects[i].kind)
+			ex.failedIterations.fail(iteration)
+		}
+		ex.timer.since(phaseWaiting, start)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"sync"
+	"time"
+)
+
+// objectMarkers records the wall-clock markers of the objects of a creation job: when the first object was submitted,
+// when the last one was created and when the last readiness wait completed
+type objectMarkers struct {
+	lock           sync.Mutex
+	firstSubmitted time.Time
+	lastCreated    time.Time
+	lastReady      time.Time
+}
+
+// objectMarkersSummary holds the markers of a job, and their offset in seconds from the start of the job
+type objectMarkersSummary struct {
+	FirstObjectTimestamp *time.Time `json:"firstObjectTimestamp,omitempty"`
+	LastObjectTimestamp  *time.Time `json:"lastObjectTimestamp,omitempty"`
+	LastReadyTimestamp   *time.Time `json:"lastReadyTimestamp,omitempty"`
+	TimeToFirstObject    float64    `json:"timeToFirstObject,omitempty"`
+	TimeToLastObject     float64    `json:"timeToLastObject,omitempty"`
+	TimeToLastReady      float64    `json:"timeToLastReady,omitempty"`
+}
+
+func (m *objectMarkers) submitted() {
+	if m == nil {
+		return
+	}
+	now := time.Now().UTC()
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.firstSubmitted.IsZero() {
+		m.firstSubmitted = now
+	}
+}
+
+func (m *objectMarkers) created() {
+	if m == nil {
+		return
+	}
+	now := time.Now().UTC()
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if now.After(m.lastCreated) {
+		m.lastCreated = now
+	}
+}
+
+func (m *objectMarkers) ready() {
+	if m == nil {
+		return
+	}
+	now := time.Now().UTC()
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if now.After(m.lastReady) {
+		m.lastReady = now
+	}
+}
+
+// summary returns the markers recorded, relative to the given job start
+func (m *objectMarkers) summary(jobStart time.Time) objectMarkersSummary {
+	var s objectMarkersSummary
+	if m == nil {
+		return s
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	marker := func(t time.Time) (*time.Time, float64) {
+		if t.IsZero() {
+			return nil, 0
+		}
+		return &t, t.Sub(jobStart).Seconds()
+	}
+	s.FirstObjectTimestamp, s.TimeToFirstObject = marker(m.firstSubmitted)
+	s.LastObjectTimestamp, s.TimeToLastObject = marker(m.lastCreated)
+	s.LastReadyTimestamp, s.TimeToLastReady = marker(m.lastReady)
+	return s
+}
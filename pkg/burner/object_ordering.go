@@ -0,0 +1,61 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// objectOrderer shuffles the order in which the objects of each iteration are created
+type objectOrderer struct {
+	rand *rand.Rand
+	lock sync.Mutex
+}
+
+// newObjectOrderer returns nil when the job uses sequential ordering
+func newObjectOrderer(jobConfig config.Job) *objectOrderer {
+	if jobConfig.ObjectOrdering != config.OrderingShuffled {
+		return nil
+	}
+	var seed int64
+	if jobConfig.OrderingSeed != nil {
+		seed = *jobConfig.OrderingSeed
+	}
+	log.Infof("Job %s: shuffled object ordering with seed %d", jobConfig.Name, seed)
+	return &objectOrderer{
+		rand: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// order returns the indexes of the given number of objects in the order they have to be created in the next iteration
+func (o *objectOrderer) order(objects int) []int {
+	indexes := make([]int, objects)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	if o == nil {
+		return indexes
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.rand.Shuffle(objects, func(i, j int) {
+		indexes[i], indexes[j] = indexes[j], indexes[i]
+	})
+	return indexes
+}
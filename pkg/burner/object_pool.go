@@ -0,0 +1,62 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"sync"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	log "github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// objectPool creates the objects of an iteration concurrently, with a bounded number of workers
+type objectPool struct {
+	workers int
+}
+
+// newObjectPool returns nil when the job handles the objects of each iteration one after another
+func newObjectPool(jobConfig config.Job) *objectPool {
+	if jobConfig.ObjectConcurrency <= 1 {
+		return nil
+	}
+	log.Infof("Job %s: creating up to %d objects of each iteration concurrently", jobConfig.Name, jobConfig.ObjectConcurrency)
+	return &objectPool{workers: jobConfig.ObjectConcurrency}
+}
+
+// run runs the given tasks in order, or concurrently when the pool is set, and returns once all of them finished,
+// aggregating their errors
+func (p *objectPool) run(tasks []func() error) error {
+	errs := make([]error, len(tasks))
+	if p == nil {
+		for i, task := range tasks {
+			errs[i] = task()
+		}
+		return utilerrors.NewAggregate(errs)
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, p.workers)
+	for i, task := range tasks {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, task func() error) {
+			defer wg.Done()
+			errs[i] = task()
+			<-sem
+		}(i, task)
+	}
+	wg.Wait()
+	return utilerrors.NewAggregate(errs)
+}
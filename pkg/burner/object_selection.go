@@ -0,0 +1,158 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const objectSelectionDistributionMetric = "objectSelectionDistribution"
+
+// objectSelector picks the object created in each iteration of jobs with weighted selection
+type objectSelector struct {
+	weights     []int
+	totalWeight int
+	rand        *rand.Rand
+	counts      []int
+	lock        sync.Mutex
+}
+
+type objectSelectionCount struct {
+	ObjectTemplate string  `json:"objectTemplate"`
+	Weight         int     `json:"weight"`
+	Iterations     int     `json:"iterations"`
+	Ratio          float64 `json:"ratio"`
+}
+
+type objectSelectionDistribution struct {
+	Timestamp  time.Time              `json:"timestamp"`
+	UUID       string                 `json:"uuid"`
+	MetricName string                 `json:"metricName"`
+	JobName    string                 `json:"jobName"`
+	Seed       int64                  `json:"seed"`
+	Objects    []objectSelectionCount `json:"objects"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// newObjectSelector returns nil when the job doesn't use weighted selection, runOnce objects are never picked
+func newObjectSelector(jobConfig config.Job, objects []object) *objectSelector {
+	if jobConfig.Selection != config.SelectionWeighted {
+		return nil
+	}
+	selector := &objectSelector{
+		weights: make([]int, len(objects)),
+		rand:    rand.New(rand.NewSource(jobConfig.SelectionSeed)),
+		counts:  make([]int, len(objects)),
+	}
+	for i, obj := range objects {
+		if !obj.RunOnce {
+			selector.weights[i] = obj.Weight
+			selector.totalWeight += obj.Weight
+		}
+	}
+	if selector.totalWeight == 0 {
+		log.Warnf("Job %s: no object with weight greater than 0 to select, creating all of them", jobConfig.Name)
+		return nil
+	}
+	return selector
+}
+
+// pick returns the index of the object to create in the next iteration, -1 means all of them
+func (s *objectSelector) pick() int {
+	if s == nil {
+		return -1
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	pick := s.rand.Intn(s.totalWeight)
+	var selected int
+	for i, weight := range s.weights {
+		if pick < weight {
+			selected = i
+			break
+		}
+		pick -= weight
+	}
+	s.counts[selected]++
+	return selected
+}
+
+// selected returns true when the given object has to be created in an iteration with the given pick
+func selected(pick, objectIndex int, obj object) bool {
+	return pick < 0 || obj.RunOnce || pick == objectIndex
+}
+
+// indexObjectSelectionDistribution indexes the number of iterations each object was picked in
+func (ex *Executor) indexObjectSelectionDistribution(indexer *indexers.Indexer, metadata map[string]interface{}) {
+	var total int
+	if ex.objectSelector == nil || ex.SkipIndexing {
+		return
+	}
+	distribution := objectSelectionDistribution{
+		Timestamp:  time.Now().UTC(),
+		UUID:       ex.uuid,
+		MetricName: objectSelectionDistributionMetric,
+		JobName:    ex.Name,
+		Seed:       ex.SelectionSeed,
+		Metadata:   metadata,
+	}
+	for _, count := range ex.objectSelector.counts {
+		total += count
+	}
+	for i, obj := range ex.objects {
+		if obj.RunOnce {
+			continue
+		}
+		objectCount := objectSelectionCount{
+			ObjectTemplate: obj.ObjectTemplate,
+			Weight:         ex.objectSelector.weights[i],
+			Iterations:     ex.objectSelector.counts[i],
+		}
+		if total > 0 {
+			objectCount.Ratio = float64(objectCount.Iterations) / float64(total)
+		}
+		distribution.Objects = append(distribution.Objects, objectCount)
+	}
+	log.Infof("Indexing metric %s", objectSelectionDistributionMetric)
+	indexingOpts := indexers.IndexingOpts{
+		MetricName: fmt.Sprintf("%s-%s", objectSelectionDistributionMetric, ex.Name),
+	}
+	resp, err := (*indexer).Index([]interface{}{distribution}, indexingOpts)
+	if err != nil {
+		log.Error(err)
+	} else {
+		log.Info(resp)
+	}
+}
+
+// logObjectSelectionDistribution logs the seed and the number of iterations each object was picked in
+func (ex *Executor) logObjectSelectionDistribution() {
+	if ex.objectSelector == nil {
+		return
+	}
+	log.Infof("Job %s: weighted selection with seed %d", ex.Name, ex.SelectionSeed)
+	for i, obj := range ex.objects {
+		if !obj.RunOnce {
+			log.Infof("Object %s: picked in %d iterations", obj.ObjectTemplate, ex.objectSelector.counts[i])
+		}
+	}
+}
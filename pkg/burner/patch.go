@@ -16,12 +16,14 @@ package burner
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements"
 	"github.com/cloud-bulldozer/kube-burner/pkg/util"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -33,12 +35,14 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 )
 
-func setupPatchJob(jobConfig config.Job) Executor {
+func setupPatchJob(jobConfig config.Job) (Executor, error) {
 	var f io.Reader
-	var err error
 	log.Debugf("Preparing patch job: %s", jobConfig.Name)
 	var ex Executor
-	mapper := newRESTMapper()
+	mapper, err := newRESTMapper()
+	if err != nil {
+		return ex, err
+	}
 	for _, o := range jobConfig.Objects {
 		if o.APIVersion == "" {
 			o.APIVersion = "v1"
@@ -46,26 +50,26 @@ func setupPatchJob(jobConfig config.Job) Executor {
 		log.Debugf("Rendering template: %s", o.ObjectTemplate)
 		f, err = util.ReadConfig(o.ObjectTemplate)
 		if err != nil {
-			log.Fatalf("Error reading template %s: %s", o.ObjectTemplate, err)
+			return ex, fmt.Errorf("error reading template %s: %s", o.ObjectTemplate, err)
 		}
 		t, err := io.ReadAll(f)
 		if err != nil {
-			log.Fatalf("Error reading template %s: %s", o.ObjectTemplate, err)
+			return ex, fmt.Errorf("error reading template %s: %s", o.ObjectTemplate, err)
 		}
 
 		// Unlike create, we don't want to create the gvk with the entire template,
 		// because it would try to use the properties of the patch data to find
 		// the objects to patch.
 		gvk := schema.FromAPIVersionAndKind(o.APIVersion, o.Kind)
-		mapping, err := mapper.RESTMapping(gvk.GroupKind())
+		mapping, err := restMapping(mapper, gvk)
 		if err != nil {
-			log.Fatal(err)
+			return ex, err
 		}
 		if len(o.LabelSelector) == 0 {
-			log.Fatalf("Empty labelSelectors not allowed with: %s", o.Kind)
+			return ex, fmt.Errorf("empty labelSelectors not allowed with: %s", o.Kind)
 		}
 		if len(o.PatchType) == 0 {
-			log.Fatalln("Empty Patch Type not allowed")
+			return ex, fmt.Errorf("empty patch type not allowed with: %s", o.Kind)
 		}
 		obj := object{
 			gvr:           mapping.Resource,
@@ -78,7 +82,7 @@ func setupPatchJob(jobConfig config.Job) Executor {
 		log.Infof("Job %s: Patch %s with selector %s", jobConfig.Name, gvk.Kind, labels.Set(obj.labelSelector))
 		ex.objects = append(ex.objects, obj)
 	}
-	return ex
+	return ex, nil
 }
 
 // RunPatchJob executes a patch job
@@ -127,22 +131,27 @@ func (ex *Executor) patchHandler(obj object, originalItem unstructured.Unstructu
 
 	if strings.HasSuffix(obj.ObjectTemplate, "json") {
 		if obj.patchType == string(types.ApplyPatchType) {
-			log.Fatalf("Apply patch type requires YAML")
+			abortRun(fmt.Sprintf("job %s: apply patch type requires YAML, %s is JSON", ex.Name, obj.ObjectTemplate))
+			return
 		}
 		data = obj.objectSpec
 	} else {
 		// Processing template
+		templatingStart := time.Now()
 		templateData := map[string]interface{}{
 			jobName:      ex.Name,
 			jobIteration: iteration,
 			jobUUID:      ex.uuid,
+			values:       templateValues,
+			env:          templateEnv,
 		}
 		for k, v := range obj.InputVars {
 			templateData[k] = v
 		}
-		renderedObj, err := util.RenderTemplate(obj.objectSpec, templateData, util.MissingKeyError)
+		renderedObj, err := util.RenderTemplateWithEngine(templateEngine, obj.objectSpec, templateData, util.MissingKeyError)
 		if err != nil {
-			log.Fatalf("Template error in %s: %s", obj.ObjectTemplate, err)
+			abortRun(fmt.Sprintf("job %s: template error in %s, iteration %d: %s", ex.Name, obj.ObjectTemplate, iteration, err))
+			return
 		}
 
 		// Converting to JSON if patch type is not Apply
@@ -151,37 +160,54 @@ func (ex *Executor) patchHandler(obj object, originalItem unstructured.Unstructu
 			patchOptions.FieldManager = "kube-controller-manager"
 		} else {
 			newObject := &unstructured.Unstructured{}
-			yamlToUnstructured(renderedObj, newObject)
+			if _, _, err := yamlToUnstructured(renderedObj, newObject); err != nil {
+				abortRun(fmt.Sprintf("job %s: %s in %s, iteration %d", ex.Name, err, obj.ObjectTemplate, iteration))
+				return
+			}
 			data, err = newObject.MarshalJSON()
 			if err != nil {
 				log.Errorf("Error converting patch to JSON")
 			}
 		}
+		ex.timer.since(phaseTemplating, templatingStart)
 	}
 
 	ns := originalItem.GetNamespace()
 	log.Debugf("Patching %s/%s in namespace %s", originalItem.GetKind(),
 		originalItem.GetName(), ns)
+	throttlingStart := time.Now()
 	ex.limiter.Wait(context.TODO())
+	ex.timer.since(phaseThrottling, throttlingStart)
 
 	var uns *unstructured.Unstructured
-	var err error
-	if obj.Namespaced {
-		uns, err = DynamicClient.Resource(obj.gvr).Namespace(ns).
-			Patch(context.TODO(), originalItem.GetName(),
-				types.PatchType(obj.patchType), data, patchOptions)
-	} else {
-		uns, err = DynamicClient.Resource(obj.gvr).
-			Patch(context.TODO(), originalItem.GetName(),
-				types.PatchType(obj.patchType), data, patchOptions)
-	}
-	if err != nil {
-		if errors.IsForbidden(err) {
-			log.Fatalf("Authorization error patching %s/%s: %s", originalItem.GetKind(), originalItem.GetName(), err)
-		} else if err != nil {
-			log.Errorf("Error patching object %s/%s in namespace %s: %s", originalItem.GetKind(),
-				originalItem.GetName(), ns, err)
+	var attempts int
+	start := time.Now()
+	err := ex.retryRequestOn(isRetryablePatch, func() error {
+		var err error
+		attempts++
+		callStart := time.Now()
+		if obj.Namespaced {
+			uns, err = DynamicClient.Resource(obj.gvr).Namespace(ns).
+				Patch(context.TODO(), originalItem.GetName(),
+					types.PatchType(obj.patchType), data, patchOptions)
+		} else {
+			uns, err = DynamicClient.Resource(obj.gvr).
+				Patch(context.TODO(), originalItem.GetName(),
+					types.PatchType(obj.patchType), data, patchOptions)
 		}
+		measurements.RecordAPICall("patch", originalItem.GetKind(), ns, originalItem.GetName(), callStart, err)
+		if errors.IsForbidden(err) {
+			abortRun(fmt.Sprintf("authorization error patching %s/%s: %s", originalItem.GetKind(), originalItem.GetName(), err))
+		}
+		return err
+	}, &ex.retries.patch)
+	ex.timer.since(phaseAPICalls, start)
+	measurements.RecordPatch(originalItem.GetKind(), ns, originalItem.GetName(), start, attempts-1, err)
+	if errors.IsNotFound(err) {
+		log.Warnf("%s/%s in namespace %s not found, it was deleted after being listed", originalItem.GetKind(), originalItem.GetName(), ns)
+	} else if err != nil {
+		log.Errorf("Error patching object %s/%s in namespace %s: %s", originalItem.GetKind(),
+			originalItem.GetName(), ns, err)
 	} else {
 		log.Debugf("Patched %s/%s in namespace %s", uns.GetKind(), uns.GetName(), ns)
 	}
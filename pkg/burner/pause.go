@@ -0,0 +1,88 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	log "github.com/sirupsen/logrus"
+)
+
+const pauseMetric = "pauseInterval"
+
+// pauseInterval holds the interval slept by a pause job, durations are in seconds
+type pauseInterval struct {
+	Timestamp     time.Time              `json:"timestamp"`
+	EndTimestamp  time.Time              `json:"endTimestamp"`
+	UUID          string                 `json:"uuid"`
+	MetricName    string                 `json:"metricName"`
+	JobName       string                 `json:"jobName"`
+	PauseDuration float64                `json:"pauseDuration"`
+	Jitter        float64                `json:"jitter"`
+	ElapsedTime   float64                `json:"elapsedTime"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// RunPauseJob sleeps for the pause duration, randomly increased or decreased up to the jitter percentage,
+// so pauses of distributed runs don't line up. A zero duration is a no-op, and the pause stops when the run is interrupted
+func (ex *Executor) RunPauseJob() *pauseInterval {
+	if ex.PauseDuration == 0 {
+		log.Infof("Job %s: pause duration is 0, skipping", ex.Name)
+		return nil
+	}
+	pause := ex.PauseDuration
+	if ex.Jitter > 0 {
+		jitter := (rand.Float64()*2 - 1) * ex.Jitter / 100
+		pause += time.Duration(float64(ex.PauseDuration) * jitter)
+		if pause < 0 {
+			pause = 0
+		}
+	}
+	interval := &pauseInterval{
+		Timestamp:     time.Now().UTC(),
+		UUID:          ex.uuid,
+		MetricName:    pauseMetric,
+		JobName:       ex.Name,
+		PauseDuration: ex.PauseDuration.Seconds(),
+		Jitter:        ex.Jitter,
+	}
+	log.Infof("Job %s: pausing for %v", ex.Name, pause)
+	select {
+	case <-time.After(pause):
+	case <-jobCtx.Done():
+		log.Warnf("Job %s: pause stopped before %v", ex.Name, pause)
+	}
+	interval.EndTimestamp = time.Now().UTC()
+	interval.ElapsedTime = interval.EndTimestamp.Sub(interval.Timestamp).Seconds()
+	log.Infof("Job %s: pause finished after %v", ex.Name, interval.EndTimestamp.Sub(interval.Timestamp).Round(time.Millisecond))
+	return interval
+}
+
+// indexPauseInterval indexes the interval slept by a pause job
+func (ex *Executor) indexPauseInterval(indexer *indexers.Indexer, interval *pauseInterval, metadata map[string]interface{}) {
+	if interval == nil || ex.SkipIndexing {
+		return
+	}
+	interval.Metadata = metadata
+	log.Infof("Indexing metric %s", pauseMetric)
+	resp, err := (*indexer).Index([]interface{}{interval}, indexers.IndexingOpts{MetricName: pauseMetric})
+	if err != nil {
+		log.Error(err)
+	} else {
+		log.Info(resp)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/metrics"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+const podLogsRetryInterval = 2 * time.Second
+
+// podLogSampler tails the logs of a sample of the pods created by a job into files
+type podLogSampler struct {
+	lock      sync.Mutex
+	sample    int
+	random    *rand.Rand
+	seen      int
+	directory string
+	// sampled holds the streams of the sampled pods, in sampling order
+	sampled []*podLogStream
+	watcher *metrics.Watcher
+	wg      sync.WaitGroup
+}
+
+// podLogStream tails the logs of all the containers of a pod
+type podLogStream struct {
+	namespace string
+	name      string
+	cancel    context.CancelFunc
+	files     map[string]*int64
+	// dropped is set when the pod is replaced by another one in the random sample
+	dropped atomic.Bool
+}
+
+// startPodLogs starts tailing the logs of a sample of the pods created by the job, when configured
+func (ex *Executor) startPodLogs(metricsDirectory string) *podLogSampler {
+	if ex.PodLogs == nil {
+		return nil
+	}
+	directory := path.Join(metricsDirectory, "pod-logs", ex.Name)
+	if err := os.MkdirAll(directory, 0744); err != nil {
+		log.Errorf("Error creating pod logs directory %s: %s", directory, err)
+		return nil
+	}
+	s := &podLogSampler{
+		sample:    ex.PodLogs.Sample,
+		directory: directory,
+	}
+	if ex.PodLogs.Selection == config.PodLogsRandom {
+		s.random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	selector := labels.Set{"kube-burner-uuid": ex.uuid, "kube-burner-job": ex.Name}
+	s.watcher = metrics.NewWatcher(
+		ClientSet.CoreV1().RESTClient().(*rest.RESTClient),
+		"podLogsWatcher",
+		"pods",
+		corev1.NamespaceAll,
+		func(options *metav1.ListOptions) {
+			options.LabelSelector = selector.String()
+		},
+	)
+	s.watcher.Informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: s.handleCreatePod,
+	})
+	if err := s.watcher.StartAndCacheSync(); err != nil {
+		log.Errorf("Pod logs sampler error: %s", err)
+	}
+	log.Infof("Capturing logs of %d %s pods of job %s into %s", s.sample, ex.PodLogs.Selection, ex.Name, directory)
+	return s
+}
+
+// handleCreatePod samples the pods as they're created. Random samples use reservoir sampling, so every
+// created pod has the same probability of being kept, and the replaced pods get their logs removed
+func (s *podLogSampler) handleCreatePod(obj interface{}) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.seen++
+	if len(s.sampled) < s.sample {
+		s.sampled = append(s.sampled, s.tail(pod))
+		return
+	}
+	if s.random == nil {
+		return
+	}
+	if i := s.random.Intn(s.seen); i < s.sample {
+		replaced := s.sampled[i]
+		replaced.dropped.Store(true)
+		replaced.cancel()
+		s.sampled[i] = s.tail(pod)
+	}
+}
+
+// tail starts following the logs of each container of the pod
+func (s *podLogSampler) tail(pod *corev1.Pod) *podLogStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &podLogStream{
+		namespace: pod.Namespace,
+		name:      pod.Name,
+		cancel:    cancel,
+		files:     make(map[string]*int64),
+	}
+	for _, container := range pod.Spec.Containers {
+		file := path.Join(s.directory, fmt.Sprintf("%s_%s_%s.log", pod.Namespace, pod.Name, container.Name))
+		written := new(int64)
+		stream.files[file] = written
+		s.wg.Add(1)
+		go func(container, file string) {
+			defer s.wg.Done()
+			stream.follow(ctx, container, file, written)
+		}(container.Name, file)
+	}
+	return stream
+}
+
+// follow copies the logs of the container into the file until the context is cancelled. Containers not started
+// yet are retried, and the logs are followed again after container restarts
+func (p *podLogStream) follow(ctx context.Context, container, file string, written *int64) {
+	f, err := os.Create(file)
+	if err != nil {
+		log.Errorf("Error creating pod log file %s: %s", file, err)
+		return
+	}
+	defer func() {
+		f.Close()
+		if p.dropped.Load() {
+			os.Remove(file)
+		}
+	}()
+	logOptions := &corev1.PodLogOptions{Container: container, Follow: true}
+	for {
+		stream, err := ClientSet.CoreV1().Pods(p.namespace).GetLogs(p.name, logOptions).Stream(ctx)
+		if err == nil {
+			n, _ := io.Copy(f, stream)
+			stream.Close()
+			atomic.AddInt64(written, n)
+			// Follow the next container instance from now on
+			logOptions.SinceTime = &metav1.Time{Time: time.Now()}
+		} else if kerrors.IsNotFound(err) {
+			log.Debugf("Pod %s/%s not found, stopping its log capture", p.namespace, p.name)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(podLogsRetryInterval):
+		}
+	}
+}
+
+// stop stops tailing the logs, and records in the log files of the containers that didn't produce any
+// logs, like the ones that never started, the pod phase and the container state
+func (s *podLogSampler) stop() {
+	if s == nil {
+		return
+	}
+	s.watcher.StopWatcher()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, stream := range s.sampled {
+		stream.cancel()
+	}
+	s.wg.Wait()
+	var empty int
+	for _, stream := range s.sampled {
+		var pod *corev1.Pod
+		if obj, exists, _ := s.watcher.Informer.GetStore().GetByKey(stream.namespace + "/" + stream.name); exists {
+			pod = obj.(*corev1.Pod)
+		}
+		for file, written := range stream.files {
+			if atomic.LoadInt64(written) > 0 {
+				continue
+			}
+			empty++
+			note := fmt.Sprintf("kube-burner: no logs were produced by pod %s/%s, %s\n", stream.namespace, stream.name, podLogsState(pod))
+			if err := os.WriteFile(file, []byte(note), 0644); err != nil {
+				log.Errorf("Error writing pod log file %s: %s", file, err)
+			}
+		}
+	}
+	log.Infof("Captured logs of %d pods into %s, %d containers produced no logs", len(s.sampled), s.directory, empty)
+}
+
+// podLogsState describes the state of the pod and its containers
+func podLogsState(pod *corev1.Pod) string {
+	if pod == nil {
+		return "pod state unknown"
+	}
+	state := fmt.Sprintf("pod phase: %s", pod.Status.Phase)
+	for _, cs := range pod.Status.ContainerStatuses {
+		switch {
+		case cs.State.Waiting != nil:
+			state += fmt.Sprintf(", container %s waiting: %s %s", cs.Name, cs.State.Waiting.Reason, cs.State.Waiting.Message)
+		case cs.State.Terminated != nil:
+			state += fmt.Sprintf(", container %s terminated: %s", cs.Name, cs.State.Terminated.Reason)
+		}
+	}
+	return state
+}
@@ -0,0 +1,66 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/prometheus"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// runPreJobCheck blocks until the expression of the job preJobCheck is true in all the Prometheus endpoints.
+// When Prometheus can't be queried, the check fails or is skipped with a warning depending on its onError setting
+func (ex *Executor) runPreJobCheck(prometheusClients []*prometheus.Prometheus) error {
+	check := ex.PreJobCheck
+	if len(prometheusClients) == 0 {
+		return preJobCheckError(check, fmt.Errorf("no Prometheus endpoint configured"))
+	}
+	log.Infof("Waiting for preJobCheck of job %s: %s", ex.Name, check.Expr)
+	var queryErr error
+	err := wait.PollUntilContextTimeout(jobCtx, check.Interval, check.Timeout, true, func(ctx context.Context) (bool, error) {
+		for _, p := range prometheusClients {
+			passed, err := p.EvaluateExpr(check.Expr)
+			if err != nil {
+				queryErr = fmt.Errorf("error querying %s: %s", p.Endpoint, err)
+				return true, nil
+			}
+			if !passed {
+				log.Debugf("preJobCheck expression of job %s is not true in %s yet", ex.Name, p.Endpoint)
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if queryErr != nil {
+		return preJobCheckError(check, queryErr)
+	}
+	if err != nil {
+		return fmt.Errorf("preJobCheck of job %s not passed within %v: %s", ex.Name, check.Timeout, err)
+	}
+	log.Infof("preJobCheck of job %s passed", ex.Name)
+	return nil
+}
+
+func preJobCheckError(check *config.PreJobCheck, err error) error {
+	if check.OnError == config.PreJobCheckWarn {
+		log.Warnf("Skipping preJobCheck: %s", err)
+		return nil
+	}
+	return fmt.Errorf("preJobCheck failed: %s", err)
+}
@@ -61,19 +61,20 @@ func preLoadImages(job Executor) error {
 	// 5 minutes should be more than enough to cleanup this namespace
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
-	CleanupNamespaces(ctx, metav1.ListOptions{LabelSelector: "kube-burner-preload=true"}, true)
-	return nil
+	return CleanupNamespaces(ctx, metav1.ListOptions{LabelSelector: "kube-burner-preload=true"}, true)
 }
 
 func getJobImages(job Executor) ([]string, error) {
 	var imageList []string
 	var unstructuredObject unstructured.Unstructured
 	for _, object := range job.objects {
-		renderedObj, err := util.RenderTemplate(object.objectSpec, object.InputVars, util.MissingKeyZero)
+		renderedObj, err := util.RenderTemplateWithEngine(templateEngine, object.objectSpec, object.InputVars, util.MissingKeyZero)
 		if err != nil {
-			return imageList, err
+			return imageList, fmt.Errorf("template error in %s: %s", object.ObjectTemplate, err)
+		}
+		if _, _, err := yamlToUnstructured(renderedObj, &unstructuredObject); err != nil {
+			return imageList, fmt.Errorf("%s in %s", err, object.ObjectTemplate)
 		}
-		yamlToUnstructured(renderedObj, &unstructuredObject)
 		switch unstructuredObject.GetKind() {
 		case "Deployment", "DaemonSet", "ReplicaSet", "Job":
 			var pod NestedPod
@@ -102,7 +103,7 @@ func createDSs(imageList []string, namespaceLabels map[string]string, nodeSelect
 		nsLabels[label] = value
 	}
 	if err := createNamespace(preLoadNs, nsLabels); err != nil {
-		log.Fatal(err)
+		return err
 	}
 	dsName := "preload"
 	ds := appsv1.DaemonSet{
@@ -0,0 +1,130 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/term"
+)
+
+// progressInterval interval of the progress reports of creation jobs, disabled when 0
+var progressInterval time.Duration
+
+// progressReporter periodically reports the objects created by a job, the current creation rate and an ETA
+type progressReporter struct {
+	jobName string
+	// baseline objects created before the reporter started, i.e. by previous churn cycles
+	baseline int64
+	total    int64
+	created  func() int64
+	tty      bool
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// startProgress starts reporting the progress of the given number of iterations, it returns nil when progress reporting is disabled
+func (ex *Executor) startProgress(iterations int) *progressReporter {
+	if progressInterval <= 0 || ex.created == nil {
+		return nil
+	}
+	p := &progressReporter{
+		jobName:  ex.Name,
+		baseline: ex.created.Load(),
+		total:    int64(math.Round(float64(iterations) * ex.objectsPerIteration())),
+		created:  ex.created.Load,
+		tty:      term.IsTerminal(int(os.Stderr.Fd())),
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+	for objectIndex, obj := range ex.objects {
+		if _, created := ex.runOnceNamespaces[objectIndex]; obj.RunOnce && !created {
+			p.total += int64(obj.Replicas)
+		}
+	}
+	go p.run()
+	return p
+}
+
+// objectsPerIteration returns the number of objects created per iteration, the expected one with weighted selection
+func (ex *Executor) objectsPerIteration() float64 {
+	var objects, weightedReplicas float64
+	var totalWeight int
+	for _, obj := range ex.objects {
+		if obj.RunOnce {
+			continue
+		}
+		if ex.objectSelector == nil {
+			objects += float64(obj.Replicas)
+			continue
+		}
+		weightedReplicas += float64(obj.Weight * obj.Replicas)
+		totalWeight += obj.Weight
+	}
+	if totalWeight > 0 {
+		objects = weightedReplicas / float64(totalWeight)
+	}
+	return objects
+}
+
+func (p *progressReporter) run() {
+	defer close(p.doneCh)
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	lastCreated, lastTime := p.baseline, time.Now()
+	for {
+		select {
+		case <-p.stopCh:
+			if p.tty {
+				fmt.Fprintln(os.Stderr)
+			}
+			return
+		case now := <-ticker.C:
+			created := p.created()
+			rate := float64(created-lastCreated) / now.Sub(lastTime).Seconds()
+			lastCreated, lastTime = created, now
+			p.report(created-p.baseline, rate)
+		}
+	}
+}
+
+// report prints the progress in a single updating line on terminals, and as a log line otherwise
+func (p *progressReporter) report(created int64, rate float64) {
+	eta := "unknown"
+	if remaining := p.total - created; remaining <= 0 {
+		eta = "0s"
+	} else if rate > 0 {
+		eta = (time.Duration(float64(remaining)/rate) * time.Second).String()
+	}
+	msg := fmt.Sprintf("Job %s: %d/%d objects created, %.2f objects/s, ETA %s", p.jobName, created, p.total, rate, eta)
+	if p.tty {
+		fmt.Fprintf(os.Stderr, "\r\033[K%s", msg)
+		return
+	}
+	log.Info(msg)
+}
+
+// stop stops reporting the progress
+func (p *progressReporter) stop() {
+	if p == nil {
+		return
+	}
+	close(p.stopCh)
+	<-p.doneCh
+}
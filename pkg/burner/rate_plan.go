@@ -0,0 +1,144 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// ratePlan transitions the job limiter across the segments of a creation rate plan
+type ratePlan struct {
+	sync.Mutex
+	segments []config.RateSegment
+	limiter  *rate.Limiter
+	// current index of the segment objects are admitted in, and admitted the number of objects admitted in it
+	current  int
+	admitted int
+	trackers []*creationRateTracker
+}
+
+// rateSegmentSummary holds the timeline of a segment of a creation rate plan
+type rateSegmentSummary struct {
+	Count         int       `json:"count"`
+	TargetRate    float64   `json:"targetRate"`
+	AchievedRate  float64   `json:"achievedRate"`
+	Objects       int64     `json:"objects"`
+	FirstCreation time.Time `json:"firstCreation"`
+	LastCreation  time.Time `json:"lastCreation"`
+}
+
+// newRatePlan returns nil when the job doesn't have a rate plan, the limiter is set to the rate of the first segment
+func newRatePlan(segments []config.RateSegment, limiter *rate.Limiter) *ratePlan {
+	if len(segments) == 0 {
+		return nil
+	}
+	p := &ratePlan{
+		segments: segments,
+		limiter:  limiter,
+		trackers: make([]*creationRateTracker, len(segments)),
+	}
+	for i := range p.trackers {
+		p.trackers[i] = &creationRateTracker{}
+	}
+	p.setLimit(0)
+	p.logSegment()
+	return p
+}
+
+// logSegment logs the transition to the current segment
+func (p *ratePlan) logSegment() {
+	segment := p.segments[p.current]
+	objects := "the rest of the objects"
+	if segment.Count > 0 {
+		objects = fmt.Sprintf("%d objects", segment.Count)
+	}
+	pace := "as fast as the client allows"
+	if segment.Rate > 0 {
+		pace = fmt.Sprintf("at %v objects/s", segment.Rate)
+	}
+	log.Infof("Rate plan segment %d: creating %s %s", p.current, objects, pace)
+}
+
+// setLimit sets the limiter to the rate of the given segment, segments without rate aren't paced
+func (p *ratePlan) setLimit(segment int) {
+	limit := rate.Inf
+	if p.segments[segment].Rate > 0 {
+		limit = rate.Limit(p.segments[segment].Rate)
+	}
+	p.limiter.SetLimit(limit)
+}
+
+// admit returns the segment the next object is created in, moving to the next segment once the count of the current one is reached
+func (p *ratePlan) admit() int {
+	if p == nil {
+		return 0
+	}
+	p.Lock()
+	defer p.Unlock()
+	count := p.segments[p.current].Count
+	if count > 0 && p.admitted >= count && p.current < len(p.segments)-1 {
+		p.current++
+		p.admitted = 0
+		p.setLimit(p.current)
+		p.logSegment()
+	}
+	p.admitted++
+	return p.current
+}
+
+// record records an object of the given segment created at the given time
+func (p *ratePlan) record(segment int, t time.Time) {
+	if p == nil {
+		return
+	}
+	p.trackers[segment].record(t)
+}
+
+// timeline returns the summary of every segment of the plan
+func (p *ratePlan) timeline() []rateSegmentSummary {
+	var summaries []rateSegmentSummary
+	for i, segment := range p.segments {
+		tracker := p.trackers[i]
+		achieved := tracker.achieved()
+		tracker.Lock()
+		summaries = append(summaries, rateSegmentSummary{
+			Count:         segment.Count,
+			TargetRate:    segment.Rate,
+			AchievedRate:  achieved,
+			Objects:       tracker.objects,
+			FirstCreation: tracker.first,
+			LastCreation:  tracker.last,
+		})
+		tracker.Unlock()
+	}
+	return summaries
+}
+
+// maxRate returns the highest creation rate of the job, taking the segments of the rate plan into account
+func (ex *Executor) maxRate() float64 {
+	maxRate := ex.Rate
+	for _, segment := range ex.RatePlan {
+		if segment.Rate > maxRate {
+			maxRate = segment.Rate
+		}
+	}
+	return maxRate
+}
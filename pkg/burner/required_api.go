@@ -0,0 +1,107 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"fmt"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// checkRequiredAPIs checks the APIs required by the job and its objects against the cluster discovery information.
+// Objects requiring a missing API are removed from the job, and false is returned when the whole job must be skipped.
+// When the job's missingAPIPolicy is error, a missing API is returned as an error
+func checkRequiredAPIs(job *config.Job) (bool, error) {
+	var mapper meta.RESTMapper
+	var mapperErr error
+	missing := func(apis []string) (string, string) {
+		if len(apis) == 0 || mapperErr != nil {
+			return "", ""
+		}
+		if mapper == nil {
+			if mapper, mapperErr = newRESTMapper(); mapperErr != nil {
+				return "", ""
+			}
+		}
+		for _, api := range apis {
+			if reason := missingAPI(mapper, api); reason != "" {
+				return api, reason
+			}
+		}
+		return "", ""
+	}
+	if api, reason := missing(job.RequiresAPI); api != "" {
+		if job.MissingAPIPolicy == config.MissingAPIError {
+			return false, fmt.Errorf("job %s requires API %s: %s", job.Name, api, reason)
+		}
+		log.Warnf("Skipping job %s, required API %s: %s", job.Name, api, reason)
+		return false, nil
+	}
+	var objects []config.Object
+	for _, o := range job.Objects {
+		if api, reason := missing(o.RequiresAPI); api != "" {
+			if job.MissingAPIPolicy == config.MissingAPIError {
+				return false, fmt.Errorf("job %s: object %s requires API %s: %s", job.Name, objectName(o), api, reason)
+			}
+			log.Warnf("Job %s: skipping object %s, required API %s: %s", job.Name, objectName(o), api, reason)
+			continue
+		}
+		objects = append(objects, o)
+	}
+	if mapperErr != nil {
+		return false, fmt.Errorf("job %s: %s", job.Name, mapperErr)
+	}
+	if len(objects) == 0 && len(job.Objects) > 0 {
+		log.Warnf("Skipping job %s, all its objects were skipped", job.Name)
+		return false, nil
+	}
+	job.Objects = objects
+	return true, nil
+}
+
+// missingAPI returns the reason the given API is not available, or an empty string when it's available
+func missingAPI(mapper meta.RESTMapper, api string) string {
+	// The format was already validated at config parsing
+	gvk, _ := config.ParseRequiredAPI(api)
+	if _, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err == nil {
+		return ""
+	}
+	for gv, err := range failedDiscoveryGroups {
+		if gv.Group == gvk.Group && gv.Version == gvk.Version {
+			return fmt.Sprintf("discovery failed: %v", err)
+		}
+	}
+	return "not found in the cluster"
+}
+
+// objectName returns a name identifying the object within the job
+func objectName(o config.Object) string {
+	if o.ObjectTemplate != "" {
+		return o.ObjectTemplate
+	}
+	return o.Kind
+}
+
+// objectsRequireAPIs returns true when any of the objects requires an API
+func objectsRequireAPIs(objects []config.Object) bool {
+	for _, o := range objects {
+		if len(o.RequiresAPI) > 0 {
+			return true
+		}
+	}
+	return false
+}
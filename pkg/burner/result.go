@@ -0,0 +1,81 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"time"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+)
+
+// Result holds the outcome of a kube-burner run
+type Result struct {
+	// UUID of the run
+	UUID string
+	// ReturnCode suggested process exit code: 0 on success, 1 on failure, 2 on timeout, 3 when objects were leaked, 4 when
+	// interrupted and 5 when aborted, either by a critical alert or by a fatal error, like a template,
+	// authorization or cleanup timeout error
+	ReturnCode int
+	// Jobs results of the executed jobs, in execution order
+	Jobs []JobResult
+	// Errors found during the run
+	Errors []error
+}
+
+// JobResult holds the outcome of a job
+type JobResult struct {
+	// Name of the job
+	Name string
+	// JobType type of the job
+	JobType config.JobType
+	// Start time of the job
+	Start time.Time
+	// End time of the job
+	End time.Time
+	// ElapsedTime time taken by the job
+	ElapsedTime time.Duration
+	// ObjectsCreated number of objects successfully created by the job
+	ObjectsCreated int64
+	// FailedIterations iterations with objects that couldn't be created
+	FailedIterations []int
+	// ChurnCycles number of delete and re-create cycles completed by a churn job
+	ChurnCycles int
+}
+
+// Passed returns true when the run finished without errors
+func (r Result) Passed() bool {
+	return r.ReturnCode == 0
+}
+
+// jobResult builds the result of the given executed job
+func (ex *Executor) jobResult() JobResult {
+	result := JobResult{
+		Name:        ex.Name,
+		JobType:     ex.JobType,
+		Start:       ex.timer.start,
+		End:         ex.timer.end,
+		ElapsedTime: ex.timer.end.Sub(ex.timer.start),
+	}
+	if ex.created != nil {
+		result.ObjectsCreated = ex.created.Load()
+	}
+	if ex.failedIterations != nil {
+		result.FailedIterations = ex.failedIterations.list()
+	}
+	if ex.churn != nil {
+		result.ChurnCycles = ex.churn.CyclesCompleted
+	}
+	return result
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// failedIterationsFile holds the failed iterations of the run with the given UUID
+const failedIterationsFile = "failed-iterations-%s.json"
+
+type failedIterations struct {
+	UUID string           `json:"uuid"`
+	Jobs map[string][]int `json:"jobs"`
+}
+
+// iterationTracker keeps track of the iterations with objects that couldn't be created
+type iterationTracker struct {
+	lock   sync.Mutex
+	failed map[int]bool
+}
+
+func newIterationTracker() *iterationTracker {
+	return &iterationTracker{failed: make(map[int]bool)}
+}
+
+func (t *iterationTracker) fail(iteration int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.failed[iteration] = true
+}
+
+// list returns the sorted list of failed iterations
+func (t *iterationTracker) list() []int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	iterations := make([]int, 0, len(t.failed))
+	for iteration := range t.failed {
+		iterations = append(iterations, iteration)
+	}
+	sort.Ints(iterations)
+	return iterations
+}
+
+// RunFailedIterations runs only the given iterations of a creation job, grouping consecutive iterations
+func (ex *Executor) RunFailedIterations(iterations []int, waitListNamespaces *[]string) {
+	log.Infof("Retrying %d failed iterations of job %s", len(iterations), ex.Name)
+	sort.Ints(iterations)
+	for i := 0; i < len(iterations); {
+		j := i + 1
+		for j < len(iterations) && iterations[j] == iterations[j-1]+1 {
+			j++
+		}
+		ex.RunCreateJob(iterations[i], iterations[j-1]+1, waitListNamespaces)
+		i = j
+	}
+}
+
+// ReadFailedIterations reads the failed iterations of each job recorded by the run with the given UUID
+func ReadFailedIterations(uuid string) (map[string][]int, error) {
+	var failed failedIterations
+	fileName := fmt.Sprintf(failedIterationsFile, uuid)
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("error reading failed iterations of run %s: %s", uuid, err)
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(&failed); err != nil {
+		return nil, fmt.Errorf("error decoding %s: %s", fileName, err)
+	}
+	return failed.Jobs, nil
+}
+
+// writeFailedIterations persists the failed iterations of the given jobs, the file is removed when there are none
+func writeFailedIterations(uuid string, jobList []Executor) {
+	failed := failedIterations{
+		UUID: uuid,
+		Jobs: make(map[string][]int),
+	}
+	fileName := fmt.Sprintf(failedIterationsFile, uuid)
+	for _, job := range jobList {
+		if job.failedIterations == nil {
+			continue
+		}
+		if iterations := job.failedIterations.list(); len(iterations) > 0 {
+			log.Warnf("Job %s: %d iterations failed", job.Name, len(iterations))
+			failed.Jobs[job.Name] = iterations
+		}
+	}
+	if len(failed.Jobs) == 0 {
+		if err := os.Remove(fileName); err != nil && !os.IsNotExist(err) {
+			log.Errorf("Error removing %s: %s", fileName, err)
+		}
+		return
+	}
+	f, err := os.Create(fileName)
+	if err != nil {
+		log.Errorf("Error creating %s: %s", fileName, err)
+		return
+	}
+	defer f.Close()
+	if err := json.NewEncoder(f).Encode(failed); err != nil {
+		log.Errorf("Error writing %s: %s", fileName, err)
+		return
+	}
+	log.Infof("Failed iterations written to %s, use --retry-failed=%s to retry them", fileName, uuid)
+}
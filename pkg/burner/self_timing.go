@@ -0,0 +1,107 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	log "github.com/sirupsen/logrus"
+)
+
+const selfTimingMetric = "burnerSelfTiming"
+
+// phase is an internal phase of a job
+type phase int
+
+const (
+	phaseTemplating phase = iota
+	phaseThrottling
+	phaseAPICalls
+	phaseWaiting
+	phaseIndexing
+	numPhases
+)
+
+// phaseTimer accumulates the time spent in each phase. Phases running in parallel goroutines,
+// like templating or API calls, accumulate the time of all of them
+type phaseTimer struct {
+	durations [numPhases]int64
+	start     time.Time
+	end       time.Time
+}
+
+// selfTiming holds the time spent in each phase of a job, in seconds
+type selfTiming struct {
+	Timestamp    time.Time              `json:"timestamp"`
+	EndTimestamp time.Time              `json:"endTimestamp"`
+	UUID         string                 `json:"uuid"`
+	MetricName   string                 `json:"metricName"`
+	JobName      string                 `json:"jobName"`
+	ElapsedTime  float64                `json:"elapsedTime"`
+	Templating   float64                `json:"templating"`
+	Throttling   float64                `json:"throttling"`
+	APICalls     float64                `json:"apiCalls"`
+	Waiting      float64                `json:"waiting"`
+	Indexing     float64                `json:"indexing"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// since adds the time elapsed since start to the given phase
+func (t *phaseTimer) since(p phase, start time.Time) {
+	if t == nil {
+		return
+	}
+	atomic.AddInt64(&t.durations[p], int64(time.Since(start)))
+}
+
+func (t *phaseTimer) seconds(p phase) float64 {
+	return time.Duration(atomic.LoadInt64(&t.durations[p])).Seconds()
+}
+
+// indexSelfTiming indexes a burnerSelfTiming document per executed job
+func indexSelfTiming(indexer *indexers.Indexer, jobList []Executor, metadata map[string]interface{}) {
+	var docs []interface{}
+	for _, job := range jobList {
+		if job.timer == nil || job.timer.end.IsZero() || job.SkipIndexing {
+			continue
+		}
+		docs = append(docs, selfTiming{
+			Timestamp:    job.timer.start,
+			EndTimestamp: job.timer.end,
+			UUID:         job.uuid,
+			MetricName:   selfTimingMetric,
+			JobName:      job.Name,
+			ElapsedTime:  job.timer.end.Sub(job.timer.start).Seconds(),
+			Templating:   job.timer.seconds(phaseTemplating),
+			Throttling:   job.timer.seconds(phaseThrottling),
+			APICalls:     job.timer.seconds(phaseAPICalls),
+			Waiting:      job.timer.seconds(phaseWaiting),
+			Indexing:     job.timer.seconds(phaseIndexing),
+			Metadata:     metadata,
+		})
+	}
+	if len(docs) == 0 {
+		return
+	}
+	log.Infof("Indexing metric %s", selfTimingMetric)
+	resp, err := (*indexer).Index(docs, indexers.IndexingOpts{MetricName: selfTimingMetric})
+	if err != nil {
+		log.Error(err)
+	} else {
+		log.Info(resp)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"context"
+	"time"
+)
+
+// jobCtx is the context of the running job, derived from runCtx. When the jobs declare a timeoutWeight,
+// its deadline is the one of the timeout budget allocated to the job
+var jobCtx = context.Background()
+
+// timeoutBudget splits the overall timeout across the jobs according to their timeoutWeight
+type timeoutBudget struct {
+	deadline time.Time
+	// shares fraction of the timeout of each job of the list, by position
+	shares []float64
+}
+
+// newTimeoutBudget returns the timeout budget of the given jobs, nil when none of them declares a timeoutWeight.
+// Jobs without timeoutWeight share equally the fraction of the timeout not claimed by the weighted ones
+func newTimeoutBudget(jobList []Executor, timeout time.Duration) *timeoutBudget {
+	var weights float64
+	var unweighted int
+	for _, job := range jobList {
+		weights += job.TimeoutWeight
+		if job.TimeoutWeight == 0 {
+			unweighted++
+		}
+	}
+	if weights == 0 {
+		return nil
+	}
+	b := &timeoutBudget{
+		deadline: time.Now().Add(timeout),
+		shares:   make([]float64, len(jobList)),
+	}
+	for i, job := range jobList {
+		b.shares[i] = job.TimeoutWeight
+		if job.TimeoutWeight == 0 {
+			b.shares[i] = (1 - weights) / float64(unweighted)
+		}
+	}
+	return b
+}
+
+// allocate returns the timeout of the job at the given position. The remaining time is split across the jobs
+// still to run, so the time not used by the previous jobs, or by the skipped ones, rolls over to them
+func (b *timeoutBudget) allocate(position int) time.Duration {
+	var pending float64
+	for _, share := range b.shares[position:] {
+		pending += share
+	}
+	remaining := time.Until(b.deadline)
+	if remaining <= 0 || pending == 0 {
+		return 0
+	}
+	return time.Duration(float64(remaining) * b.shares[position] / pending)
+}
+
+// jobTimedOut returns true when the timeout budget of the running job is exhausted
+func jobTimedOut() bool {
+	return !interrupted() && jobCtx.Err() != nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// traceEvent represents an event of the Chrome trace-event format
+type traceEvent struct {
+	Name string                 `json:"name"`
+	Cat  string                 `json:"cat,omitempty"`
+	Ph   string                 `json:"ph"`
+	Ts   int64                  `json:"ts"`
+	Dur  int64                  `json:"dur,omitempty"`
+	Pid  int                    `json:"pid"`
+	Tid  int                    `json:"tid"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+type iterationKey struct {
+	pid       int
+	iteration int
+}
+
+// tracer collects the timing spans of the sampled iterations, the process of each span is its job,
+// and its thread the job iteration
+type tracer struct {
+	config.TraceConfig
+	start      time.Time
+	step       int
+	lock       sync.Mutex
+	events     []traceEvent
+	dropped    int
+	jobs       map[string]int
+	iterations map[iterationKey][2]int64
+}
+
+// chromeTracer is nil when tracing is disabled
+var chromeTracer *tracer
+
+func newTracer(traceConfig config.TraceConfig) *tracer {
+	return &tracer{
+		TraceConfig: traceConfig,
+		start:       time.Now(),
+		// Iterations are sampled deterministically, one of every step iterations is traced
+		step:       int(math.Max(math.Round(1/traceConfig.SampleRate), 1)),
+		jobs:       make(map[string]int),
+		iterations: make(map[iterationKey][2]int64),
+	}
+}
+
+// sampled returns true when the given iteration is traced
+func (t *tracer) sampled(iteration int) bool {
+	return t != nil && iteration%t.step == 0
+}
+
+// addSpan records a complete event from start to now
+func (t *tracer) addSpan(name, category, jobName string, iteration int, start time.Time, args map[string]interface{}) {
+	if !t.sampled(iteration) {
+		return
+	}
+	ts := start.Sub(t.start).Microseconds()
+	end := time.Since(t.start).Microseconds()
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if len(t.events) >= t.MaxEvents {
+		t.dropped++
+		return
+	}
+	pid, exists := t.jobs[jobName]
+	if !exists {
+		pid = len(t.jobs) + 1
+		t.jobs[jobName] = pid
+	}
+	t.events = append(t.events, traceEvent{
+		Name: name,
+		Cat:  category,
+		Ph:   "X",
+		Ts:   ts,
+		Dur:  end - ts,
+		Pid:  pid,
+		Tid:  iteration,
+		Args: args,
+	})
+	// Keep track of the iteration boundaries to generate its parent span
+	key := iterationKey{pid: pid, iteration: iteration}
+	bounds, exists := t.iterations[key]
+	if !exists || ts < bounds[0] {
+		bounds[0] = ts
+	}
+	if end > bounds[1] {
+		bounds[1] = end
+	}
+	t.iterations[key] = bounds
+}
+
+// write dumps the collected events to the configured file in Chrome trace-event JSON format
+func (t *tracer) write() {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	events := make([]traceEvent, 0, len(t.events)+len(t.iterations)+len(t.jobs))
+	for jobName, pid := range t.jobs {
+		events = append(events, traceEvent{Name: "process_name", Ph: "M", Pid: pid, Args: map[string]interface{}{"name": jobName}})
+	}
+	for key, bounds := range t.iterations {
+		events = append(events, traceEvent{
+			Name: fmt.Sprintf("iteration %d", key.iteration),
+			Cat:  "iteration",
+			Ph:   "X",
+			Ts:   bounds[0],
+			Dur:  bounds[1] - bounds[0],
+			Pid:  key.pid,
+			Tid:  key.iteration,
+		})
+	}
+	events = append(events, t.events...)
+	if t.dropped > 0 {
+		log.Warnf("Trace events limit of %d reached, %d events were dropped", t.MaxEvents, t.dropped)
+	}
+	f, err := os.Create(t.File)
+	if err != nil {
+		log.Errorf("Error creating trace file %s: %s", t.File, err)
+		return
+	}
+	defer f.Close()
+	if err := json.NewEncoder(f).Encode(map[string]interface{}{"traceEvents": events}); err != nil {
+		log.Errorf("Error writing trace file %s: %s", t.File, err)
+		return
+	}
+	log.Infof("Trace with %d events written to %s", len(events), t.File)
+}
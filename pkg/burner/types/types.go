@@ -14,6 +14,8 @@
 
 package types
 
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 const (
 	// OpenShift Build CRD
 	OpenShiftBuildGroup      = "build.openshift.io"
@@ -30,8 +32,9 @@ const (
 
 // Condition contains details for the current condition of this pod.
 type Condition struct {
-	Type   string `json:"type"`
-	Status string `json:"status"`
+	Type               string      `json:"type"`
+	Status             string      `json:"status"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // UnstructuredContent minimum unstructured object content to unmarshal the status phase of a CRD
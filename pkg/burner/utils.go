@@ -22,16 +22,18 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
 	"github.com/openshift/client-go/config/clientset/versioned"
 	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/restmapper"
 	"k8s.io/kubectl/pkg/scheme"
 )
 
@@ -60,6 +62,7 @@ func setMetadataLabels(obj *unstructured.Unstructured, labels map[string]string)
 	// objects (i.e Pods under deployment/replicastes). So this function should help
 	// us achieve that without breaking any of our labeling functionality.
 	templatePath := []string{"spec", "template", "metadata", "labels"}
+	setVolumeClaimTemplateLabels(obj, labels)
 	metadata, found, _ := unstructured.NestedMap(obj.Object, templatePath...)
 	if !found {
 		return
@@ -70,12 +73,129 @@ func setMetadataLabels(obj *unstructured.Unstructured, labels map[string]string)
 	unstructured.SetNestedMap(obj.Object, metadata, templatePath...)
 }
 
-func yamlToUnstructured(y []byte, uns *unstructured.Unstructured) (runtime.Object, *schema.GroupVersionKind) {
+// setVolumeClaimTemplateLabels labels the claims created from the volumeClaimTemplates of a StatefulSet,
+// so they're selected by the measurements watching the objects of the run
+func setVolumeClaimTemplateLabels(obj *unstructured.Unstructured, labels map[string]string) {
+	claimTemplates, found, _ := unstructured.NestedSlice(obj.Object, "spec", "volumeClaimTemplates")
+	if !found {
+		return
+	}
+	for i, claimTemplate := range claimTemplates {
+		claim, ok := claimTemplate.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		claimLabels, _, _ := unstructured.NestedStringMap(claim, "metadata", "labels")
+		if claimLabels == nil {
+			claimLabels = make(map[string]string, len(labels))
+		}
+		for k, v := range labels {
+			claimLabels[k] = v
+		}
+		unstructured.SetNestedStringMap(claim, claimLabels, "metadata", "labels")
+		claimTemplates[i] = claim
+	}
+	unstructured.SetNestedSlice(obj.Object, claimTemplates, "spec", "volumeClaimTemplates")
+}
+
+// setSweepResources sets the resource quantity corresponding to the given iteration to all the containers of the object
+func setSweepResources(obj *unstructured.Unstructured, sweep *config.ResourceSweep, iteration, iterations int) {
+	containersPath := append(podSpecPath(obj.GetKind()), "containers")
+	containers, found, _ := unstructured.NestedSlice(obj.Object, containersPath...)
+	if !found {
+		log.Warnf("Containers not found in %s/%s, skipping resource sweep", obj.GetKind(), obj.GetName())
+		return
+	}
+	quantity := sweepQuantity(sweep, iteration, iterations)
+	fields := []string{"requests"}
+	if sweep.Limits {
+		fields = append(fields, "limits")
+	}
+	for i := range containers {
+		container, ok := containers[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, field := range fields {
+			unstructured.SetNestedField(container, quantity, "resources", field, sweep.Resource)
+		}
+		containers[i] = container
+	}
+	unstructured.SetNestedSlice(obj.Object, containers, containersPath...)
+}
+
+// podSpecPath returns the path of the pod spec within an object of the given kind
+func podSpecPath(kind string) []string {
+	switch kind {
+	case "Pod":
+		return []string{"spec"}
+	case "CronJob":
+		return []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	default:
+		return []string{"spec", "template", "spec"}
+	}
+}
+
+// setReadinessDelay delays the readiness of the containers of the given object. Existing readiness probes get the delay
+// as initial delay, and a readiness probe running the given command is injected in the containers without one
+func setReadinessDelay(obj *unstructured.Unstructured, delay time.Duration, command []string) {
+	containersPath := append(podSpecPath(obj.GetKind()), "containers")
+	containers, found, _ := unstructured.NestedSlice(obj.Object, containersPath...)
+	if !found {
+		log.Debugf("Containers not found in %s/%s, skipping readiness delay", obj.GetKind(), obj.GetName())
+		return
+	}
+	// initialDelaySeconds only accepts seconds, so the delay is rounded up
+	delaySeconds := int64(math.Ceil(delay.Seconds()))
+	probeCommand := make([]interface{}, len(command))
+	for i, arg := range command {
+		probeCommand[i] = arg
+	}
+	for i := range containers {
+		container, ok := containers[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, found := container["readinessProbe"]; !found {
+			container["readinessProbe"] = map[string]interface{}{
+				"exec": map[string]interface{}{
+					"command": probeCommand,
+				},
+				"periodSeconds": int64(1),
+			}
+		}
+		unstructured.SetNestedField(container, delaySeconds, "readinessProbe", "initialDelaySeconds")
+		containers[i] = container
+	}
+	unstructured.SetNestedSlice(obj.Object, containers, containersPath...)
+}
+
+// sweepQuantity linearly interpolates the sweep quantity for the given iteration
+func sweepQuantity(sweep *config.ResourceSweep, iteration, iterations int) string {
+	// Quantities were already validated at config parsing
+	start := resource.MustParse(sweep.Start)
+	end := resource.MustParse(sweep.End)
+	if iterations <= 1 {
+		return start.String()
+	}
+	if iteration >= iterations {
+		iteration = iterations - 1
+	}
+	step := float64(end.MilliValue()-start.MilliValue()) / float64(iterations-1)
+	milliValue := start.MilliValue() + int64(math.Round(step*float64(iteration)))
+	// Millis are only relevant for CPU like resources
+	if milliValue%1000 == 0 || sweep.Resource != string(corev1.ResourceCPU) {
+		return resource.NewQuantity(milliValue/1000, start.Format).String()
+	}
+	return resource.NewMilliQuantity(milliValue, start.Format).String()
+}
+
+func yamlToUnstructured(y []byte, uns *unstructured.Unstructured) (runtime.Object, *schema.GroupVersionKind, error) {
 	o, gvk, err := scheme.Codecs.UniversalDeserializer().Decode(y, nil, uns)
 	if err != nil {
-		log.Fatalf("Error decoding YAML: %s", err)
+		return nil, nil, fmt.Errorf("error decoding YAML: %s", err)
 	}
-	return o, gvk
+	return o, gvk, nil
 }
 
 // Verify verifies the number of created objects
@@ -112,6 +232,11 @@ func (ex *Executor) Verify() bool {
 			continue
 		}
 		objectsExpected := ex.JobIterations * obj.Replicas
+		if obj.RunOnce {
+			objectsExpected = obj.Replicas
+		} else if ex.objectSelector != nil {
+			objectsExpected = ex.objectSelector.counts[objectIndex] * obj.Replicas
+		}
 		if replicas != objectsExpected {
 			log.Errorf("%s found: %d Expected: %d", obj.gvr.Resource, replicas, objectsExpected)
 			success = false
@@ -177,11 +302,46 @@ func isEmpty(raw []byte) bool {
 	return strings.TrimSpace(string(raw)) == ""
 }
 
-// newMapper returns a discovery RESTMapper
-func newRESTMapper() meta.RESTMapper {
-	apiGroupResouces, err := restmapper.GetAPIGroupResources(discoveryClient)
+// newRESTMapper returns the RESTMapper of the API resources discovered during the run
+func newRESTMapper() (meta.RESTMapper, error) {
+	_, mapper, err := cachedDiscovery(false)
+	return mapper, err
+}
+
+// restMapping returns the RESTMapping of the given kind. Unknown kinds refresh the discovery cache once, as they may
+// belong to CRDs installed after it was populated
+func restMapping(mapper meta.RESTMapper, gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	mapping, err := mapper.RESTMapping(gvk.GroupKind())
+	if meta.IsNoMatchError(err) {
+		log.Debugf("Kind %s not found, refreshing discovery information", gvk)
+		if _, mapper, err = cachedDiscovery(true); err == nil {
+			mapping, err = mapper.RESTMapping(gvk.GroupKind())
+		}
+	}
+	if meta.IsNoMatchError(err) && staticDiscovery() {
+		// The resources of the kinds unknown to the static mappings, like the ones of CRDs, are guessed
+		gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+		scope := meta.RESTScopeNamespace
+		if clusterScopedKinds[gvk.Kind] {
+			scope = meta.RESTScopeRoot
+		}
+		log.Debugf("Kind %s not found in the static mappings, assuming it's the %s resource %s", gvk, scope.Name(), gvr.Resource)
+		return &meta.RESTMapping{Resource: gvr, GroupVersionKind: gvk, Scope: scope}, nil
+	}
 	if err != nil {
-		log.Fatal(err)
+		for gv, discoveryErr := range failedDiscoveryGroups {
+			if gv.Group == gvk.Group {
+				return nil, fmt.Errorf("unable to resolve %s, discovery failed for %s: %v", gvk.Kind, gv, discoveryErr)
+			}
+		}
+		return nil, err
+	}
+	return mapping, nil
+}
+
+// logFailedDiscoveryGroups reports the group versions skipped due to discovery errors
+func logFailedDiscoveryGroups(groups map[schema.GroupVersion]error) {
+	for gv, err := range groups {
+		log.Debugf("Skipping group version %s, discovery failed: %v", gv, err)
 	}
-	return restmapper.NewDiscoveryRESTMapper(apiGroupResouces)
 }
@@ -0,0 +1,149 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements"
+	"github.com/cloud-bulldozer/kube-burner/pkg/util"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+// Validate statically validates a parsed configuration, it renders the object templates with sample iteration
+// variables and checks the referenced files, selectors and durations. It never contacts the API server nor
+// Prometheus, and returns all the problems found
+func Validate(configSpec config.Spec) []error {
+	errs := measurements.Validate(configSpec.GlobalConfig.Measurements)
+	errs = append(errs, validateLabels("injectLabels", configSpec.GlobalConfig.InjectLabels)...)
+	for _, job := range configSpec.Jobs {
+		var jobErrs []error
+		durations := []struct {
+			name string
+			d    time.Duration
+		}{
+			{"jobIterationDelay", job.JobIterationDelay},
+			{"jobPause", job.JobPause},
+			{"maxWaitTimeout", job.MaxWaitTimeout},
+			{"deletionTimeout", job.DeletionTimeout},
+			{"preLoadPeriod", job.PreLoadPeriod},
+			{"churnTeardownWaveJitter", job.ChurnTeardownWaveJitter},
+			{"baselineDuration", job.BaselineDuration},
+			{"retryBackoff", job.RetryBackoff},
+			{"pauseDuration", job.PauseDuration},
+			{"readinessDelay", job.ReadinessDelay},
+		}
+		for _, duration := range durations {
+			if duration.d < 0 {
+				jobErrs = append(jobErrs, fmt.Errorf("%s can't be negative: %v", duration.name, duration.d))
+			}
+		}
+		jobErrs = append(jobErrs, validateLabels("namespaceLabels", job.NamespaceLabels)...)
+		for i, obj := range job.Objects {
+			for _, err := range validateObject(configSpec, job, obj) {
+				jobErrs = append(jobErrs, fmt.Errorf("object %d: %s", i, err))
+			}
+		}
+		for _, err := range jobErrs {
+			errs = append(errs, fmt.Errorf("job %s: %s", job.Name, err))
+		}
+	}
+	return errs
+}
+
+// validateObject validates the object of the given job, rendering its template when it has one
+func validateObject(configSpec config.Spec, job config.Job, obj config.Object) []error {
+	var errs []error
+	switch job.JobType {
+	case config.DeletionJob, config.PatchJob:
+		if obj.Kind == "" || obj.APIVersion == "" {
+			errs = append(errs, fmt.Errorf("kind and apiVersion are required"))
+		}
+		if _, err := labels.ValidatedSelectorFromSet(obj.LabelSelector); err != nil {
+			errs = append(errs, fmt.Errorf("invalid labelSelector: %s", err))
+		}
+		if job.JobType == config.DeletionJob {
+			return errs
+		}
+	case config.CreationJob:
+	default:
+		return errs
+	}
+	if obj.ObjectTemplate == "" {
+		return append(errs, fmt.Errorf("objectTemplate is required"))
+	}
+	var f io.Reader
+	var err error
+	if configSpec.EmbedFS == (embed.FS{}) {
+		f, err = util.ReadConfig(obj.ObjectTemplate)
+	} else {
+		f, err = util.ReadEmbedConfig(configSpec.EmbedFS, path.Join(configSpec.EmbedFSDir, obj.ObjectTemplate))
+	}
+	if err != nil {
+		return append(errs, fmt.Errorf("error reading template %s: %s", obj.ObjectTemplate, err))
+	}
+	t, err := io.ReadAll(f)
+	if err != nil {
+		return append(errs, fmt.Errorf("error reading template %s: %s", obj.ObjectTemplate, err))
+	}
+	templateData := map[string]interface{}{
+		jobName:       job.Name,
+		jobIteration:  0,
+		jobUUID:       configSpec.GlobalConfig.UUID,
+		replica:       1,
+		nameSuffixVar: nameSuffix(job.NameSeed, job.Name, 0),
+		values:        configSpec.GlobalConfig.Values,
+		env:           configSpec.GlobalConfig.Env,
+	}
+	for k, v := range obj.InputVars {
+		templateData[k] = v
+	}
+	rendered, err := util.RenderTemplateWithEngine(util.TemplateEngine(configSpec.GlobalConfig.TemplateEngine), t, templateData, util.MissingKeyError)
+	if err != nil {
+		return append(errs, fmt.Errorf("template error in %s: %s", obj.ObjectTemplate, err))
+	}
+	// Patch templates can hold JSON patches instead of objects
+	if job.JobType == config.PatchJob || strings.HasSuffix(obj.ObjectTemplate, "json") {
+		return errs
+	}
+	uns := &unstructured.Unstructured{}
+	if _, _, err := scheme.Codecs.UniversalDeserializer().Decode(rendered, nil, uns); err != nil {
+		return append(errs, fmt.Errorf("error decoding template %s: %s", obj.ObjectTemplate, err))
+	}
+	return append(errs, validateLabels("labels", uns.GetLabels())...)
+}
+
+// validateLabels validates the keys and values of the given labels
+func validateLabels(field string, l map[string]string) []error {
+	var errs []error
+	for k, v := range l {
+		for _, msg := range validation.IsQualifiedName(k) {
+			errs = append(errs, fmt.Errorf("%s: invalid key %q: %s", field, k, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(v) {
+			errs = append(errs, fmt.Errorf("%s: invalid value %q of %s: %s", field, v, k, msg))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,156 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/burner/types"
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+)
+
+// waitOptionsAnnotation holds the forCondition(s) or forJSONPath waitOptions of the object, so verify applies them
+const waitOptionsAnnotation = "kube-burner-wait-options"
+
+// NotReadyObject object found not ready when verifying the readiness of the objects of a run
+type NotReadyObject struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Reason    string
+}
+
+// readinessPredicate returns whether the object is ready and, when it isn't, the reason
+type readinessPredicate func(obj unstructured.Unstructured) (bool, string)
+
+// typedReadiness converts the object to its typed kind before evaluating the given predicate
+func typedReadiness[T any](predicate func(T) (bool, string)) readinessPredicate {
+	return func(obj unstructured.Unstructured) (bool, string) {
+		var typed T
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &typed); err != nil {
+			return false, fmt.Sprintf("error decoding object: %s", err)
+		}
+		return predicate(typed)
+	}
+}
+
+// readyCondition is the condition kube-burner waits for in the KubeVirt VirtualMachines and VirtualMachineInstances
+func readyCondition(obj unstructured.Unstructured) (bool, string) {
+	_, ready, reason := conditionsReady(obj, []string{"Ready"})
+	return ready, reason
+}
+
+// resourceReadiness readiness predicates of the resources kube-burner waits for
+var resourceReadiness = map[schema.GroupResource]readinessPredicate{
+	{Group: "apps", Resource: "deployments"}:                                               typedReadiness(deploymentReady),
+	{Group: "apps", Resource: "replicasets"}:                                               typedReadiness(replicaSetReady),
+	{Group: "apps", Resource: "statefulsets"}:                                              typedReadiness(statefulSetReady),
+	{Group: "apps", Resource: "daemonsets"}:                                                typedReadiness(daemonSetReady),
+	{Group: "", Resource: "replicationcontrollers"}:                                        typedReadiness(replicationControllerReady),
+	{Group: "", Resource: "pods"}:                                                          typedReadiness(podReady),
+	{Group: "", Resource: "persistentvolumeclaims"}:                                        typedReadiness(pvcReady),
+	{Group: "batch", Resource: "jobs"}:                                                     typedReadiness(jobReady),
+	{Group: types.OpenShiftBuildGroup, Resource: types.OpenShiftBuildResource}:             buildReady,
+	{Group: types.KubevirtGroup, Resource: types.VirtualMachineResource}:                   readyCondition,
+	{Group: types.KubevirtGroup, Resource: types.VirtualMachineInstanceResource}:           readyCondition,
+	{Group: types.KubevirtGroup, Resource: types.VirtualMachineInstanceReplicaSetResource}: vmiReplicaSetReady,
+}
+
+// VerifyReadiness checks, without waiting, that the objects matching the given options are ready, with the same criteria
+// kube-burner waits for them: the forCondition(s) or forJSONPath waitOptions recorded in the objects, or otherwise the
+// readiness of their kind. Objects of other kinds aren't checked. The number of objects checked is returned along with
+// the ones not ready
+func VerifyReadiness(ctx context.Context, listOptions metav1.ListOptions) (int, []NotReadyObject, error) {
+	var checked int
+	var notReady []NotReadyObject
+	var errs []error
+	var resources []schema.GroupVersionResource
+	for _, namespaced := range []bool{true, false} {
+		gvrs, err := listableResources(namespaced)
+		if err != nil {
+			return 0, nil, fmt.Errorf("error discovering server resources: %s", err)
+		}
+		resources = append(resources, gvrs...)
+	}
+	for _, gvr := range resources {
+		predicate, known := resourceReadiness[gvr.GroupResource()]
+		objs, err := DynamicClient.Resource(gvr).List(ctx, listOptions)
+		if err != nil {
+			// Only the resources kube-burner waits for are expected to be listable
+			if known {
+				errs = append(errs, fmt.Errorf("error listing %s: %s", gvr.Resource, err))
+			} else {
+				log.Debugf("Unable to list resource: %s error: %v. Hence skipping it", gvr.Resource, err)
+			}
+			continue
+		}
+		var resourceChecked, resourceNotReady int
+		for _, obj := range objs.Items {
+			var ready bool
+			var reason string
+			if waitOptions, ok := obj.GetAnnotations()[waitOptionsAnnotation]; ok {
+				ready, reason = waitOptionsReady(obj, waitOptions)
+			} else if known {
+				ready, reason = predicate(obj)
+			} else {
+				continue
+			}
+			resourceChecked++
+			if !ready {
+				resourceNotReady++
+				notReady = append(notReady, NotReadyObject{obj.GetKind(), obj.GetNamespace(), obj.GetName(), reason})
+			}
+		}
+		if resourceChecked > 0 {
+			log.Debugf("%d %s checked, %d not ready", resourceChecked, gvr.Resource, resourceNotReady)
+		}
+		checked += resourceChecked
+	}
+	return checked, notReady, utilerrors.NewAggregate(errs)
+}
+
+// waitOptionsReady evaluates the waitOptions recorded in the object
+func waitOptionsReady(obj unstructured.Unstructured, annotation string) (bool, string) {
+	var waitOptions config.WaitOptions
+	if err := json.Unmarshal([]byte(annotation), &waitOptions); err != nil {
+		return false, fmt.Sprintf("invalid %s annotation: %s", waitOptionsAnnotation, err)
+	}
+	conditions := waitOptions.ForConditions
+	if waitOptions.ForCondition != "" {
+		conditions = []string{waitOptions.ForCondition}
+	}
+	if len(conditions) > 0 {
+		_, ready, reason := conditionsReady(obj, conditions)
+		return ready, reason
+	}
+	jp, err := config.ParseWaitJSONPath(waitOptions.ForJSONPath)
+	if err != nil {
+		return false, err.Error()
+	}
+	ready, reason, err := jsonPathReady(jp, obj, waitOptions.ForJSONPath, waitOptions.Value)
+	if err != nil {
+		return false, fmt.Sprintf("error evaluating %s: %s", waitOptions.ForJSONPath, err)
+	}
+	return ready, reason
+}
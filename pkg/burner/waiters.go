@@ -15,19 +15,28 @@
 package burner
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/time/rate"
 
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/jsonpath"
 
 	"github.com/cloud-bulldozer/kube-burner/pkg/burner/types"
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements"
 )
 
 func (ex *Executor) waitForObjects(ns string, limiter *rate.Limiter) {
@@ -35,53 +44,188 @@ func (ex *Executor) waitForObjects(ns string, limiter *rate.Limiter) {
 		if !obj.Wait {
 			continue
 		}
-		if obj.WaitOptions.ForCondition != "" {
-			if !obj.Namespaced {
-				ns = ""
-			}
-			waitForCondition(obj.gvr, ns, obj.WaitOptions.ForCondition, ex.MaxWaitTimeout, limiter)
+		if err := ex.waitForObject(obj, ns, limiter); err != nil {
+			log.Errorf("Error waiting for %s in namespace %s: %s", obj.kind, ns, err)
+			ex.failureEvents.captureWaitFailure(ns, obj.kind)
 		} else {
-			switch obj.kind {
-			case "Deployment":
-				waitForDeployments(ns, ex.MaxWaitTimeout, limiter)
-			case "ReplicaSet":
-				waitForRS(ns, ex.MaxWaitTimeout, limiter)
-			case "ReplicationController":
-				waitForRC(ns, ex.MaxWaitTimeout, limiter)
-			case "StatefulSet":
-				waitForStatefulSet(ns, ex.MaxWaitTimeout, limiter)
-			case "DaemonSet":
-				waitForDS(ns, ex.MaxWaitTimeout, limiter)
-			case "Pod":
-				waitForPod(ns, ex.MaxWaitTimeout, limiter)
-			case "Build", "BuildConfig":
-				waitForBuild(ns, ex.MaxWaitTimeout, obj.Replicas, limiter)
-			case "VirtualMachine":
-				waitForVM(ns, ex.MaxWaitTimeout, limiter)
-			case "VirtualMachineInstance":
-				waitForVMI(ns, ex.MaxWaitTimeout, limiter)
-			case "VirtualMachineInstanceReplicaSet":
-				waitForVMIRS(ns, ex.MaxWaitTimeout, limiter)
-			case "Job":
-				waitForJob(ns, ex.MaxWaitTimeout, limiter)
-			case "PersistentVolumeClaim":
-				waitForPVC(ns, ex.MaxWaitTimeout, limiter)
-			}
+			ex.objectMarkers.ready()
 		}
 	}
 	log.Infof("Actions in namespace %v completed", ns)
 }
 
-func waitForDeployments(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) {
-	// TODO handle errors such as timeouts
-	wait.PollUntilContextTimeout(context.TODO(), time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
-		limiter.Wait(context.TODO())
-		deps, err := ClientSet.AppsV1().Deployments(ns).List(context.TODO(), metav1.ListOptions{})
+// waitForObject waits for the objects of the given kind in the namespace to be ready
+func (ex *Executor) waitForObject(obj object, ns string, limiter *rate.Limiter) error {
+	var err error
+	// The object timeout, when given, takes precedence over the job one, which still caps it since the job
+	// timeout can be shortened at runtime, e.g. by the timeout budget
+	maxWaitTimeout := ex.MaxWaitTimeout
+	if obj.MaxWaitTimeout > 0 && obj.MaxWaitTimeout < ex.MaxWaitTimeout {
+		maxWaitTimeout = obj.MaxWaitTimeout
+	}
+	// Claims, like the ones created from the volumeClaimTemplates of a StatefulSet, are waited for before the object
+	if obj.WaitOptions.ForPVCsBound && obj.Namespaced {
+		if err := waitForPVC(ns, maxWaitTimeout, limiter); err != nil {
+			log.Errorf("Error waiting for PersistentVolumeClaims of %s in namespace %s to be bound: %s", obj.kind, ns, err)
+			ex.failureEvents.captureWaitFailure(ns, "PersistentVolumeClaim")
+		}
+	}
+	if obj.WaitOptions.ForCondition != "" || len(obj.WaitOptions.ForConditions) > 0 {
+		if !obj.Namespaced {
+			ns = ""
+		}
+		conditions := obj.WaitOptions.ForConditions
+		if obj.WaitOptions.ForCondition != "" {
+			conditions = []string{obj.WaitOptions.ForCondition}
+		}
+		err = waitForConditions(obj.gvr, ns, conditions, maxWaitTimeout, limiter)
+	} else if obj.WaitOptions.ForJSONPath != "" {
+		if !obj.Namespaced {
+			ns = ""
+		}
+		err = waitForJSONPath(obj.gvr, ns, obj.WaitOptions.ForJSONPath, obj.WaitOptions.Value, maxWaitTimeout, limiter)
+	} else {
+		switch obj.kind {
+		case "Deployment":
+			err = waitForDeployments(ns, maxWaitTimeout, limiter)
+		case "ReplicaSet":
+			err = waitForRS(ns, maxWaitTimeout, limiter)
+		case "ReplicationController":
+			err = waitForRC(ns, maxWaitTimeout, limiter)
+		case "StatefulSet":
+			err = waitForStatefulSet(ns, maxWaitTimeout, limiter)
+		case "DaemonSet":
+			err = waitForDS(ns, maxWaitTimeout, limiter)
+		case "Pod":
+			err = waitForPod(ns, maxWaitTimeout, limiter)
+		case "Build", "BuildConfig":
+			err = waitForBuild(ns, maxWaitTimeout, obj.Replicas, limiter)
+		case "VirtualMachine":
+			err = waitForVM(ns, maxWaitTimeout, limiter)
+		case "VirtualMachineInstance":
+			err = waitForVMI(ns, maxWaitTimeout, limiter)
+		case "VirtualMachineInstanceReplicaSet":
+			err = waitForVMIRS(ns, maxWaitTimeout, limiter)
+		case "Job":
+			err = waitForJob(ns, maxWaitTimeout, limiter)
+		case "PersistentVolumeClaim":
+			err = waitForPVC(ns, maxWaitTimeout, limiter)
+		}
+	}
+	return err
+}
+
+// The readiness predicates below are shared by the waiters and VerifyReadiness, so both apply the same criteria.
+// They return whether the object is ready and, when it isn't, the reason
+
+// replicasReady returns whether all the desired replicas are ready
+func replicasReady(desired *int32, ready int32, unit string) (bool, string) {
+	if desired == nil || *desired == ready {
+		return true, ""
+	}
+	return false, fmt.Sprintf("%d/%d %s ready", ready, *desired, unit)
+}
+
+func deploymentReady(dep appsv1.Deployment) (bool, string) {
+	return replicasReady(dep.Spec.Replicas, dep.Status.ReadyReplicas, "replicas")
+}
+
+func replicaSetReady(rs appsv1.ReplicaSet) (bool, string) {
+	return replicasReady(rs.Spec.Replicas, rs.Status.ReadyReplicas, "replicas")
+}
+
+func statefulSetReady(sts appsv1.StatefulSet) (bool, string) {
+	return replicasReady(sts.Spec.Replicas, sts.Status.ReadyReplicas, "replicas")
+}
+
+func daemonSetReady(ds appsv1.DaemonSet) (bool, string) {
+	return replicasReady(&ds.Status.DesiredNumberScheduled, ds.Status.NumberReady, "pods")
+}
+
+func replicationControllerReady(rc corev1.ReplicationController) (bool, string) {
+	return replicasReady(rc.Spec.Replicas, rc.Status.ReadyReplicas, "replicas")
+}
+
+func podReady(pod corev1.Pod) (bool, string) {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false, fmt.Sprintf("phase %s", pod.Status.Phase)
+	}
+	return true, ""
+}
+
+func pvcReady(pvc corev1.PersistentVolumeClaim) (bool, string) {
+	if pvc.Status.Phase != corev1.ClaimBound {
+		return false, fmt.Sprintf("phase %s", pvc.Status.Phase)
+	}
+	return true, ""
+}
+
+// jobReady returns whether the Job is completed, failed Jobs are never ready
+func jobReady(job batchv1.Job) (bool, string) {
+	if failed, reason := jobFailed(job); failed {
+		return false, fmt.Sprintf("failed: %s", reason)
+	}
+	if !jobCompleted(job) {
+		return false, fmt.Sprintf("%d pods succeeded", job.Status.Succeeded)
+	}
+	return true, ""
+}
+
+// buildReady returns whether the Build is finished, whatever its outcome
+func buildReady(build unstructured.Unstructured) (bool, string) {
+	phase, _, _ := unstructured.NestedString(build.Object, "status", "phase")
+	switch phase {
+	case "", "New", "Pending", "Running":
+		return false, fmt.Sprintf("phase %q", phase)
+	}
+	return true, ""
+}
+
+func vmiReplicaSetReady(rs unstructured.Unstructured) (bool, string) {
+	desired, _, _ := unstructured.NestedInt64(rs.Object, "spec", "replicas")
+	ready, _, _ := unstructured.NestedInt64(rs.Object, "status", "readyReplicas")
+	if desired != ready {
+		return false, fmt.Sprintf("%d/%d replicas ready", ready, desired)
+	}
+	return true, ""
+}
+
+// conditionsReady returns whether all the given conditions are true in the object, and the latest transition time among them
+func conditionsReady(obj unstructured.Unstructured, conditions []string) (time.Time, bool, string) {
+	var content types.UnstructuredContent
+	jsonObj, err := obj.MarshalJSON()
+	if err != nil {
+		return time.Time{}, false, fmt.Sprintf("error decoding object: %s", err)
+	}
+	_ = json.Unmarshal(jsonObj, &content)
+	readyTime, missing := conditionsMet(content.Status.Conditions, conditions)
+	if len(missing) > 0 {
+		return readyTime, false, fmt.Sprintf("conditions %s not true", strings.Join(missing, ", "))
+	}
+	return readyTime, true, ""
+}
+
+// jsonPathReady returns whether the given JSONPath expression evaluates to value in the object
+func jsonPathReady(jp *jsonpath.JSONPath, obj unstructured.Unstructured, path, value string) (bool, string, error) {
+	var buf bytes.Buffer
+	if err := jp.Execute(&buf, obj.Object); err != nil {
+		return false, "", err
+	}
+	if buf.String() != value {
+		return false, fmt.Sprintf("%s is %q, expected %q", path, buf.String(), value), nil
+	}
+	return true, "", nil
+}
+
+func waitForDeployments(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) error {
+	return wait.PollUntilContextTimeout(jobCtx, time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
+		limiter.Wait(ctx)
+		deps, err := ClientSet.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return false, err
 		}
 		for _, dep := range deps.Items {
-			if *dep.Spec.Replicas != dep.Status.ReadyReplicas {
+			if ready, _ := deploymentReady(dep); !ready {
 				log.Debugf("Waiting for replicas from deployments in ns %s to be ready", ns)
 				return false, nil
 			}
@@ -90,15 +234,15 @@ func waitForDeployments(ns string, maxWaitTimeout time.Duration, limiter *rate.L
 	})
 }
 
-func waitForRS(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) {
-	wait.PollUntilContextTimeout(context.TODO(), time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
-		limiter.Wait(context.TODO())
-		rss, err := ClientSet.AppsV1().ReplicaSets(ns).List(context.TODO(), metav1.ListOptions{})
+func waitForRS(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) error {
+	return wait.PollUntilContextTimeout(jobCtx, time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
+		limiter.Wait(ctx)
+		rss, err := ClientSet.AppsV1().ReplicaSets(ns).List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return false, err
 		}
 		for _, rs := range rss.Items {
-			if *rs.Spec.Replicas != rs.Status.ReadyReplicas {
+			if ready, _ := replicaSetReady(rs); !ready {
 				log.Debugf("Waiting for replicas from replicaSets in ns %s to be ready", ns)
 				return false, nil
 			}
@@ -107,15 +251,15 @@ func waitForRS(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) {
 	})
 }
 
-func waitForStatefulSet(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) {
-	wait.PollUntilContextTimeout(context.TODO(), time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
-		limiter.Wait(context.TODO())
-		stss, err := ClientSet.AppsV1().StatefulSets(ns).List(context.TODO(), metav1.ListOptions{})
+func waitForStatefulSet(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) error {
+	return wait.PollUntilContextTimeout(jobCtx, time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
+		limiter.Wait(ctx)
+		stss, err := ClientSet.AppsV1().StatefulSets(ns).List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return false, err
 		}
 		for _, sts := range stss.Items {
-			if *sts.Spec.Replicas != sts.Status.ReadyReplicas {
+			if ready, _ := statefulSetReady(sts); !ready {
 				log.Debugf("Waiting for replicas from statefulSets in ns %s to be ready", ns)
 				return false, nil
 			}
@@ -124,26 +268,33 @@ func waitForStatefulSet(ns string, maxWaitTimeout time.Duration, limiter *rate.L
 	})
 }
 
-func waitForPVC(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) {
-	wait.PollUntilContextTimeout(context.TODO(), time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
-		limiter.Wait(context.TODO())
-		pvc, err := ClientSet.CoreV1().PersistentVolumeClaims(ns).List(context.TODO(), metav1.ListOptions{FieldSelector: "status.phase!=Bound"})
+// waitForPVC waits for the PersistentVolumeClaims of the namespace to be bound, the field selector narrows the list
+// down to the claims pvcReady rejects
+func waitForPVC(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) error {
+	return wait.PollUntilContextTimeout(jobCtx, time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
+		limiter.Wait(ctx)
+		pvcs, err := ClientSet.CoreV1().PersistentVolumeClaims(ns).List(ctx, metav1.ListOptions{FieldSelector: "status.phase!=Bound"})
 		if err != nil {
 			return false, err
 		}
-		return len(pvc.Items) == 0, nil
+		for _, pvc := range pvcs.Items {
+			if ready, _ := pvcReady(pvc); !ready {
+				return false, nil
+			}
+		}
+		return true, nil
 	})
 }
 
-func waitForRC(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) {
-	wait.PollUntilContextTimeout(context.TODO(), time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
-		limiter.Wait(context.TODO())
-		rcs, err := ClientSet.CoreV1().ReplicationControllers(ns).List(context.TODO(), metav1.ListOptions{})
+func waitForRC(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) error {
+	return wait.PollUntilContextTimeout(jobCtx, time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
+		limiter.Wait(ctx)
+		rcs, err := ClientSet.CoreV1().ReplicationControllers(ns).List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return false, err
 		}
 		for _, rc := range rcs.Items {
-			if *rc.Spec.Replicas != rc.Status.ReadyReplicas {
+			if ready, _ := replicationControllerReady(rc); !ready {
 				log.Debugf("Waiting for replicas from replicationControllers in ns %s to be ready", ns)
 				return false, nil
 			}
@@ -152,15 +303,15 @@ func waitForRC(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) {
 	})
 }
 
-func waitForDS(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) {
-	wait.PollUntilContextTimeout(context.TODO(), time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
-		limiter.Wait(context.TODO())
-		dss, err := ClientSet.AppsV1().DaemonSets(ns).List(context.TODO(), metav1.ListOptions{})
+func waitForDS(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) error {
+	return wait.PollUntilContextTimeout(jobCtx, time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
+		limiter.Wait(ctx)
+		dss, err := ClientSet.AppsV1().DaemonSets(ns).List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return false, err
 		}
 		for _, ds := range dss.Items {
-			if ds.Status.DesiredNumberScheduled != ds.Status.NumberReady {
+			if ready, _ := daemonSetReady(ds); !ready {
 				log.Debugf("Waiting for replicas from daemonsets in ns %s to be ready", ns)
 				return false, nil
 			}
@@ -169,142 +320,223 @@ func waitForDS(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) {
 	})
 }
 
-func waitForPod(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) {
-	wait.PollUntilContextTimeout(context.TODO(), time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
-		limiter.Wait(context.TODO())
-		pods, err := ClientSet.CoreV1().Pods(ns).List(context.TODO(), metav1.ListOptions{FieldSelector: "status.phase!=Running"})
+// waitForPod waits for the Pods of the namespace to be running, the field selector narrows the list down to the pods
+// podReady rejects
+func waitForPod(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) error {
+	return wait.PollUntilContextTimeout(jobCtx, time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
+		limiter.Wait(ctx)
+		pods, err := ClientSet.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{FieldSelector: "status.phase!=Running"})
 		if err != nil {
 			return false, err
 		}
-		return len(pods.Items) == 0, nil
+		for _, pod := range pods.Items {
+			if ready, _ := podReady(pod); !ready {
+				return false, nil
+			}
+		}
+		return true, nil
 	})
 }
 
-func waitForBuild(ns string, maxWaitTimeout time.Duration, expected int, limiter *rate.Limiter) {
-	buildStatus := []string{"New", "Pending", "Running"}
-	var build types.UnstructuredContent
+func waitForBuild(ns string, maxWaitTimeout time.Duration, expected int, limiter *rate.Limiter) error {
 	gvr := schema.GroupVersionResource{
 		Group:    types.OpenShiftBuildGroup,
 		Version:  types.OpenShiftBuildAPIVersion,
 		Resource: types.OpenShiftBuildResource,
 	}
-	wait.PollUntilContextTimeout(context.TODO(), time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
-		limiter.Wait(context.TODO())
-		builds, err := DynamicClient.Resource(gvr).Namespace(ns).List(context.TODO(), metav1.ListOptions{})
+	return wait.PollUntilContextTimeout(jobCtx, time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
+		limiter.Wait(ctx)
+		builds, err := DynamicClient.Resource(gvr).Namespace(ns).List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return false, err
 		}
 		if len(builds.Items) < expected {
 			log.Debugf("Waiting for Builds in ns %s to be completed", ns)
+			return false, nil
+		}
+		for _, build := range builds.Items {
+			if ready, _ := buildReady(build); !ready {
+				log.Debugf("Waiting for Builds in ns %s to be completed", ns)
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+// waitForJob waits for the Jobs of the namespace to reach their completions, failed Jobs don't recover by themselves
+// so the wait errors out as soon as one of them is observed failed
+func waitForJob(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) error {
+	return wait.PollUntilContextTimeout(jobCtx, time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
+		limiter.Wait(ctx)
+		jobs, err := ClientSet.BatchV1().Jobs(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
 			return false, err
 		}
-		for _, b := range builds.Items {
-			jsonBuild, err := b.MarshalJSON()
-			if err != nil {
-				log.Errorf("Error decoding Build object: %s", err)
+		for _, job := range jobs.Items {
+			if failed, reason := jobFailed(job); failed {
+				return false, fmt.Errorf("job %s failed: %s", job.Name, reason)
 			}
-			_ = json.Unmarshal(jsonBuild, &build)
-			for _, bs := range buildStatus {
-				if build.Status.Phase == "" || build.Status.Phase == bs {
-					log.Debugf("Waiting for Builds in ns %s to be completed", ns)
-					return false, err
-				}
+			if ready, _ := jobReady(job); !ready {
+				log.Debugf("Waiting for jobs in ns %s to be completed", ns)
+				return false, nil
 			}
 		}
 		return true, nil
 	})
 }
 
-func waitForJob(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) {
-	gvr := schema.GroupVersionResource{
-		Group:    "batch",
-		Version:  "v1",
-		Resource: "jobs",
+// jobCompleted returns true when the succeeded pods of the Job reach its completions. Jobs without completions,
+// like work queues, are completed when the Complete condition is set
+func jobCompleted(job batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobComplete && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	if job.Spec.Completions == nil {
+		return false
 	}
-	verifyCondition(gvr, ns, "Complete", maxWaitTimeout, limiter)
+	return job.Status.Succeeded >= *job.Spec.Completions
 }
 
-func waitForCondition(gvr schema.GroupVersionResource, ns, condition string, maxWaitTimeout time.Duration, limiter *rate.Limiter) {
-	verifyCondition(gvr, ns, condition, maxWaitTimeout, limiter)
+// jobFailed returns true and the failure reason when the Job has the Failed condition, i.e. its backoffLimit or activeDeadlineSeconds were exceeded
+func jobFailed(job batchv1.Job) (bool, string) {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			return true, fmt.Sprintf("%s: %s", c.Reason, c.Message)
+		}
+	}
+	return false, ""
+}
+
+// waitForConditions waits until all the given conditions are true in all the objects of the given resource
+func waitForConditions(gvr schema.GroupVersionResource, ns string, conditions []string, maxWaitTimeout time.Duration, limiter *rate.Limiter) error {
+	return verifyConditions(gvr, ns, conditions, maxWaitTimeout, limiter)
 }
 
-func verifyCondition(gvr schema.GroupVersionResource, ns, condition string, maxWaitTimeout time.Duration, limiter *rate.Limiter) {
-	var uObj types.UnstructuredContent
-	wait.PollUntilContextTimeout(context.TODO(), 10*time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
+// verifyConditions polls the objects of the given resource until all the given conditions are true in all of them,
+// the time each object took to meet them is recorded by the conditionLatency measurement
+func verifyConditions(gvr schema.GroupVersionResource, ns string, conditions []string, maxWaitTimeout time.Duration, limiter *rate.Limiter) error {
+	return wait.PollUntilContextTimeout(jobCtx, 10*time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
 		var objs *unstructured.UnstructuredList
-		limiter.Wait(context.TODO())
+		limiter.Wait(ctx)
 		if ns != "" {
-			objs, err = DynamicClient.Resource(gvr).Namespace(ns).List(context.TODO(), metav1.ListOptions{})
+			objs, err = DynamicClient.Resource(gvr).Namespace(ns).List(ctx, metav1.ListOptions{})
 		} else {
-			objs, err = DynamicClient.Resource(gvr).List(context.TODO(), metav1.ListOptions{})
+			objs, err = DynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
 		}
 		if err != nil {
 			return false, err
 		}
-	VERIFY:
+		allReady := true
 		for _, obj := range objs.Items {
-			jsonBuild, err := obj.MarshalJSON()
-			if err != nil {
-				log.Errorf("Error decoding object: %s", err)
-				return false, err
-			}
-			_ = json.Unmarshal(jsonBuild, &uObj)
-			for _, c := range uObj.Status.Conditions {
-				if c.Status == "True" && c.Type == condition {
-					continue VERIFY
+			readyTime, ready, _ := conditionsReady(obj, conditions)
+			if !ready {
+				if ns != "" {
+					log.Debugf("Waiting for %s in ns %s to be ready", gvr.Resource, ns)
+				} else {
+					log.Debugf("Waiting for %s to be ready", gvr.Resource)
 				}
+				allReady = false
+				continue
 			}
-			if ns != "" {
-				log.Debugf("Waiting for %s in ns %s to be ready", gvr.Resource, ns)
-			} else {
-				log.Debugf("Waiting for %s to be ready", gvr.Resource)
+			measurements.RecordConditionReady(obj.GetKind(), obj.GetNamespace(), obj.GetName(), string(obj.GetUID()), obj.GetCreationTimestamp().Time, readyTime)
+		}
+		return allReady, nil
+	})
+}
+
+// conditionsMet returns the conditions not true yet among the given ones, and the latest transition time of the true ones
+func conditionsMet(objConditions []types.Condition, conditions []string) (time.Time, []string) {
+	var readyTime time.Time
+	var missing []string
+	for _, condition := range conditions {
+		met := false
+		for _, c := range objConditions {
+			if c.Type == condition && c.Status == "True" {
+				met = true
+				if c.LastTransitionTime.Time.After(readyTime) {
+					readyTime = c.LastTransitionTime.Time
+				}
+				break
 			}
+		}
+		if !met {
+			missing = append(missing, condition)
+		}
+	}
+	// Conditions without transition time are accounted when they're observed
+	if readyTime.IsZero() {
+		readyTime = time.Now()
+	}
+	return readyTime, missing
+}
+
+// waitForJSONPath waits until the given JSONPath expression evaluates to value in all the objects of the given resource
+func waitForJSONPath(gvr schema.GroupVersionResource, ns, path, value string, maxWaitTimeout time.Duration, limiter *rate.Limiter) error {
+	jp, err := config.ParseWaitJSONPath(path)
+	if err != nil {
+		return err
+	}
+	return wait.PollUntilContextTimeout(jobCtx, 10*time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
+		var objs *unstructured.UnstructuredList
+		limiter.Wait(ctx)
+		if ns != "" {
+			objs, err = DynamicClient.Resource(gvr).Namespace(ns).List(ctx, metav1.ListOptions{})
+		} else {
+			objs, err = DynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+		}
+		if err != nil {
 			return false, err
 		}
+		for _, obj := range objs.Items {
+			ready, reason, err := jsonPathReady(jp, obj, path, value)
+			if err != nil {
+				return false, err
+			}
+			if !ready {
+				log.Debugf("Waiting for %s %s: %s", gvr.Resource, obj.GetName(), reason)
+				return false, nil
+			}
+		}
 		return true, nil
 	})
 }
 
-func waitForVM(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) {
+func waitForVM(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) error {
 	vmGVR := schema.GroupVersionResource{
 		Group:    types.KubevirtGroup,
 		Version:  types.KubevirtAPIVersion,
 		Resource: types.VirtualMachineResource,
 	}
-	verifyCondition(vmGVR, ns, "Ready", maxWaitTimeout, limiter)
+	return verifyConditions(vmGVR, ns, []string{"Ready"}, maxWaitTimeout, limiter)
 }
 
-func waitForVMI(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) {
+func waitForVMI(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) error {
 	vmiGVR := schema.GroupVersionResource{
 		Group:    types.KubevirtGroup,
 		Version:  types.KubevirtAPIVersion,
 		Resource: types.VirtualMachineInstanceResource,
 	}
-	verifyCondition(vmiGVR, ns, "Ready", maxWaitTimeout, limiter)
+	return verifyConditions(vmiGVR, ns, []string{"Ready"}, maxWaitTimeout, limiter)
 }
 
-func waitForVMIRS(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) {
-	var rs types.UnstructuredContent
+func waitForVMIRS(ns string, maxWaitTimeout time.Duration, limiter *rate.Limiter) error {
 	vmiGVRRS := schema.GroupVersionResource{
 		Group:    types.KubevirtGroup,
 		Version:  types.KubevirtAPIVersion,
 		Resource: types.VirtualMachineInstanceReplicaSetResource,
 	}
-	wait.PollUntilContextTimeout(context.TODO(), 10*time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
-		limiter.Wait(context.TODO())
-		objs, err := DynamicClient.Resource(vmiGVRRS).Namespace(ns).List(context.TODO(), metav1.ListOptions{})
+	return wait.PollUntilContextTimeout(jobCtx, 10*time.Second, maxWaitTimeout, true, func(ctx context.Context) (done bool, err error) {
+		limiter.Wait(ctx)
+		objs, err := DynamicClient.Resource(vmiGVRRS).Namespace(ns).List(ctx, metav1.ListOptions{})
 		if err != nil {
 			log.Debugf("VMIRS error %v", err)
 			return false, err
 		}
 		for _, obj := range objs.Items {
-			jsonBuild, err := obj.MarshalJSON()
-			if err != nil {
-				log.Errorf("Error decoding VMIRS object: %s", err)
-				return false, err
-			}
-			_ = json.Unmarshal(jsonBuild, &rs)
-			if rs.Spec.Replicas != rs.Status.ReadyReplicas {
+			if ready, _ := vmiReplicaSetReady(obj); !ready {
 				log.Debugf("Waiting for replicas from VMIRS in ns %s to be running", ns)
 				return false, nil
 			}
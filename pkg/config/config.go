@@ -19,8 +19,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math"
+	"net"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/cloud-bulldozer/go-commons/indexers"
@@ -30,37 +34,68 @@ import (
 
 	uid "github.com/satori/go.uuid"
 	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/client-go/util/jsonpath"
 )
 
-var configSpec = Spec{
-	GlobalConfig: GlobalConfig{
-		RUNID:          uid.NewV4().String(),
-		GC:             false,
-		GCMetrics:      false,
-		GCTimeout:      1 * time.Hour,
-		RequestTimeout: 15 * time.Second,
-		Measurements:   []mtypes.Measurement{},
-		IndexerConfig: indexers.IndexerConfig{
-			InsecureSkipVerify: false,
-			MetricsDirectory:   "collected-metrics",
-			TarballName:        "kube-burner-metrics.tgz",
+var configSpec = defaultSpec()
+
+// kubeConfigPath and kubeConfigContext override the default kubeconfig resolution when set
+var kubeConfigPath, kubeConfigContext string
+
+// scaleFactor multiplies the jobIterations of every job, it's exposed to the configuration template as .ScaleFactor
+var scaleFactor float64 = 1
+
+// defaultSpec returns a configuration with the default values
+func defaultSpec() Spec {
+	return Spec{
+		GlobalConfig: GlobalConfig{
+			RUNID:               uid.NewV4().String(),
+			GC:                  false,
+			GCMetrics:           false,
+			CleanupOnFailure:    true,
+			GCTimeout:           1 * time.Hour,
+			DeletionConcurrency: 10,
+			RequestTimeout:      15 * time.Second,
+			ClientPoolSize:      1,
+			Trace: TraceConfig{
+				SampleRate: 1,
+				MaxEvents:  100000,
+			},
+			LeakCheck: LeakCheck{
+				Exclude: []string{"events", "events.events.k8s.io"},
+			},
+			Measurements: []mtypes.Measurement{},
+			IndexerConfig: IndexerConfig{
+				IndexerConfig: indexers.IndexerConfig{
+					InsecureSkipVerify: false,
+					MetricsDirectory:   "collected-metrics",
+					TarballName:        "kube-burner-metrics.tgz",
+				},
+			},
+			WaitWhenFinished: false,
 		},
-		WaitWhenFinished: false,
-	},
+	}
 }
 
 // UnmarshalYAML implements Unmarshaller to customize object defaults
 func (o *Object) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	type rawObject Object
 	object := rawObject{
-		Wait: true,
+		Wait:            true,
+		ObjectOperation: CreateOperation,
+		FieldManager:    "kube-burner",
 	}
 	if err := unmarshal(&object); err != nil {
 		return err
@@ -69,6 +104,20 @@ func (o *Object) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+// UnmarshalYAML implements Unmarshaller to customize cleanup verification defaults
+func (c *CleanupVerification) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type rawCleanupVerification CleanupVerification
+	verification := rawCleanupVerification{
+		Timeout:  5 * time.Minute,
+		Interval: 10 * time.Second,
+	}
+	if err := unmarshal(&verification); err != nil {
+		return err
+	}
+	*c = CleanupVerification(verification)
+	return nil
+}
+
 // UnmarshalYAML implements Unmarshaller to customize job defaults
 func (j *Job) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	type rawJob Job
@@ -90,6 +139,12 @@ func (j *Job) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		ChurnDuration:          1 * time.Hour,
 		ChurnDelay:             5 * time.Minute,
 		ChurnDeletionStrategy:  "default",
+		BaselineDuration:       5 * time.Minute,
+		MissingAPIPolicy:       MissingAPISkip,
+		FailureEvents:          10,
+		RetryBackoff:           1 * time.Second,
+		RetryBackoffCap:        30 * time.Second,
+		MaxRetries:             8,
 	}
 
 	if err := unmarshal(&raw); err != nil {
@@ -105,16 +160,26 @@ func (j *Job) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
-// Parse parses a configuration file
-func Parse(uuid string, f io.Reader) (Spec, error) {
-	cfg, err := io.ReadAll(f)
-	if err != nil {
-		return configSpec, fmt.Errorf("error reading configuration file: %s", err)
-	}
-	renderedCfg, err := util.RenderTemplate(cfg, util.EnvToMap(), util.MissingKeyError)
-	if err != nil {
-		return configSpec, fmt.Errorf("error rendering configuration template: %s", err)
+// Parse parses the given configuration files. The jobs of all of them are concatenated in order, while the global
+// settings of the later files override the ones of the previous files
+func Parse(uuid string, files ...io.Reader) (Spec, error) {
+	// Start from the defaults, so configurations parsed previously in the same process don't leak into this one
+	configSpec = defaultSpec()
+	var renderedCfgs [][]byte
+	templateData := util.EnvToMap()
+	templateData["ScaleFactor"] = scaleFactor
+	for _, f := range files {
+		cfg, err := io.ReadAll(f)
+		if err != nil {
+			return configSpec, fmt.Errorf("error reading configuration file: %s", err)
+		}
+		renderedCfg, err := util.RenderTemplate(cfg, templateData, util.MissingKeyError)
+		if err != nil {
+			return configSpec, fmt.Errorf("error rendering configuration template: %s", err)
+		}
+		renderedCfgs = append(renderedCfgs, renderedCfg)
 	}
+	renderedCfg, err := mergeConfigs(renderedCfgs)
 	if err != nil {
 		return configSpec, err
 	}
@@ -124,44 +189,493 @@ func Parse(uuid string, f io.Reader) (Spec, error) {
 	if err = yamlDec.Decode(&configSpec); err != nil {
 		return configSpec, fmt.Errorf("error decoding configuration file: %s", err)
 	}
+	// Validation errors are collected, so all of them are reported at once
+	var errs []error
 	if err := jobIsDuped(); err != nil {
-		return configSpec, err
+		errs = append(errs, err)
 	}
 	if err := validateDNS1123(); err != nil {
-		return configSpec, err
+		errs = append(errs, err)
+	}
+	for label := range configSpec.GlobalConfig.InjectLabels {
+		if strings.HasPrefix(label, "kube-burner-") {
+			errs = append(errs, fmt.Errorf("injectLabels can't override label %s", label))
+		}
 	}
 	for i, job := range configSpec.Jobs {
+		if job.JobType == CreationJob && len(job.Objects) > 0 && patchOperationOnly(job.Objects) {
+			job.JobType = PatchJob
+			configSpec.Jobs[i].JobType = PatchJob
+		}
+		if scaleFactor != 1 && job.JobIterations > 0 {
+			job.JobIterations = scaleIterations(job.Name, job.JobIterations)
+			configSpec.Jobs[i].JobIterations = job.JobIterations
+		}
+		if job.NamespacePattern != "" {
+			if err := validateNamespacePattern(job, uuid, util.TemplateEngine(configSpec.GlobalConfig.TemplateEngine)); err != nil {
+				errs = append(errs, fmt.Errorf("job %s: %s", job.Name, err))
+			}
+		}
+		if len(job.ReuseNamespaces) > 0 {
+			if err := validateReuseNamespaces(job); err != nil {
+				errs = append(errs, fmt.Errorf("job %s: %s", job.Name, err))
+			}
+		}
+		// Jobs not setting their own client rate limits inherit the global ones
+		if job.QPS == 0 {
+			configSpec.Jobs[i].QPS = configSpec.GlobalConfig.QPS
+		}
+		if job.Burst == 0 {
+			configSpec.Jobs[i].Burst = configSpec.GlobalConfig.Burst
+		}
 		if len(job.Namespace) > 62 {
 			log.Warnf("Namespace %s length has > 62 characters, truncating it", job.Namespace)
 			configSpec.Jobs[i].Namespace = job.Namespace[:57]
 		}
 		if !job.NamespacedIterations && job.Churn {
-			log.Fatal("Cannot have Churn enabled without Namespaced Iterations also enabled")
+			errs = append(errs, fmt.Errorf("job %s: cannot have Churn enabled without Namespaced Iterations also enabled", job.Name))
+		}
+		if job.Churn && (job.ChurnPercent < 1 || job.ChurnPercent > 100 || job.ChurnDuration <= 0 || job.ChurnDelay < 0) {
+			errs = append(errs, fmt.Errorf("job %s: churnPercent must be between 1 and 100, churnDuration greater than 0 and churnDelay greater or equal than 0", job.Name))
+		}
+		// The injected probe runs in the container images of the workload, so there's no command fitting them all
+		if job.ReadinessDelay > 0 && len(job.ReadinessProbeCommand) == 0 {
+			errs = append(errs, fmt.Errorf("job %s: readinessDelay requires readinessProbeCommand, run by the readiness probe injected in containers without one", job.Name))
+		}
+		if sweep := job.ConcurrencySweep; sweep != nil {
+			if job.JobType != CreationJob || job.Churn {
+				errs = append(errs, fmt.Errorf("job %s: concurrencySweep is only supported by creation jobs without churn", job.Name))
+			}
+			if len(sweep.Levels) == 0 || sweep.Iterations < 1 {
+				errs = append(errs, fmt.Errorf("job %s: concurrencySweep requires at least one level and iterations > 0", job.Name))
+			}
+			for _, level := range sweep.Levels {
+				if level < 1 {
+					errs = append(errs, fmt.Errorf("job %s: concurrencySweep levels must be greater than 0", job.Name))
+				}
+			}
+			// Each level runs its own set of iterations
+			job.JobIterations = len(sweep.Levels) * sweep.Iterations
+			configSpec.Jobs[i].JobIterations = job.JobIterations
+		}
+		for _, variant := range job.NamespaceLabelVariants {
+			if variant.Weight < 1 {
+				errs = append(errs, fmt.Errorf("job %s: namespace label variant weights must be greater than 0", job.Name))
+			}
+			for label := range variant.Labels {
+				if strings.HasPrefix(label, "kube-burner-") {
+					errs = append(errs, fmt.Errorf("job %s: namespace label variants can't override label %s", job.Name, label))
+				}
+			}
+		}
+		if job.Rate < 0 || (job.Rate > 0 && job.JobType != CreationJob) {
+			errs = append(errs, fmt.Errorf("job %s: rate must be greater than 0 and is only supported by creation jobs", job.Name))
+		}
+		if err := validateRatePlan(job); err != nil {
+			errs = append(errs, fmt.Errorf("job %s: %s", job.Name, err))
+		}
+		if job.TimeoutWeight < 0 || job.TimeoutWeight > 1 {
+			errs = append(errs, fmt.Errorf("job %s: timeoutWeight must be between 0 and 1", job.Name))
+		}
+		if err := validateSelection(job); err != nil {
+			errs = append(errs, fmt.Errorf("job %s: %s", job.Name, err))
+		}
+		if job.ObjectOrdering != "" && job.ObjectOrdering != OrderingSequential && job.ObjectOrdering != OrderingShuffled {
+			errs = append(errs, fmt.Errorf("job %s: objectOrdering must be %s or %s", job.Name, OrderingSequential, OrderingShuffled))
+		}
+		// Objects created concurrently can't keep an order
+		if job.ObjectOrdering != "" && job.ObjectConcurrency > 1 {
+			errs = append(errs, fmt.Errorf("job %s: objectOrdering and objectConcurrency are mutually exclusive", job.Name))
+		}
+		if job.ObjectOrdering == OrderingShuffled && job.OrderingSeed == nil {
+			var seed int64
+			configSpec.Jobs[i].OrderingSeed = &seed
+		}
+		if err := validateDependencies(job); err != nil {
+			errs = append(errs, fmt.Errorf("job %s: %s", job.Name, err))
+		}
+		if job.ObjectConcurrency < 0 {
+			errs = append(errs, fmt.Errorf("job %s: objectConcurrency must be greater or equal than 0", job.Name))
 		}
 		if job.JobIterations < 1 && job.JobType == CreationJob {
-			log.Fatalf("Job %s has < 1 iterations", job.Name)
+			errs = append(errs, fmt.Errorf("job %s has < 1 iterations", job.Name))
+		}
+		if podLogs := job.PodLogs; podLogs != nil {
+			if job.JobType != CreationJob {
+				errs = append(errs, fmt.Errorf("job %s: podLogs is only supported by creation jobs", job.Name))
+			}
+			if podLogs.Sample < 1 {
+				errs = append(errs, fmt.Errorf("job %s: podLogs sample must be greater than 0", job.Name))
+			}
+			if podLogs.Selection == "" {
+				podLogs.Selection = PodLogsFirst
+			}
+			if podLogs.Selection != PodLogsFirst && podLogs.Selection != PodLogsRandom {
+				errs = append(errs, fmt.Errorf("job %s: podLogs selection must be %s or %s", job.Name, PodLogsFirst, PodLogsRandom))
+			}
+		}
+		if check := job.PreJobCheck; check != nil {
+			if check.Expr == "" {
+				errs = append(errs, fmt.Errorf("job %s: preJobCheck expr not defined", job.Name))
+			}
+			if check.Interval == 0 {
+				check.Interval = 10 * time.Second
+			}
+			if check.Timeout == 0 {
+				check.Timeout = 10 * time.Minute
+			}
+			if check.Interval < 0 || check.Timeout < 0 {
+				errs = append(errs, fmt.Errorf("job %s: preJobCheck interval and timeout must be greater than 0", job.Name))
+			}
+			if check.OnError == "" {
+				check.OnError = PreJobCheckFail
+			}
+			if check.OnError != PreJobCheckFail && check.OnError != PreJobCheckWarn {
+				errs = append(errs, fmt.Errorf("job %s: preJobCheck onError must be %s or %s", job.Name, PreJobCheckFail, PreJobCheckWarn))
+			}
+		}
+		if job.NamespaceCreationDelay < 0 || job.NamespaceCreationJitter < 0 {
+			errs = append(errs, fmt.Errorf("job %s: namespaceCreationDelay and namespaceCreationJitter must be greater or equal than 0", job.Name))
+		}
+		if job.ChurnTeardownWaveSize < 0 || job.ChurnTeardownWaveJitter < 0 {
+			errs = append(errs, fmt.Errorf("job %s: churnTeardownWaveSize and churnTeardownWaveJitter must be greater or equal than 0", job.Name))
+		}
+		if job.RetryBackoff <= 0 || job.MaxRetries < 0 {
+			errs = append(errs, fmt.Errorf("job %s: retryBackoff must be greater than 0 and maxRetries greater or equal than 0", job.Name))
+		}
+		if job.RetryBackoffCap < job.RetryBackoff {
+			errs = append(errs, fmt.Errorf("job %s: retryBackoffCap must be greater or equal than retryBackoff", job.Name))
+		}
+		if job.DeletionTimeout < 0 {
+			errs = append(errs, fmt.Errorf("job %s: deletionTimeout must be greater or equal than 0", job.Name))
+		}
+		if job.FailureEvents < 0 {
+			errs = append(errs, fmt.Errorf("job %s: failureEvents must be greater or equal than 0", job.Name))
+		}
+		if job.MissingAPIPolicy != MissingAPISkip && job.MissingAPIPolicy != MissingAPIError {
+			errs = append(errs, fmt.Errorf("job %s: missingAPIPolicy must be %s or %s", job.Name, MissingAPISkip, MissingAPIError))
 		}
-		if job.JobType == DeletionJob {
+		requiredAPIs := append([]string{}, job.RequiresAPI...)
+		for _, o := range job.Objects {
+			if err := validateResourceSweep(o.ResourceSweep); err != nil {
+				errs = append(errs, fmt.Errorf("job %s: %s", job.Name, err))
+			}
+			if job.JobType == PatchJob {
+				if o.ObjectOperation != CreateOperation && o.ObjectOperation != PatchOperation {
+					errs = append(errs, fmt.Errorf("job %s: objectOperation of patch jobs must be %s", job.Name, PatchOperation))
+				}
+			} else if o.ObjectOperation == PatchOperation {
+				errs = append(errs, fmt.Errorf("job %s: objects with objectOperation %s can't be mixed with %s or %s ones", job.Name, PatchOperation, CreateOperation, ApplyOperation))
+			} else if o.ObjectOperation != CreateOperation && o.ObjectOperation != ApplyOperation {
+				errs = append(errs, fmt.Errorf("job %s: objectOperation must be %s, %s or %s", job.Name, CreateOperation, ApplyOperation, PatchOperation))
+			}
+			if o.FieldSelector != "" {
+				if job.JobType != DeletionJob {
+					errs = append(errs, fmt.Errorf("job %s: fieldSelector is only supported by %s jobs", job.Name, DeletionJob))
+				}
+				if _, err := fields.ParseSelector(o.FieldSelector); err != nil {
+					errs = append(errs, fmt.Errorf("job %s: invalid fieldSelector %s: %s", job.Name, o.FieldSelector, err))
+				}
+			}
+			if o.MaxWaitTimeout < 0 {
+				errs = append(errs, fmt.Errorf("job %s: object maxWaitTimeout must be greater or equal than 0", job.Name))
+			}
+			if err := validateWaitOptions(o.WaitOptions); err != nil {
+				errs = append(errs, fmt.Errorf("job %s: %s", job.Name, err))
+			}
+			if strings.HasPrefix(o.ObjectTemplate, util.GitPrefix) {
+				if _, err := util.ParseGitReference(o.ObjectTemplate); err != nil {
+					errs = append(errs, fmt.Errorf("job %s: %s", job.Name, err))
+				}
+			}
+			requiredAPIs = append(requiredAPIs, o.RequiresAPI...)
+		}
+		for _, api := range requiredAPIs {
+			if _, err := ParseRequiredAPI(api); err != nil {
+				errs = append(errs, fmt.Errorf("job %s: %s", job.Name, err))
+			}
+		}
+		switch job.JobType {
+		case CreationJob, DeletionJob, PatchJob, BaselineJob, PauseJob:
+		default:
+			errs = append(errs, fmt.Errorf("job %s: unknown jobType %s", job.Name, job.JobType))
+		}
+		if job.JobType == PauseJob && (job.PauseDuration < 0 || job.Jitter < 0) {
+			errs = append(errs, fmt.Errorf("job %s: pauseDuration and jitter must be greater or equal than 0", job.Name))
+		}
+		if job.JobType == DeletionJob || job.JobType == BaselineJob || job.JobType == PauseJob {
 			configSpec.Jobs[i].PreLoadImages = false
 		}
 	}
+	if err := validateTimeoutWeights(configSpec.Jobs); err != nil {
+		errs = append(errs, err)
+	}
+	if configSpec.GlobalConfig.ScrapeOffsetBefore < 0 || configSpec.GlobalConfig.ScrapeOffsetAfter < 0 {
+		errs = append(errs, fmt.Errorf("scrapeOffsetBefore and scrapeOffsetAfter must be greater or equal than 0"))
+	}
+	if configSpec.GlobalConfig.DeletionConcurrency < 1 {
+		errs = append(errs, fmt.Errorf("deletionConcurrency must be greater than 0"))
+	}
+	if configSpec.GlobalConfig.ClientPoolSize < 1 {
+		errs = append(errs, fmt.Errorf("clientPoolSize must be greater than 0"))
+	}
+	if trace := configSpec.GlobalConfig.Trace; trace.SampleRate <= 0 || trace.SampleRate > 1 {
+		errs = append(errs, fmt.Errorf("trace sampleRate must be greater than 0 and lower or equal than 1"))
+	}
+	if leakCheck := configSpec.GlobalConfig.LeakCheck; leakCheck.Enabled {
+		if !configSpec.GlobalConfig.GC {
+			errs = append(errs, fmt.Errorf("leakCheck requires gc to be enabled"))
+		}
+		if leakCheck.Tolerance < 0 {
+			errs = append(errs, fmt.Errorf("leakCheck tolerance must be greater or equal than 0"))
+		}
+	}
+	for i, verification := range configSpec.GlobalConfig.CleanupVerifications {
+		if verification.Command == "" {
+			errs = append(errs, fmt.Errorf("cleanup verification %d has no command", i))
+		}
+		if verification.Name == "" {
+			configSpec.GlobalConfig.CleanupVerifications[i].Name = verification.Command
+		}
+	}
+	switch util.TemplateEngine(configSpec.GlobalConfig.TemplateEngine) {
+	case "":
+		configSpec.GlobalConfig.TemplateEngine = string(util.SprigTemplateEngine)
+	case util.SprigTemplateEngine, util.GoTemplateEngine:
+	default:
+		errs = append(errs, fmt.Errorf("templateEngine must be %s or %s", util.SprigTemplateEngine, util.GoTemplateEngine))
+	}
+	if configSpec.GlobalConfig.MaxObjects < 0 {
+		errs = append(errs, fmt.Errorf("maxObjects must be greater or equal than 0"))
+	}
+	configSpec.TotalObjects = totalObjects(configSpec.Jobs)
+	log.Infof("The configuration creates %d objects in total", configSpec.TotalObjects)
+	if err := loadTemplateValues(&configSpec.GlobalConfig); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateIndexers(&configSpec.GlobalConfig); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return configSpec, utilerrors.NewAggregate(errs)
+	}
 	configSpec.GlobalConfig.UUID = uuid
 	if configSpec.GlobalConfig.IndexerConfig.MetricsDirectory == "collected-metrics" {
 		configSpec.GlobalConfig.IndexerConfig.MetricsDirectory += "-" + uuid
 	}
+	for i, indexerConfig := range configSpec.GlobalConfig.Indexers {
+		if indexerConfig.MetricsDirectory == "collected-metrics" {
+			configSpec.GlobalConfig.Indexers[i].MetricsDirectory += "-" + uuid
+		}
+	}
 	return configSpec, nil
 }
 
+// patchOperationOnly returns true when all the objects use the patch objectOperation
+func patchOperationOnly(objects []Object) bool {
+	for _, o := range objects {
+		if o.ObjectOperation != PatchOperation {
+			return false
+		}
+	}
+	return true
+}
+
+// validateTimeoutWeights validates the timeout budget of the jobs doesn't exceed the overall timeout, leaving part of it
+// to the jobs without timeoutWeight
+func validateTimeoutWeights(jobs []Job) error {
+	var weights float64
+	var unweighted int
+	for _, job := range jobs {
+		if job.TimeoutWeight == 0 {
+			unweighted++
+		}
+		weights += job.TimeoutWeight
+	}
+	if weights > 1 {
+		return fmt.Errorf("the timeoutWeight of the jobs adds up to %v, it can't be greater than 1", weights)
+	}
+	if weights == 1 && unweighted > 0 {
+		return fmt.Errorf("the timeoutWeight of the jobs adds up to 1, leaving no timeout to the %d jobs without timeoutWeight", unweighted)
+	}
+	return nil
+}
+
+// validateRatePlan validates the segments of the creation rate plan
+func validateRatePlan(job Job) error {
+	if len(job.RatePlan) == 0 {
+		return nil
+	}
+	if job.JobType != CreationJob {
+		return fmt.Errorf("ratePlan is only supported by creation jobs")
+	}
+	if job.Rate > 0 {
+		return fmt.Errorf("rate and ratePlan are mutually exclusive")
+	}
+	for i, segment := range job.RatePlan {
+		if segment.Rate < 0 {
+			return fmt.Errorf("ratePlan segment %d: rate must be greater or equal than 0", i)
+		}
+		if segment.Count < 0 || (segment.Count == 0 && i != len(job.RatePlan)-1) {
+			return fmt.Errorf("ratePlan segment %d: count must be greater than 0, only the last segment can leave it unset", i)
+		}
+	}
+	return nil
+}
+
+// validateSelection validates the weights of the objects of jobs using weighted selection
+func validateSelection(job Job) error {
+	switch job.Selection {
+	case "", SelectionAll:
+		return nil
+	case SelectionWeighted:
+	default:
+		return fmt.Errorf("selection must be %s or %s", SelectionAll, SelectionWeighted)
+	}
+	var totalWeight int
+	for _, o := range job.Objects {
+		if o.Weight < 0 {
+			return fmt.Errorf("object weights must be greater or equal than 0")
+		}
+		if !o.RunOnce {
+			totalWeight += o.Weight
+		}
+	}
+	if totalWeight == 0 {
+		return fmt.Errorf("weighted selection requires at least one object with weight greater than 0")
+	}
+	return nil
+}
+
+// validateDependencies checks the object names are unique and the dependencies among the objects of the job form no cycle
+func validateDependencies(job Job) error {
+	names := make(map[string]bool)
+	var dependencies bool
+	for _, o := range job.Objects {
+		if o.Name != "" {
+			if names[o.Name] {
+				return fmt.Errorf("duplicated object name %s", o.Name)
+			}
+			names[o.Name] = true
+		}
+		if len(o.DependsOn) > 0 {
+			dependencies = true
+		} else if o.WaitForDependencies {
+			return fmt.Errorf("object %s: waitForDependencies requires dependsOn", o.ObjectTemplate)
+		}
+	}
+	if !dependencies {
+		return nil
+	}
+	if job.JobType != CreationJob {
+		return fmt.Errorf("dependsOn is only supported by create jobs")
+	}
+	_, err := DependencyLevels(job.Objects)
+	return err
+}
+
+// DependencyLevels returns the dependency level of each object: objects without dependencies have level 0, and
+// the rest have the level following the highest level among their dependencies. An error is returned when an
+// object depends on an unknown object or the dependencies form a cycle
+func DependencyLevels(objects []Object) ([]int, error) {
+	indexes := make(map[string]int)
+	for i, o := range objects {
+		if o.Name != "" {
+			indexes[o.Name] = i
+		}
+	}
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	levels := make([]int, len(objects))
+	state := make([]int, len(objects))
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			// The cycle starts at the first occurrence of the object in the path
+			for len(path) > 0 && path[0] != objects[i].Name {
+				path = path[1:]
+			}
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, objects[i].Name), " -> "))
+		}
+		state[i] = visiting
+		for _, dependency := range objects[i].DependsOn {
+			j, exists := indexes[dependency]
+			if !exists {
+				return fmt.Errorf("object %s depends on unknown object %s", objects[i].ObjectTemplate, dependency)
+			}
+			if err := visit(j, append(path, objects[i].Name)); err != nil {
+				return err
+			}
+			if levels[j]+1 > levels[i] {
+				levels[i] = levels[j] + 1
+			}
+		}
+		state[i] = visited
+		return nil
+	}
+	for i := range objects {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return levels, nil
+}
+
+// validateIndexers sets the defaults of the indexers list, when indexerConfig isn't set the first indexer of the list takes its place
+func validateIndexers(globalConfig *GlobalConfig) error {
+	for i, indexerConfig := range globalConfig.Indexers {
+		if indexerConfig.Type == "" {
+			return fmt.Errorf("indexer %d: type not defined", i)
+		}
+		if indexerConfig.MetricsDirectory == "" {
+			globalConfig.Indexers[i].MetricsDirectory = "collected-metrics"
+		}
+		if indexerConfig.TarballName == "" {
+			globalConfig.Indexers[i].TarballName = "kube-burner-metrics.tgz"
+		}
+	}
+	if globalConfig.IndexerConfig.Type == "" && len(globalConfig.Indexers) > 0 {
+		globalConfig.IndexerConfig = globalConfig.Indexers[0]
+		globalConfig.Indexers = globalConfig.Indexers[1:]
+	}
+	return nil
+}
+
+// loadTemplateValues loads the values file and the allowlisted environment variables exposed to the object templates
+func loadTemplateValues(globalConfig *GlobalConfig) error {
+	globalConfig.Values = make(map[string]interface{})
+	globalConfig.Env = make(map[string]interface{})
+	if globalConfig.ValuesFile != "" {
+		f, err := util.ReadConfig(globalConfig.ValuesFile)
+		if err != nil {
+			return fmt.Errorf("error reading values file %s: %s", globalConfig.ValuesFile, err)
+		}
+		if err := yaml.NewDecoder(f).Decode(&globalConfig.Values); err != nil && err != io.EOF {
+			return fmt.Errorf("error decoding values file %s: %s", globalConfig.ValuesFile, err)
+		}
+	}
+	for _, envVar := range globalConfig.EnvVars {
+		// Unset variables are left out, so referencing them fails the rendering
+		if value, ok := os.LookupEnv(envVar); ok {
+			globalConfig.Env[envVar] = value
+		} else {
+			log.Warnf("Environment variable %s is not set", envVar)
+		}
+	}
+	return nil
+}
+
 // FetchConfigMap Fetchs the specified configmap and looks for config.yml, metrics.yml and alerts.yml files
 func FetchConfigMap(configMap, namespace string) (string, string, error) {
 	log.Infof("Fetching configmap %s", configMap)
-	var kubeconfig, metricProfile, alertProfile string
-	if os.Getenv("KUBECONFIG") != "" {
-		kubeconfig = os.Getenv("KUBECONFIG")
-	} else if _, err := os.Stat(filepath.Join(os.Getenv("HOME"), ".kube", "config")); kubeconfig == "" && !os.IsNotExist(err) {
-		kubeconfig = filepath.Join(os.Getenv("HOME"), ".kube", "config")
-	}
-	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	var metricProfile, alertProfile string
+	restConfig, err := buildConfig()
 	if err != nil {
 		return metricProfile, alertProfile, err
 	}
@@ -191,7 +705,8 @@ func validateDNS1123() error {
 		if errs := validation.IsDNS1123Subdomain(job.Name); len(errs) > 0 {
 			return fmt.Errorf("Job %s name validation error: %s", job.Name, fmt.Sprint(errs))
 		}
-		if job.JobType == CreationJob {
+		// Namespace patterns are validated apart
+		if job.JobType == CreationJob && len(job.ReuseNamespaces) == 0 && (job.NamespacePattern == "" || !job.NamespacedIterations) {
 			if errs := validation.IsDNS1123Subdomain(job.Namespace); job.JobType == CreationJob && len(errs) > 0 {
 				return fmt.Errorf("Namespace %s name validation error: %s", job.Namespace, errs)
 			}
@@ -202,15 +717,7 @@ func validateDNS1123() error {
 
 // GetRestConfig returns restConfig with the given QPS and burst
 func GetClientSet(QPS float32, burst int) (*kubernetes.Clientset, *rest.Config, error) {
-	var err error
-	var restConfig *rest.Config
-	var kubeconfig string
-	if os.Getenv("KUBECONFIG") != "" {
-		kubeconfig = os.Getenv("KUBECONFIG")
-	} else if _, err := os.Stat(filepath.Join(os.Getenv("HOME"), ".kube", "config")); kubeconfig == "" && !os.IsNotExist(err) {
-		kubeconfig = filepath.Join(os.Getenv("HOME"), ".kube", "config")
-	}
-	restConfig, err = buildConfig(kubeconfig)
+	restConfig, err := buildConfig()
 	if err != nil {
 		return &kubernetes.Clientset{}, restConfig, err
 	}
@@ -219,16 +726,327 @@ func GetClientSet(QPS float32, burst int) (*kubernetes.Clientset, *rest.Config,
 	return kubernetes.NewForConfigOrDie(restConfig), restConfig, nil
 }
 
-func buildConfig(kubeconfigPath string) (*rest.Config, error) {
-	if kubeconfigPath == "" {
-		kubeconfig, err := rest.InClusterConfig()
+// GetClientPool returns the clientSet built by GetClientSet along with a pool of size restConfigs.
+// Each restConfig of the pool uses its own connections to the API server, while all of them share
+// a rate limiter, so the pool as a whole honors the given QPS and burst
+func GetClientPool(QPS float32, burst, size int) (*kubernetes.Clientset, []*rest.Config, error) {
+	clientSet, restConfig, err := GetClientSet(QPS, burst)
+	if err != nil || size <= 1 {
+		return clientSet, []*rest.Config{restConfig}, err
+	}
+	var restConfigs []*rest.Config
+	rateLimiter := flowcontrol.NewTokenBucketRateLimiter(QPS, burst)
+	for i := 0; i < size; i++ {
+		poolConfig := rest.CopyConfig(restConfig)
+		poolConfig.RateLimiter = rateLimiter
+		// client-go shares transports across clients with the same configuration,
+		// configuring a custom dialer prevents it and thus connections are not reused across clients
+		poolConfig.Dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+		restConfigs = append(restConfigs, poolConfig)
+	}
+	return clientSet, restConfigs, nil
+}
+
+// SetScaleFactor sets the factor the jobIterations of every job are multiplied by in the configurations parsed afterwards
+func SetScaleFactor(factor float64) error {
+	if factor <= 0 {
+		return fmt.Errorf("scale factor must be greater than 0")
+	}
+	scaleFactor = factor
+	return nil
+}
+
+// scaleIterations multiplies the given iterations by the scale factor, rounding down non-integer results
+func scaleIterations(jobName string, iterations int) int {
+	scaled := float64(iterations) * scaleFactor
+	effective := int(math.Floor(scaled))
+	if float64(effective) != scaled {
+		log.Warnf("Job %s: %d jobIterations scaled by %v is not an integer, rounding down to %d", jobName, iterations, scaleFactor, effective)
+	}
+	log.Infof("Job %s: jobIterations scaled by %v from %d to %d", jobName, scaleFactor, iterations, effective)
+	return effective
+}
+
+// mergeConfigs merges the given rendered configuration files: the jobs are concatenated in order, and the global
+// settings of each file are merged key by key into the ones of the previous files, each override of a different value is reported
+func mergeConfigs(cfgs [][]byte) ([]byte, error) {
+	if len(cfgs) == 1 {
+		return cfgs[0], nil
+	}
+	merged := make(map[string]interface{})
+	for i, cfg := range cfgs {
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal(cfg, &doc); err != nil {
+			return nil, fmt.Errorf("error decoding configuration file %d: %s", i+1, err)
+		}
+		for key, value := range doc {
+			switch key {
+			case "jobs":
+				jobs, ok := value.([]interface{})
+				if !ok && value != nil {
+					return nil, fmt.Errorf("configuration file %d: jobs must be a list", i+1)
+				}
+				mergedJobs, _ := merged[key].([]interface{})
+				merged[key] = append(mergedJobs, jobs...)
+			case "global":
+				global, ok := value.(map[string]interface{})
+				if !ok && value != nil {
+					return nil, fmt.Errorf("configuration file %d: global must be a map", i+1)
+				}
+				mergedGlobal, _ := merged[key].(map[string]interface{})
+				if mergedGlobal == nil {
+					mergedGlobal = make(map[string]interface{})
+				}
+				mergeSettings(mergedGlobal, global, key, i+1)
+				merged[key] = mergedGlobal
+			default:
+				merged[key] = value
+			}
+		}
+	}
+	return yaml.Marshal(merged)
+}
+
+// mergeSettings merges the given settings into the merged ones key by key, recursing into nested maps so that only
+// the leaf settings are overridden, lists are replaced as a whole. Each override of a different value is reported
+// with its full path, e.g. global.indexerConfig.esServers
+func mergeSettings(merged, settings map[string]interface{}, path string, file int) {
+	for setting, value := range settings {
+		settingPath := path + "." + setting
+		previous, exists := merged[setting]
+		previousMap, previousIsMap := previous.(map[string]interface{})
+		valueMap, valueIsMap := value.(map[string]interface{})
+		if previousIsMap && valueIsMap {
+			mergeSettings(previousMap, valueMap, settingPath, file)
+			continue
+		}
+		if exists && !reflect.DeepEqual(previous, value) {
+			log.Warnf("Setting %s of configuration file %d overrides the one of the previous files", settingPath, file)
+		}
+		merged[setting] = value
+	}
+}
+
+// totalObjects returns the number of objects created by the creation jobs: jobIterations × replicas of every object,
+// runOnce objects are created once, and weighted jobs are accounted with the object with most replicas in every iteration.
+// The objects re-created by churn are accounted with the maximum number of cycles fitting in churnDuration, which can't
+// be bounded when churnDelay is 0
+func totalObjects(jobs []Job) int {
+	var total int
+	for _, job := range jobs {
+		if job.JobType != CreationJob {
+			continue
+		}
+		var perIteration, maxReplicas, runOnce int
+		for _, o := range job.Objects {
+			if o.RunOnce {
+				runOnce += o.Replicas
+				continue
+			}
+			perIteration += o.Replicas
+			if o.Replicas > maxReplicas {
+				maxReplicas = o.Replicas
+			}
+		}
+		if job.Selection == SelectionWeighted {
+			perIteration = maxReplicas
+		}
+		total += runOnce + job.JobIterations*perIteration
+		if !job.Churn {
+			continue
+		}
+		if job.ChurnDelay <= 0 {
+			log.Warnf("Job %s: churn cycles can't be bounded without churnDelay, the objects re-created by churn aren't accounted", job.Name)
+			continue
+		}
+		cycles := int(math.Ceil(float64(job.ChurnDuration) / float64(job.ChurnDelay)))
+		// Same number of churned iterations as RunCreateJobWithChurn
+		churned := int(math.Min(math.Max(float64(job.ChurnPercent*job.JobIterations/100), 1), float64(job.JobIterations)))
+		perCycle := churned * perIteration
+		// runOnce objects are only re-created when the whole job is churned
+		if churned == job.JobIterations {
+			perCycle += runOnce
+		}
+		total += cycles * perCycle
+	}
+	return total
+}
+
+// SetKubeConfig sets the kubeconfig file and context the API clients are built from, instead of the default kubeconfig resolution.
+// Empty values keep the default behavior, it returns an error when the given context doesn't exist in the kubeconfig
+func SetKubeConfig(kubeconfig, kubeContext string) error {
+	kubeConfigPath, kubeConfigContext = kubeconfig, kubeContext
+	if kubeContext == "" {
+		return nil
+	}
+	path := kubeconfigPath()
+	if path == "" {
+		return fmt.Errorf("context %s given but no kubeconfig found", kubeContext)
+	}
+	rawConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: path}, &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return fmt.Errorf("error loading kubeconfig %s: %s", path, err)
+	}
+	if _, ok := rawConfig.Contexts[kubeContext]; !ok {
+		return fmt.Errorf("context %s not found in kubeconfig %s", kubeContext, path)
+	}
+	return nil
+}
+
+// kubeconfigPath returns the kubeconfig file given by SetKubeConfig, or the one pointed by KUBECONFIG, or ~/.kube/config when it exists
+func kubeconfigPath() string {
+	if kubeConfigPath != "" {
+		return kubeConfigPath
+	}
+	if os.Getenv("KUBECONFIG") != "" {
+		return os.Getenv("KUBECONFIG")
+	}
+	if _, err := os.Stat(filepath.Join(os.Getenv("HOME"), ".kube", "config")); !os.IsNotExist(err) {
+		return filepath.Join(os.Getenv("HOME"), ".kube", "config")
+	}
+	return ""
+}
+
+func buildConfig() (*rest.Config, error) {
+	kubeconfig := kubeconfigPath()
+	// Fall back to the in-cluster configuration when no kubeconfig is available
+	if kubeconfig == "" && kubeConfigContext == "" {
+		restConfig, err := rest.InClusterConfig()
 		if err == nil {
-			return kubeconfig, nil
+			return restConfig, nil
 		}
 	}
 	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigPath},
-		&clientcmd.ConfigOverrides{ClusterInfo: clientcmdapi.Cluster{Server: ""}}).ClientConfig()
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+		&clientcmd.ConfigOverrides{ClusterInfo: clientcmdapi.Cluster{Server: ""}, CurrentContext: kubeConfigContext}).ClientConfig()
+}
+
+// RenderNamespacePattern renders the given namespace pattern for the given namespace index with the functions of the given engine
+func RenderNamespacePattern(engine util.TemplateEngine, pattern string, nsIndex int, uuid, jobName string) (string, error) {
+	templateData := map[string]interface{}{
+		"Iteration": nsIndex,
+		"UUID":      uuid,
+		"JobName":   jobName,
+	}
+	ns, err := util.RenderTemplateWithEngine(engine, []byte(pattern), templateData, util.MissingKeyError)
+	return string(ns), err
+}
+
+// validateReuseNamespaces verifies the namespaces reused by the job can hold its namespaced iterations
+func validateReuseNamespaces(job Job) error {
+	if job.JobType != CreationJob {
+		return fmt.Errorf("reuseNamespaces is only supported by create jobs")
+	}
+	if !job.NamespacedIterations {
+		return fmt.Errorf("reuseNamespaces requires namespacedIterations")
+	}
+	if job.NamespacePattern != "" {
+		return fmt.Errorf("reuseNamespaces and namespacePattern are mutually exclusive")
+	}
+	if job.Churn {
+		return fmt.Errorf("reuseNamespaces can't be used with churn, it would delete the reused namespaces")
+	}
+	for _, pattern := range job.ReuseNamespaces {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid reuseNamespaces pattern %q: %s", pattern, err)
+		}
+	}
+	return nil
+}
+
+// validateNamespacePattern verifies the namespaces of the first and last iterations rendered by the pattern of the job are valid
+func validateNamespacePattern(job Job, uuid string, engine util.TemplateEngine) error {
+	iterationsPerNamespace := job.IterationsPerNamespace
+	if iterationsPerNamespace < 1 {
+		iterationsPerNamespace = 1
+	}
+	lastIteration := job.JobIterations - 1
+	if lastIteration < 0 {
+		lastIteration = 0
+	}
+	for _, nsIndex := range []int{0, lastIteration / iterationsPerNamespace} {
+		ns, err := RenderNamespacePattern(engine, job.NamespacePattern, nsIndex, uuid, job.Name)
+		if err != nil {
+			return fmt.Errorf("invalid namespacePattern %q: %s", job.NamespacePattern, err)
+		}
+		if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+			return fmt.Errorf("namespacePattern %q renders invalid namespace %q: %s", job.NamespacePattern, ns, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+func validateResourceSweep(sweep *ResourceSweep) error {
+	if sweep == nil {
+		return nil
+	}
+	if sweep.Resource == "" {
+		return fmt.Errorf("resourceSweep requires a resource name")
+	}
+	for _, q := range []string{sweep.Start, sweep.End} {
+		if _, err := resource.ParseQuantity(q); err != nil {
+			return fmt.Errorf("invalid resourceSweep quantity %q: %s", q, err)
+		}
+	}
+	return nil
+}
+
+func validateWaitOptions(waitOptions WaitOptions) error {
+	if len(waitOptions.ForConditions) > 0 {
+		if waitOptions.ForCondition != "" || waitOptions.ForJSONPath != "" {
+			return fmt.Errorf("waitOptions forConditions, forCondition and forJSONPath are mutually exclusive")
+		}
+		for _, condition := range waitOptions.ForConditions {
+			if condition == "" {
+				return fmt.Errorf("waitOptions forConditions can't hold empty conditions")
+			}
+		}
+	}
+	if waitOptions.ForJSONPath == "" {
+		if waitOptions.Value != "" {
+			return fmt.Errorf("waitOptions value requires forJSONPath")
+		}
+		return nil
+	}
+	if waitOptions.ForCondition != "" {
+		return fmt.Errorf("waitOptions forCondition and forJSONPath are mutually exclusive")
+	}
+	if waitOptions.Value == "" {
+		return fmt.Errorf("waitOptions forJSONPath requires a value")
+	}
+	_, err := ParseWaitJSONPath(waitOptions.ForJSONPath)
+	return err
+}
+
+// ParseWaitJSONPath parses a JSONPath expression, given either as a template like {.status.phase} or as a bare path like .status.phase
+func ParseWaitJSONPath(path string) (*jsonpath.JSONPath, error) {
+	if !strings.HasPrefix(path, "{") {
+		path = fmt.Sprintf("{%s}", path)
+	}
+	jp := jsonpath.New("wait").AllowMissingKeys(true)
+	if err := jp.Parse(path); err != nil {
+		return nil, fmt.Errorf("invalid waitOptions forJSONPath %q: %s", path, err)
+	}
+	return jp, nil
+}
+
+// ParseRequiredAPI parses an API in group/version/kind format, core APIs can be expressed as version/kind
+func ParseRequiredAPI(api string) (schema.GroupVersionKind, error) {
+	var gvk schema.GroupVersionKind
+	parts := strings.Split(api, "/")
+	switch len(parts) {
+	case 2:
+		gvk = schema.GroupVersionKind{Version: parts[0], Kind: parts[1]}
+	case 3:
+		gvk = schema.GroupVersionKind{Group: parts[0], Version: parts[1], Kind: parts[2]}
+	default:
+		return gvk, fmt.Errorf("invalid required API %q, expected group/version/kind", api)
+	}
+	if gvk.Version == "" || gvk.Kind == "" {
+		return gvk, fmt.Errorf("invalid required API %q, expected group/version/kind", api)
+	}
+	return gvk, nil
 }
 
 func jobIsDuped() error {
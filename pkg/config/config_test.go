@@ -0,0 +1,72 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		merged   map[string]interface{}
+		settings map[string]interface{}
+		want     map[string]interface{}
+	}{
+		{
+			name:     "new settings are added",
+			merged:   map[string]interface{}{"gc": true},
+			settings: map[string]interface{}{"timeout": "1h"},
+			want:     map[string]interface{}{"gc": true, "timeout": "1h"},
+		},
+		{
+			name:     "leaf settings are overridden",
+			merged:   map[string]interface{}{"gc": true, "timeout": "1h"},
+			settings: map[string]interface{}{"gc": false},
+			want:     map[string]interface{}{"gc": false, "timeout": "1h"},
+		},
+		{
+			name: "nested maps are merged key by key",
+			merged: map[string]interface{}{
+				"indexerConfig": map[string]interface{}{"type": "elastic", "esServers": []interface{}{"https://es1"}, "defaultIndex": "kube-burner"},
+			},
+			settings: map[string]interface{}{
+				"indexerConfig": map[string]interface{}{"esServers": []interface{}{"https://es2"}},
+			},
+			want: map[string]interface{}{
+				"indexerConfig": map[string]interface{}{"type": "elastic", "esServers": []interface{}{"https://es2"}, "defaultIndex": "kube-burner"},
+			},
+		},
+		{
+			name:     "lists are replaced as a whole",
+			merged:   map[string]interface{}{"measurements": []interface{}{"podLatency", "vmiLatency"}},
+			settings: map[string]interface{}{"measurements": []interface{}{"jobLatency"}},
+			want:     map[string]interface{}{"measurements": []interface{}{"jobLatency"}},
+		},
+		{
+			name:     "a map replaces a scalar",
+			merged:   map[string]interface{}{"indexerConfig": nil},
+			settings: map[string]interface{}{"indexerConfig": map[string]interface{}{"type": "local"}},
+			want:     map[string]interface{}{"indexerConfig": map[string]interface{}{"type": "local"}},
+		},
+	}
+	for _, tt := range tests {
+		mergeSettings(tt.merged, tt.settings, "global", 2)
+		if !reflect.DeepEqual(tt.merged, tt.want) {
+			t.Errorf("%s: mergeSettings() = %v, want %v", tt.name, tt.merged, tt.want)
+		}
+	}
+}
@@ -32,6 +32,57 @@ const (
 	DeletionJob JobType = "delete"
 	// PatchJob used to patch objects
 	PatchJob JobType = "patch"
+	// BaselineJob used to collect measurements and metrics without creating objects
+	BaselineJob JobType = "baseline"
+	// PauseJob used to pause between jobs
+	PauseJob JobType = "pause"
+)
+
+// MissingAPIPolicy action taken when an API required by a job or object is not available in the cluster
+type MissingAPIPolicy string
+
+const (
+	// MissingAPISkip skips the job or object requiring the missing API
+	MissingAPISkip MissingAPIPolicy = "skip"
+	// MissingAPIError makes kube-burner fail
+	MissingAPIError MissingAPIPolicy = "error"
+)
+
+const (
+	// PodLogsFirst samples the first created pods
+	PodLogsFirst = "first"
+	// PodLogsRandom samples random pods among the created ones
+	PodLogsRandom = "random"
+)
+
+const (
+	// PreJobCheckFail fails the job when Prometheus can't be queried
+	PreJobCheckFail = "fail"
+	// PreJobCheckWarn logs a warning and starts the job when Prometheus can't be queried
+	PreJobCheckWarn = "warn"
+)
+
+const (
+	// SelectionAll creates all the objects of the job in every iteration
+	SelectionAll = "all"
+	// SelectionWeighted creates a single object of the job per iteration, picked randomly according to the object weights
+	SelectionWeighted = "weighted"
+)
+
+const (
+	// OrderingSequential creates the objects of each iteration in the order they're declared
+	OrderingSequential = "sequential"
+	// OrderingShuffled creates the objects of each iteration in a random order, seeded by orderingSeed
+	OrderingShuffled = "shuffled"
+)
+
+const (
+	// CreateOperation creates the objects
+	CreateOperation = "create"
+	// ApplyOperation applies the objects using server-side apply
+	ApplyOperation = "apply"
+	// PatchOperation patches the existing objects matching the labelSelector, jobs whose objects use it run as patch jobs
+	PatchOperation = "patch"
 )
 
 // Spec configuration root
@@ -44,6 +95,16 @@ type Spec struct {
 	EmbedFS embed.FS
 	// EmbedFSDir Directory in which the configuration files are in the embed filesystem
 	EmbedFSDir string
+	// Retry holds the failed iterations of a previous run, only these iterations are run when set
+	Retry *Retry `yaml:"-"`
+	// DryRun renders the objects without sending them to the API server when set
+	DryRun *DryRun `yaml:"-"`
+	// ProgressInterval interval of the progress reports of creation jobs, disabled when 0
+	ProgressInterval time.Duration `yaml:"-"`
+	// TotalObjects number of objects the creation jobs of the configuration create
+	TotalObjects int `yaml:"-"`
+	// Force runs the configuration even when its TotalObjects exceed maxObjects
+	Force bool `yaml:"-"`
 }
 
 // GlobalConfig holds the global configuration
@@ -53,7 +114,9 @@ type GlobalConfig struct {
 	// Benchmark RUNID
 	RUNID string
 	// IndexerConfig contains a IndexerConfig definition
-	IndexerConfig indexers.IndexerConfig `yaml:"indexerConfig"`
+	IndexerConfig IndexerConfig `yaml:"indexerConfig"`
+	// Indexers list of indexers documents are sent to, in addition to indexerConfig
+	Indexers []IndexerConfig `yaml:"indexers" json:"indexers,omitempty"`
 	// Measurements describes a list of measurements kube-burner
 	// will take along with job
 	Measurements []mtypes.Measurement `yaml:"measurements"`
@@ -63,14 +126,153 @@ type GlobalConfig struct {
 	GC bool `yaml:"gc" json:"gc"`
 	// WaitWhenFinished Wait for pods to be running when all the jobs are completed
 	WaitWhenFinished bool `yaml:"waitWhenFinished" json:"waitWhenFinished,omitempty"`
+	// CleanupOnFailure garbage collect the created namespaces also when the run fails, disabling it keeps them for inspection
+	CleanupOnFailure bool `yaml:"cleanupOnFailure" json:"cleanupOnFailure"`
 	// GCTimeout garbage collection timeout
 	GCTimeout time.Duration `yaml:"gcTimeout"`
+	// DeletionConcurrency maximum number of namespaces deleted in parallel during garbage collection
+	DeletionConcurrency int `yaml:"deletionConcurrency" json:"deletionConcurrency,omitempty"`
 	// Boolean flag to collect metrics during garbage collection
 	GCMetrics bool `yaml:"gcMetrics"`
+	// ValuesFile YAML file whose content is exposed to the object templates as .Values
+	ValuesFile string `yaml:"valuesFile" json:"valuesFile,omitempty"`
+	// EnvVars environment variables exposed to the object templates as .Env
+	EnvVars []string `yaml:"envVars" json:"envVars,omitempty"`
+	// Values content of ValuesFile
+	Values map[string]interface{} `yaml:"-" json:"-"`
+	// Env values of the allowlisted environment variables which are set
+	Env map[string]interface{} `yaml:"-" json:"-"`
+	// InjectLabels labels added to every object and namespace created, object-defined labels take precedence
+	InjectLabels map[string]string `yaml:"injectLabels" json:"injectLabels,omitempty"`
+	// InjectAnnotations annotations added to every object and namespace created, object-defined annotations take precedence
+	InjectAnnotations map[string]string `yaml:"injectAnnotations" json:"injectAnnotations,omitempty"`
+	// QPS default client queries per second of the jobs not setting their own
+	QPS float32 `yaml:"qps" json:"qps,omitempty"`
+	// Burst default client burst of the jobs not setting their own
+	Burst int `yaml:"burst" json:"burst,omitempty"`
+	// ClientPoolSize number of independent API clients object operations are distributed across
+	ClientPoolSize int `yaml:"clientPoolSize" json:"clientPoolSize,omitempty"`
+	// Trace configures the Chrome trace-event file with the per-iteration timings
+	Trace TraceConfig `yaml:"trace" json:"trace,omitempty"`
+	// CleanupVerifications list of commands to verify the external state once garbage collection finishes
+	CleanupVerifications []CleanupVerification `yaml:"cleanupVerifications" json:"cleanupVerifications,omitempty"`
+	// LeakCheck compares the cluster object counts after garbage collection against a baseline taken before the run
+	LeakCheck LeakCheck `yaml:"leakCheck" json:"leakCheck,omitempty"`
+	// ScrapeOffsetBefore time the Prometheus scraping window of each job starts before the job
+	ScrapeOffsetBefore time.Duration `yaml:"scrapeOffsetBefore" json:"scrapeOffsetBefore,omitempty"`
+	// ScrapeOffsetAfter time the Prometheus scraping window of each job ends after the job
+	ScrapeOffsetAfter time.Duration `yaml:"scrapeOffsetAfter" json:"scrapeOffsetAfter,omitempty"`
+	// CSVDirectory directory where the measurement results are exported as CSV, one file per measurement
+	CSVDirectory string `yaml:"csvDirectory" json:"csvDirectory,omitempty"`
+	// LiveAlertInterval interval the alert profiles are evaluated at during the run, a critical alert aborts it. 0 disables it
+	LiveAlertInterval time.Duration `yaml:"liveAlertInterval" json:"liveAlertInterval,omitempty"`
+	// TemplateEngine functions available to the object templates and namespace patterns: sprig or go
+	TemplateEngine string `yaml:"templateEngine" json:"templateEngine,omitempty"`
+	// MaxObjects safety cap of the objects created by the configuration, kube-burner refuses to run it when exceeded. 0 disables it
+	MaxObjects int `yaml:"maxObjects" json:"maxObjects,omitempty"`
+}
+
+// IndexerConfigs returns the configuration of all the indexers, starting with indexerConfig
+func (g GlobalConfig) IndexerConfigs() []IndexerConfig {
+	if g.IndexerConfig.Type == "" {
+		return nil
+	}
+	return append([]IndexerConfig{g.IndexerConfig}, g.Indexers...)
+}
+
+// IndexerConfig extends the go-commons indexer configuration with kube-burner specific options
+type IndexerConfig struct {
+	indexers.IndexerConfig `yaml:",inline"`
+	// Gzip compresses the files written by the local indexer
+	Gzip bool `yaml:"gzip" json:"gzip,omitempty"`
+	// OTLP configuration of the otlp indexer
+	OTLP OTLPConfig `yaml:"otlp" json:"otlp,omitempty"`
+	// AzureMonitor configuration of the azuremonitor indexer
+	AzureMonitor AzureMonitorConfig `yaml:"azureMonitor" json:"azureMonitor,omitempty"`
+	// BulkSize maximum number of documents sent per Elasticsearch or OpenSearch bulk request
+	BulkSize int `yaml:"bulkSize" json:"bulkSize,omitempty"`
+	// BulkRetries retries of a failed Elasticsearch or OpenSearch bulk request
+	BulkRetries int `yaml:"bulkRetries" json:"bulkRetries,omitempty"`
+	// SpillDirectory directory where the documents of the bulk requests failing after all the retries are written
+	SpillDirectory string `yaml:"spillDirectory" json:"spillDirectory,omitempty"`
+	// CACert path to the PEM bundle of the CAs the server certificates are verified against
+	CACert string `yaml:"caCert" json:"caCert,omitempty"`
+}
+
+// OTLPConfig holds the configuration of the OpenTelemetry collector the otlp indexer ships documents to
+type OTLPConfig struct {
+	// Endpoint OTLP/HTTP endpoint of the collector, documents are sent to its /v1/logs path
+	Endpoint string `yaml:"endpoint" json:"endpoint,omitempty"`
+	// Headers sent in every request, i.e. for authentication
+	Headers map[string]string `yaml:"headers" json:"-"`
+	// CACert path to the CA certificate used to verify the collector certificate
+	CACert string `yaml:"caCert" json:"caCert,omitempty"`
+	// ClientCert path to the client certificate for mutual TLS
+	ClientCert string `yaml:"clientCert" json:"clientCert,omitempty"`
+	// ClientKey path to the client key for mutual TLS
+	ClientKey string `yaml:"clientKey" json:"clientKey,omitempty"`
+	// BatchSize maximum number of documents sent per request
+	BatchSize int `yaml:"batchSize" json:"batchSize,omitempty"`
+	// MaxRetries number of times a batch is retried on transient collector errors
+	MaxRetries int `yaml:"maxRetries" json:"maxRetries,omitempty"`
+}
+
+// AzureMonitorConfig holds the configuration of the Log Analytics workspace the azuremonitor indexer posts documents to
+type AzureMonitorConfig struct {
+	// WorkspaceID ID of the Log Analytics workspace
+	WorkspaceID string `yaml:"workspaceID" json:"workspaceID,omitempty"`
+	// SharedKey base64 encoded primary or secondary key of the workspace
+	SharedKey string `yaml:"sharedKey" json:"-"`
+	// LogType record type of the documents, Log Analytics stores them in the LogType_CL table
+	LogType string `yaml:"logType" json:"logType,omitempty"`
+	// Domain of the Data Collector API, overridden for sovereign clouds
+	Domain string `yaml:"domain" json:"domain,omitempty"`
+	// BatchSize maximum number of documents sent per request
+	BatchSize int `yaml:"batchSize" json:"batchSize,omitempty"`
+	// MaxRetries number of times a batch is retried on transient errors
+	MaxRetries int `yaml:"maxRetries" json:"maxRetries,omitempty"`
+}
+
+// LeakCheck holds the object leak check configuration
+type LeakCheck struct {
+	// Enabled enables the leak check
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Tolerance maximum number of objects of each kind allowed above the baseline
+	Tolerance int `yaml:"tolerance" json:"tolerance"`
+	// Exclude resources not checked, in resource.group format
+	Exclude []string `yaml:"exclude" json:"exclude,omitempty"`
+}
+
+// TraceConfig holds the per-iteration timing trace configuration
+type TraceConfig struct {
+	// File path of the trace, tracing is enabled when set
+	File string `yaml:"file" json:"file,omitempty"`
+	// SampleRate fraction of iterations traced
+	SampleRate float64 `yaml:"sampleRate" json:"sampleRate,omitempty"`
+	// MaxEvents maximum number of events recorded in the trace
+	MaxEvents int `yaml:"maxEvents" json:"maxEvents,omitempty"`
+}
+
+// CleanupVerification defines a command that must succeed after garbage collection
+type CleanupVerification struct {
+	// Name of the verification
+	Name string `yaml:"name" json:"name"`
+	// Command executed by /bin/sh, the verification passes when it returns 0
+	Command string `yaml:"command" json:"command"`
+	// Timeout to wait for the verification to pass
+	Timeout time.Duration `yaml:"timeout" json:"timeout"`
+	// Interval between command retries
+	Interval time.Duration `yaml:"interval" json:"interval"`
 }
 
 // Object defines an object that kube-burner will create
 type Object struct {
+	// Name identifies the object within the job, so other objects can depend on it
+	Name string `yaml:"name" json:"name,omitempty"`
+	// DependsOn names of the objects of the job created before this one in each iteration
+	DependsOn []string `yaml:"dependsOn" json:"dependsOn,omitempty"`
+	// WaitForDependencies waits for the objects this one depends on to be ready before creating it
+	WaitForDependencies bool `yaml:"waitForDependencies" json:"waitForDependencies,omitempty"`
 	// ObjectTemplate path to a valid YAML definition of a k8s resource
 	ObjectTemplate string `yaml:"objectTemplate" json:"objectTemplate,omitempty"`
 	// Replicas number of replicas to create of the given object
@@ -86,12 +288,100 @@ type Object struct {
 	APIVersion string `yaml:"apiVersion" json:"apiVersion,omitempty"`
 	// LabelSelector objects with this labels will be removed
 	LabelSelector map[string]string `yaml:"labelSelector" json:"labelSelector,omitempty"`
+	// FieldSelector only the objects matching the labelSelector and this field selector will be removed
+	FieldSelector string `yaml:"fieldSelector" json:"fieldSelector,omitempty"`
 	// Namespaced this object is namespaced
 	Namespaced bool `yaml:"-" json:"-"`
 	// Wait for resource to be ready, it doesn't apply to all resources
 	Wait bool `yaml:"wait" json:"wait"`
 	// WaitOptions define custom behaviors when waiting for objects creation
 	WaitOptions WaitOptions `yaml:"waitOptions" json:"waitOptions,omitempty"`
+	// MaxWaitTimeout maximum wait period for this object, overrides the maxWaitTimeout of the job
+	MaxWaitTimeout time.Duration `yaml:"maxWaitTimeout" json:"maxWaitTimeout,omitempty"`
+	// ResourceSweep sweeps the container resources of the object across job iterations
+	ResourceSweep *ResourceSweep `yaml:"resourceSweep" json:"resourceSweep,omitempty"`
+	// RequiresAPI APIs, in group/version/kind format, required to create the object
+	RequiresAPI []string `yaml:"requiresAPI" json:"requiresAPI,omitempty"`
+	// RunOnce creates the object replicas a single time, in the first iteration, instead of in every iteration
+	RunOnce bool `yaml:"runOnce" json:"runOnce,omitempty"`
+	// Weight of the object when the job uses weighted selection
+	Weight int `yaml:"weight" json:"weight,omitempty"`
+	// ObjectOperation how the object is sent to the API server: create, apply or patch
+	ObjectOperation string `yaml:"objectOperation" json:"objectOperation,omitempty"`
+	// FieldManager name of the field manager used to apply the object
+	FieldManager string `yaml:"fieldManager" json:"fieldManager,omitempty"`
+	// ForceConflicts takes the ownership of the fields managed by other field managers when applying the object
+	ForceConflicts bool `yaml:"forceConflicts" json:"forceConflicts,omitempty"`
+}
+
+// ResourceSweep defines a linear sweep of a container resource across job iterations
+type ResourceSweep struct {
+	// Resource name, i.e. cpu or memory
+	Resource string `yaml:"resource" json:"resource"`
+	// Start resource quantity used in the first iteration
+	Start string `yaml:"start" json:"start"`
+	// End resource quantity used in the last iteration
+	End string `yaml:"end" json:"end"`
+	// Limits set the resource limits to the same quantity as requests
+	Limits bool `yaml:"limits" json:"limits,omitempty"`
+}
+
+// ConcurrencySweep defines the concurrency levels of create requests a creation job sweeps across
+type ConcurrencySweep struct {
+	// Levels maximum number of concurrent create requests of each level
+	Levels []int `yaml:"levels" json:"levels"`
+	// Iterations number of job iterations run within each level
+	Iterations int `yaml:"iterations" json:"iterations"`
+}
+
+// NamespaceLabelVariant defines a set of labels applied to a share of the created namespaces
+type NamespaceLabelVariant struct {
+	// Labels applied to the namespace
+	Labels map[string]string `yaml:"labels" json:"labels"`
+	// Weight of the variant, namespaces are labeled with this variant with probability weight/sum of weights
+	Weight int `yaml:"weight" json:"weight"`
+}
+
+// RateSegment defines a segment of a creation rate plan
+type RateSegment struct {
+	// Count objects created in the segment, 0 means the rest of the job and is only allowed in the last segment
+	Count int `yaml:"count" json:"count"`
+	// Rate objects created per second in the segment, 0 means as fast as the client allows
+	Rate float64 `yaml:"rate" json:"rate"`
+}
+
+// PodLogs defines the sample of pods whose logs are captured
+type PodLogs struct {
+	// Sample number of pods whose logs are captured
+	Sample int `yaml:"sample" json:"sample"`
+	// Selection how the sampled pods are picked: first or random
+	Selection string `yaml:"selection" json:"selection,omitempty"`
+}
+
+// PreJobCheck defines a Prometheus expression that must be true before the job starts
+type PreJobCheck struct {
+	// Expr Prometheus expression, it's true when it returns samples and all of them are different than 0
+	Expr string `yaml:"expr" json:"expr"`
+	// Interval between evaluations of the expression
+	Interval time.Duration `yaml:"interval" json:"interval,omitempty"`
+	// Timeout maximum time waiting for the expression to be true
+	Timeout time.Duration `yaml:"timeout" json:"timeout,omitempty"`
+	// OnError action taken when Prometheus can't be queried: fail or warn
+	OnError string `yaml:"onError" json:"onError,omitempty"`
+}
+
+// DryRun holds the dry run configuration
+type DryRun struct {
+	// OutputDir directory where the rendered objects are written, they're logged when empty
+	OutputDir string
+}
+
+// Retry describes the failed iterations of a previous run to retry
+type Retry struct {
+	// UUID of the previous run
+	UUID string
+	// Iterations failed iterations indexed by job name
+	Iterations map[string][]int
 }
 
 // Job defines a kube-burner job
@@ -114,12 +404,26 @@ type Job struct {
 	QPS float32 `yaml:"qps" json:"qps,omitempty"`
 	// Maximum burst for throttle
 	Burst int `yaml:"burst" json:"burst,omitempty"`
+	// Rate objects created per second by a creation job, paced independently of the client QPS
+	Rate float64 `yaml:"rate" json:"rate,omitempty"`
+	// RatePlan creation rate segments executed in order, a generalization of Rate
+	RatePlan []RateSegment `yaml:"ratePlan" json:"ratePlan,omitempty"`
+	// TimeoutWeight fraction of the overall timeout allocated to the job, jobs without it share the rest equally
+	TimeoutWeight float64 `yaml:"timeoutWeight" json:"timeoutWeight,omitempty"`
 	// Namespace namespace base name to use
 	Namespace string `yaml:"namespace" json:"namespace,omitempty"`
+	// NamespacePattern go-template used to name the namespaces created by the job, overrides the namespace-index scheme when set
+	NamespacePattern string `yaml:"namespacePattern" json:"namespacePattern,omitempty"`
+	// ReuseNamespaces names or glob patterns of existing namespaces the iterations are distributed across instead of creating new ones
+	ReuseNamespaces []string `yaml:"reuseNamespaces" json:"reuseNamespaces,omitempty"`
 	// MaxWaitTimeout maximum wait period
 	MaxWaitTimeout time.Duration `yaml:"maxWaitTimeout" json:"maxWaitTimeout,omitempty"`
 	// WaitForDeletion wait for objects to be definitively deleted
 	WaitForDeletion bool `yaml:"waitForDeletion" json:"waitForDeletion,omitempty"`
+	// ForegroundDeletion delete objects with the foreground propagation policy, keeping them until their dependents are deleted
+	ForegroundDeletion bool `yaml:"foregroundDeletion" json:"foregroundDeletion,omitempty"`
+	// DeletionTimeout maximum time waiting for the objects to be deleted, defaults to maxWaitTimeout
+	DeletionTimeout time.Duration `yaml:"deletionTimeout" json:"deletionTimeout,omitempty"`
 	// PodWait wait for all pods to be running before moving forward to the next iteration
 	PodWait bool `yaml:"podWait" json:"podWait,omitempty"`
 	// WaitWhenFinished Wait for pods to be running when all job iterations are completed
@@ -130,6 +434,10 @@ type Job struct {
 	NamespacedIterations bool `yaml:"namespacedIterations" json:"namespacedIterations,omitempty"`
 	// IterationsPerNamespace is the modulus to apply to job iterations to calculate . Default 1
 	IterationsPerNamespace int `yaml:"iterationsPerNamespace" json:"iterationsPerNamespace,omitempty"`
+	// NamespaceCreationDelay minimum time between the creation of two namespaces of the job
+	NamespaceCreationDelay time.Duration `yaml:"namespaceCreationDelay" json:"namespaceCreationDelay,omitempty"`
+	// NamespaceCreationJitter maximum random time added to namespaceCreationDelay
+	NamespaceCreationJitter time.Duration `yaml:"namespaceCreationJitter" json:"namespaceCreationJitter,omitempty"`
 	// VerifyObjects verify object count after running the job
 	VerifyObjects bool `yaml:"verifyObjects" json:"verifyObjects,omitempty"`
 	// ErrorOnVerify exit when verification fails
@@ -142,6 +450,23 @@ type Job struct {
 	PreLoadNodeLabels map[string]string `yaml:"preLoadNodeLabels" json:"-"`
 	// NamespaceLabels add custom labels to namespaces created by kube-burner
 	NamespaceLabels map[string]string `yaml:"namespaceLabels" json:"-"`
+	// NamespaceLabelVariants label variants distributed across the created namespaces according to their weight
+	NamespaceLabelVariants []NamespaceLabelVariant `yaml:"namespaceLabelVariants" json:"namespaceLabelVariants,omitempty"`
+	// NamespaceLabelSeed seed used to distribute the namespace label variants
+	NamespaceLabelSeed int64 `yaml:"namespaceLabelSeed" json:"namespaceLabelSeed,omitempty"`
+	// Selection how the objects created in each iteration are chosen: all or weighted
+	Selection string `yaml:"selection" json:"selection,omitempty"`
+	// SelectionSeed seed used to pick the objects of each iteration with weighted selection
+	SelectionSeed int64 `yaml:"selectionSeed" json:"selectionSeed,omitempty"`
+	// ObjectOrdering order in which the objects of each iteration are created: sequential or shuffled
+	ObjectOrdering string `yaml:"objectOrdering" json:"objectOrdering,omitempty"`
+	// OrderingSeed seed used to shuffle the objects of each iteration with shuffled ordering, a pointer so a seed of 0 is
+	// still reported
+	OrderingSeed *int64 `yaml:"orderingSeed" json:"orderingSeed,omitempty"`
+	// ObjectConcurrency maximum number of objects of an iteration created concurrently
+	ObjectConcurrency int `yaml:"objectConcurrency" json:"objectConcurrency,omitempty"`
+	// NameSeed seed used to generate the random name suffix of each iteration, injected in the templates as NameSuffix
+	NameSeed int64 `yaml:"nameSeed" json:"nameSeed,omitempty"`
 	// Churn workload
 	Churn bool `yaml:"churn" json:"churn,omitempty"`
 	// Churn percentage
@@ -152,11 +477,55 @@ type Job struct {
 	ChurnDelay time.Duration `yaml:"churnDelay" json:"churnDelay,omitempty"`
 	// Churn deletion strategy
 	ChurnDeletionStrategy string `yaml:"churnDeletionStrategy" json:"churnDeletionStrategy,omitempty"`
+	// ChurnTeardownWaveSize number of namespaces deleted per wave in each churn cycle, 0 deletes all of them at once
+	ChurnTeardownWaveSize int `yaml:"churnTeardownWaveSize" json:"churnTeardownWaveSize,omitempty"`
+	// ChurnTeardownWaveJitter maximum random delay between churn teardown waves
+	ChurnTeardownWaveJitter time.Duration `yaml:"churnTeardownWaveJitter" json:"churnTeardownWaveJitter,omitempty"`
 	// Skip this job from indexing
 	SkipIndexing bool `yaml:"skipIndexing" json:"skipIndexing,omitempty"`
+	// BaselineDuration how long a baseline job collects measurements and metrics
+	BaselineDuration time.Duration `yaml:"baselineDuration" json:"baselineDuration,omitempty"`
+	// RetryBackoff initial backoff of the create and patch requests retried due to retryable errors
+	RetryBackoff time.Duration `yaml:"retryBackoff" json:"retryBackoff,omitempty"`
+	// RetryBackoffCap maximum backoff between the retries of the create and patch requests
+	RetryBackoffCap time.Duration `yaml:"retryBackoffCap" json:"retryBackoffCap,omitempty"`
+	// MaxRetries maximum number of retries of the create and patch requests
+	MaxRetries int `yaml:"maxRetries" json:"maxRetries,omitempty"`
+	// PauseDuration how long a pause job sleeps
+	PauseDuration time.Duration `yaml:"pauseDuration" json:"pauseDuration,omitempty"`
+	// Jitter percentage of the pause duration randomly added or subtracted to it
+	Jitter float64 `yaml:"jitter" json:"jitter,omitempty"`
+	// ConcurrencySweep runs the creation job once per concurrency level
+	ConcurrencySweep *ConcurrencySweep `yaml:"concurrencySweep" json:"concurrencySweep,omitempty"`
+	// ReadinessDelay delays the readiness of the containers of the created pods
+	ReadinessDelay time.Duration `yaml:"readinessDelay" json:"readinessDelay,omitempty"`
+	// ReadinessProbeCommand command of the readiness probe injected in containers without one
+	ReadinessProbeCommand []string `yaml:"readinessProbeCommand" json:"readinessProbeCommand,omitempty"`
+	// MetricsProfile metrics profile scraped within the job time range, in addition to the global one
+	MetricsProfile string `yaml:"metricsProfile" json:"metricsProfile,omitempty"`
+	// ReplaceGlobalMetricsProfile scrape only the job metrics profile, skipping the global one
+	ReplaceGlobalMetricsProfile bool `yaml:"replaceGlobalMetricsProfile" json:"replaceGlobalMetricsProfile,omitempty"`
+	// RequiresAPI APIs, in group/version/kind format, required to run the job
+	RequiresAPI []string `yaml:"requiresAPI" json:"requiresAPI,omitempty"`
+	// MissingAPIPolicy action taken when a required API is not available: skip or error
+	MissingAPIPolicy MissingAPIPolicy `yaml:"missingAPIPolicy" json:"missingAPIPolicy,omitempty"`
+	// FailureEvents maximum number of events captured per failed creation or readiness wait, 0 disables it
+	FailureEvents int `yaml:"failureEvents" json:"failureEvents,omitempty"`
+	// PodLogs captures the logs of a sample of the pods created by the job
+	PodLogs *PodLogs `yaml:"podLogs" json:"podLogs,omitempty"`
+	// PreJobCheck blocks the start of the job until the given Prometheus expression is true
+	PreJobCheck *PreJobCheck `yaml:"preJobCheck" json:"preJobCheck,omitempty"`
 }
 
 type WaitOptions struct {
 	// ForCondition wait for this condition to become true
 	ForCondition string `yaml:"forCondition" json:"forCondition,omitempty"`
+	// ForConditions wait for all these conditions to become true
+	ForConditions []string `yaml:"forConditions" json:"forConditions,omitempty"`
+	// ForJSONPath wait for this JSONPath expression to evaluate to Value
+	ForJSONPath string `yaml:"forJSONPath" json:"forJSONPath,omitempty"`
+	// Value expected value of the ForJSONPath expression
+	Value string `yaml:"value" json:"value,omitempty"`
+	// ForPVCsBound wait for the PersistentVolumeClaims of the namespace to be Bound
+	ForPVCsBound bool `yaml:"forPVCsBound" json:"forPVCsBound,omitempty"`
 }
@@ -0,0 +1,250 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurements
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/types"
+	log "github.com/sirupsen/logrus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	apiErrorsMeasurement        = "apiErrorsMeasurement"
+	apiErrorsSummaryMeasurement = "apiErrorsSummary"
+	// clientErrorReason reason of the errors returned before getting a response from the API server, i.e. timeouts
+	clientErrorReason = "ClientError"
+)
+
+// apiErrorsMetric holds the errors of a verb, kind and status code
+type apiErrorsMetric struct {
+	Timestamp  time.Time   `json:"timestamp"`
+	Verb       string      `json:"verb"`
+	Kind       string      `json:"kind"`
+	Code       int32       `json:"code"`
+	Reason     string      `json:"reason"`
+	Count      int         `json:"count"`
+	Rate       float64     `json:"rate"`
+	Ratio      float64     `json:"ratio"`
+	MetricName string      `json:"metricName"`
+	JobName    string      `json:"jobName"`
+	UUID       string      `json:"uuid"`
+	Metadata   interface{} `json:"metadata,omitempty"`
+}
+
+// apiErrorsSummary holds the API calls and errors of a job
+type apiErrorsSummary struct {
+	Timestamp  time.Time   `json:"timestamp"`
+	Calls      int         `json:"calls"`
+	Errors     int         `json:"errors"`
+	ErrorRate  float64     `json:"errorRate"`
+	ErrorRatio float64     `json:"errorRatio"`
+	Duration   float64     `json:"duration"`
+	MetricName string      `json:"metricName"`
+	JobName    string      `json:"jobName"`
+	UUID       string      `json:"uuid"`
+	Metadata   interface{} `json:"metadata,omitempty"`
+}
+
+// apiErrorKey groups the errors by verb, kind and status code
+type apiErrorKey struct {
+	verb   string
+	kind   string
+	code   int32
+	reason string
+}
+
+// apiErrors counts the errors of the create, apply and patch API calls performed by the burner, client-side,
+// so it doesn't require Prometheus. The rate is the number of errors per second of job
+type apiErrors struct {
+	config    types.Measurement
+	lock      sync.Mutex
+	started   bool
+	startTime time.Time
+	calls     map[string]int
+	errors    map[apiErrorKey]int
+}
+
+func init() {
+	measurementMap["apiErrors"] = &apiErrors{}
+}
+
+// recordAPIError records the result of an API call, counting it as an error when err isn't nil
+func recordAPIError(verb, kind string, err error) {
+	m, enabled := factory.createFuncs["apiErrors"]
+	if !enabled {
+		return
+	}
+	m.(*apiErrors).record(verb, kind, err)
+}
+
+func (a *apiErrors) record(verb, kind string, err error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if !a.started {
+		return
+	}
+	a.calls[verb+"/"+kind]++
+	if err == nil {
+		return
+	}
+	key := apiErrorKey{verb: verb, kind: kind, reason: clientErrorReason}
+	var status kerrors.APIStatus
+	if errors.As(err, &status) {
+		key.code = status.Status().Code
+		key.reason = string(kerrors.ReasonForError(err))
+		if key.reason == string(metav1.StatusReasonUnknown) {
+			key.reason = strconv.Itoa(int(key.code))
+		}
+	}
+	a.errors[key]++
+}
+
+func (a *apiErrors) setConfig(cfg types.Measurement) error {
+	a.config = cfg
+	return nil
+}
+
+// start starts counting the API calls of the job
+func (a *apiErrors) start(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.calls = make(map[string]int)
+	a.errors = make(map[apiErrorKey]int)
+	a.startTime = time.Now()
+	a.started = true
+}
+
+func (a *apiErrors) collect(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+}
+
+// stop stops counting API calls and indexes the errors of each verb, kind and status code
+func (a *apiErrors) stop() error {
+	a.lock.Lock()
+	a.started = false
+	a.lock.Unlock()
+	apiErrorList, summary := a.summarize()
+	for _, e := range apiErrorList {
+		m := e.(apiErrorsMetric)
+		log.Warnf("%s: %d %s/%s errors with code %d (%s), %.2f%% of the calls", factory.jobConfig.Name, m.Count, m.Verb, m.Kind, m.Code, m.Reason, m.Ratio*100)
+	}
+	log.Infof("%s: %d API errors out of %d calls, %.3f errors/s", factory.jobConfig.Name, summary.Errors, summary.Calls, summary.ErrorRate)
+	if globalCfg.IndexerConfig.Type != "" {
+		if factory.jobConfig.SkipIndexing {
+			log.Infof("Skipping API errors data indexing in job: %s", factory.jobConfig.Name)
+		} else {
+			a.index(apiErrorList, summary)
+		}
+	}
+	rows := []csvRow{
+		{metric: "apiErrors", quantile: "calls", value: strconv.Itoa(summary.Calls)},
+		{metric: "apiErrors", quantile: "errors", value: strconv.Itoa(summary.Errors)},
+	}
+	for _, e := range apiErrorList {
+		m := e.(apiErrorsMetric)
+		rows = append(rows, csvRow{metric: fmt.Sprintf("%s/%s", m.Verb, m.Kind), quantile: strconv.Itoa(int(m.Code)), value: strconv.Itoa(m.Count)})
+	}
+	exportCSV("apiErrors", rows)
+	a.calls, a.errors = nil, nil
+	return nil
+}
+
+// summarize returns the errors of each verb, kind and status code, sorted by count, and the summary of the job
+func (a *apiErrors) summarize() ([]interface{}, apiErrorsSummary) {
+	now := time.Now().UTC()
+	duration := now.Sub(a.startTime).Seconds()
+	summary := apiErrorsSummary{
+		Timestamp:  now,
+		Duration:   duration,
+		MetricName: apiErrorsSummaryMeasurement,
+		JobName:    factory.jobConfig.Name,
+		UUID:       globalCfg.UUID,
+		Metadata:   factory.metadata,
+	}
+	for _, calls := range a.calls {
+		summary.Calls += calls
+	}
+	var metrics []apiErrorsMetric
+	for key, count := range a.errors {
+		summary.Errors += count
+		m := apiErrorsMetric{
+			Timestamp:  now,
+			Verb:       key.verb,
+			Kind:       key.kind,
+			Code:       key.code,
+			Reason:     key.reason,
+			Count:      count,
+			Ratio:      float64(count) / float64(a.calls[key.verb+"/"+key.kind]),
+			MetricName: apiErrorsMeasurement,
+			JobName:    factory.jobConfig.Name,
+			UUID:       globalCfg.UUID,
+			Metadata:   factory.metadata,
+		}
+		if duration > 0 {
+			m.Rate = float64(count) / duration
+		}
+		metrics = append(metrics, m)
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].Count != metrics[j].Count {
+			return metrics[i].Count > metrics[j].Count
+		}
+		return fmt.Sprintf("%s/%s/%d", metrics[i].Verb, metrics[i].Kind, metrics[i].Code) < fmt.Sprintf("%s/%s/%d", metrics[j].Verb, metrics[j].Kind, metrics[j].Code)
+	})
+	if summary.Calls > 0 {
+		summary.ErrorRatio = float64(summary.Errors) / float64(summary.Calls)
+	}
+	if duration > 0 {
+		summary.ErrorRate = float64(summary.Errors) / duration
+	}
+	apiErrorList := make([]interface{}, len(metrics))
+	for i, m := range metrics {
+		apiErrorList[i] = m
+	}
+	return apiErrorList, summary
+}
+
+// index sends metrics to the configured indexer
+func (a *apiErrors) index(apiErrorList []interface{}, summary apiErrorsSummary) {
+	log.Infof("Indexing API errors data for job: %s", factory.jobConfig.Name)
+	metricMap := map[string][]interface{}{
+		apiErrorsSummaryMeasurement: {summary},
+	}
+	if len(apiErrorList) > 0 {
+		metricMap[apiErrorsMeasurement] = apiErrorList
+	}
+	for metricName, data := range metricMap {
+		indexingOpts := indexers.IndexingOpts{
+			MetricName: fmt.Sprintf("%s-%s", metricName, factory.jobConfig.Name),
+		}
+		log.Debugf("Indexing [%d] documents: %s", len(data), metricName)
+		resp, err := (*factory.indexer).Index(data, indexingOpts)
+		if err != nil {
+			log.Error(err.Error())
+		} else {
+			log.Info(resp)
+		}
+	}
+}
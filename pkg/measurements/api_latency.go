@@ -0,0 +1,138 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurements
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/types"
+)
+
+const (
+	apiLatencyMeasurement = "apiLatencyMeasurement"
+)
+
+// apiCallMetric holds the latency of an API call performed by the burner
+type apiCallMetric struct {
+	Timestamp  time.Time   `json:"timestamp"`
+	Verb       string      `json:"verb"`
+	Kind       string      `json:"kind"`
+	Namespace  string      `json:"namespace"`
+	Name       string      `json:"name"`
+	Latency    int         `json:"latency"`
+	Error      bool        `json:"error"`
+	MetricName string      `json:"metricName"`
+	JobName    string      `json:"jobName"`
+	UUID       string      `json:"uuid"`
+	Metadata   interface{} `json:"metadata,omitempty"`
+}
+
+// apiLatency measures the latency of the create, apply and patch API calls performed by the burner,
+// unlike podLatency, it only accounts for the time taken by the API server to serve the request
+type apiLatency struct {
+	config    types.Measurement
+	calls     []apiCallMetric
+	callsLock sync.Mutex
+	started   bool
+}
+
+func init() {
+	measurementMap["apiLatency"] = &apiLatency{}
+}
+
+// RecordAPICall records the latency and result of an API call started at the given time, for the apiLatency and apiErrors
+// measurements respectively. It's a no-op when none of them is enabled
+func RecordAPICall(verb, kind, namespace, name string, start time.Time, err error) {
+	recordAPIError(verb, kind, err)
+	m, enabled := factory.createFuncs["apiLatency"]
+	if !enabled {
+		return
+	}
+	m.(*apiLatency).record(apiCallMetric{
+		Timestamp: start.UTC(),
+		Verb:      verb,
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Latency:   int(time.Since(start).Milliseconds()),
+		Error:     err != nil,
+	})
+}
+
+func (a *apiLatency) record(call apiCallMetric) {
+	a.callsLock.Lock()
+	defer a.callsLock.Unlock()
+	if !a.started {
+		return
+	}
+	call.MetricName = apiLatencyMeasurement
+	call.JobName = factory.jobConfig.Name
+	call.UUID = globalCfg.UUID
+	call.Metadata = factory.metadata
+	a.calls = append(a.calls, call)
+}
+
+func (a *apiLatency) setConfig(cfg types.Measurement) error {
+	a.config = cfg
+	for _, percentile := range cfg.Quantiles {
+		if percentile <= 0 || percentile > 100 {
+			return fmt.Errorf("invalid quantile %v in apiLatency measurement, it must be greater than 0 and lower or equal than 100", percentile)
+		}
+	}
+	for _, th := range cfg.LatencyThresholds {
+		if th.Percentile < 0 || th.Percentile > 100 {
+			return fmt.Errorf("invalid percentile %v in apiLatency measurement, it must be between 0 and 100", th.Percentile)
+		}
+	}
+	return nil
+}
+
+// start starts recording the API calls of the job
+func (a *apiLatency) start(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+	a.callsLock.Lock()
+	defer a.callsLock.Unlock()
+	a.calls = nil
+	a.started = true
+}
+
+func (a *apiLatency) collect(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+}
+
+// stop stops recording API calls and reports the latencies of the successful ones grouped by verb and kind, i.e. create/Deployment
+func (a *apiLatency) stop() error {
+	a.callsLock.Lock()
+	a.started = false
+	a.callsLock.Unlock()
+	report := latencyReport{
+		measurement: "apiLatency",
+		config:      a.config,
+		latencies:   map[string][]int{},
+	}
+	for _, call := range a.calls {
+		report.documents = append(report.documents, call)
+		if call.Error {
+			continue
+		}
+		quantileName := fmt.Sprintf("%s/%s", call.Verb, call.Kind)
+		report.latencies[quantileName] = append(report.latencies[quantileName], call.Latency)
+	}
+	// Reset the calls, required in multi-job benchmarks
+	a.calls = nil
+	return report.report()
+}
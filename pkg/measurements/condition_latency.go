@@ -0,0 +1,146 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurements
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/types"
+)
+
+const (
+	conditionLatencyMeasurement = "conditionLatencyMeasurement"
+)
+
+// conditionMetric holds the time an object waited for with conditions took to meet them
+type conditionMetric struct {
+	Timestamp  time.Time   `json:"timestamp"`
+	Kind       string      `json:"kind"`
+	Namespace  string      `json:"namespace"`
+	Name       string      `json:"name"`
+	Latency    int         `json:"latency"`
+	MetricName string      `json:"metricName"`
+	JobName    string      `json:"jobName"`
+	JobConfig  config.Job  `json:"jobConfig"`
+	UUID       string      `json:"uuid"`
+	Metadata   interface{} `json:"metadata,omitempty"`
+}
+
+// conditionLatency measures the time the objects waited for with forCondition or forConditions take to meet their conditions
+type conditionLatency struct {
+	config      types.Measurement
+	objects     map[string]conditionMetric
+	objectsLock sync.Mutex
+	started     bool
+}
+
+func init() {
+	measurementMap["conditionLatency"] = &conditionLatency{}
+}
+
+// RecordConditionReady records the time an object created at the given time met its conditions, objects are recorded once.
+// It's a no-op when the conditionLatency measurement isn't enabled
+func RecordConditionReady(kind, namespace, name, uid string, created, ready time.Time) {
+	m, enabled := factory.createFuncs["conditionLatency"]
+	if !enabled {
+		return
+	}
+	latency := int(ready.Sub(created).Milliseconds())
+	// Creation and transition timestamps have second precision
+	if latency < 0 {
+		latency = 0
+	}
+	m.(*conditionLatency).record(uid, conditionMetric{
+		Timestamp: created.UTC(),
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Latency:   latency,
+	})
+}
+
+func (c *conditionLatency) record(uid string, object conditionMetric) {
+	c.objectsLock.Lock()
+	defer c.objectsLock.Unlock()
+	if !c.started {
+		return
+	}
+	if _, exists := c.objects[uid]; exists {
+		return
+	}
+	object.MetricName = conditionLatencyMeasurement
+	object.JobName = factory.jobConfig.Name
+	object.JobConfig = *factory.jobConfig
+	object.UUID = globalCfg.UUID
+	object.Metadata = factory.metadata
+	c.objects[uid] = object
+}
+
+func (c *conditionLatency) setConfig(cfg types.Measurement) error {
+	c.config = cfg
+	for _, percentile := range cfg.Quantiles {
+		if percentile <= 0 || percentile > 100 {
+			return fmt.Errorf("invalid quantile %v in conditionLatency measurement, it must be greater than 0 and lower or equal than 100", percentile)
+		}
+	}
+	for _, th := range cfg.LatencyThresholds {
+		if th.ConditionType == "" {
+			return fmt.Errorf("conditionLatency thresholds require the conditionType, holding the kind of the objects")
+		}
+		if th.Percentile < 0 || th.Percentile > 100 {
+			return fmt.Errorf("invalid percentile %v in conditionLatency measurement, it must be between 0 and 100", th.Percentile)
+		}
+	}
+	return nil
+}
+
+// start starts recording the objects meeting their conditions
+func (c *conditionLatency) start(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+	c.objectsLock.Lock()
+	defer c.objectsLock.Unlock()
+	c.objects = make(map[string]conditionMetric)
+	c.started = factory.jobConfig.JobType == config.CreationJob
+}
+
+func (c *conditionLatency) collect(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+}
+
+// stop stops recording objects and reports their latencies grouped by kind
+func (c *conditionLatency) stop() error {
+	c.objectsLock.Lock()
+	started := c.started
+	c.started = false
+	c.objectsLock.Unlock()
+	if !started {
+		return nil
+	}
+	report := latencyReport{
+		measurement: "conditionLatency",
+		config:      c.config,
+		latencies:   map[string][]int{},
+	}
+	for _, object := range c.objects {
+		report.documents = append(report.documents, object)
+		report.latencies[object.Kind] = append(report.latencies[object.Kind], object.Latency)
+	}
+	// Reset the objects, required in multi-job benchmarks
+	c.objects = nil
+	return report.report()
+}
@@ -0,0 +1,245 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurements
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/metrics"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/types"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	containerRestartsMeasurement        = "containerRestartsMeasurement"
+	containerRestartsSummaryMeasurement = "containerRestartsSummary"
+)
+
+// containerRestartsMetric holds the container restarts of a pod
+type containerRestartsMetric struct {
+	Timestamp  time.Time        `json:"timestamp"`
+	Restarts   int32            `json:"restarts"`
+	Containers map[string]int32 `json:"containers"`
+	Flagged    bool             `json:"flagged"`
+	MetricName string           `json:"metricName"`
+	JobName    string           `json:"jobName"`
+	UUID       string           `json:"uuid"`
+	Namespace  string           `json:"namespace"`
+	Name       string           `json:"podName"`
+	NodeName   string           `json:"nodeName"`
+	Metadata   interface{}      `json:"metadata,omitempty"`
+}
+
+// containerRestartsSummary holds the container restarts of all the pods of a job
+type containerRestartsSummary struct {
+	Timestamp         time.Time   `json:"timestamp"`
+	Pods              int         `json:"pods"`
+	PodsWithRestarts  int         `json:"podsWithRestarts"`
+	PodsOverThreshold int         `json:"podsOverThreshold"`
+	TotalRestarts     int32       `json:"totalRestarts"`
+	MaxRestarts       int32       `json:"maxRestarts"`
+	Threshold         int32       `json:"threshold"`
+	MetricName        string      `json:"metricName"`
+	JobName           string      `json:"jobName"`
+	JobConfig         config.Job  `json:"jobConfig"`
+	UUID              string      `json:"uuid"`
+	Metadata          interface{} `json:"metadata,omitempty"`
+}
+
+// containerRestarts tallies the container restarts of the pods created by the job
+type containerRestarts struct {
+	config     types.Measurement
+	watcher    *metrics.Watcher
+	metrics    map[string]containerRestartsMetric
+	metricLock sync.Mutex
+}
+
+func init() {
+	measurementMap["containerRestarts"] = &containerRestarts{}
+}
+
+// handlePod records the restart count of every container of the pod, init containers included
+func (c *containerRestarts) handlePod(obj interface{}) {
+	pod := obj.(*corev1.Pod)
+	c.metricLock.Lock()
+	defer c.metricLock.Unlock()
+	cm, exists := c.metrics[string(pod.UID)]
+	if !exists {
+		cm = containerRestartsMetric{
+			Timestamp:  pod.CreationTimestamp.Time.UTC(),
+			Containers: make(map[string]int32),
+			MetricName: containerRestartsMeasurement,
+			JobName:    factory.jobConfig.Name,
+			UUID:       globalCfg.UUID,
+			Namespace:  pod.Namespace,
+			Name:       pod.Name,
+			Metadata:   factory.metadata,
+		}
+	}
+	cm.NodeName = pod.Spec.NodeName
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		// Restart counts only grow, the highest one observed is kept
+		if cs.RestartCount > cm.Containers[cs.Name] {
+			cm.Containers[cs.Name] = cs.RestartCount
+		}
+	}
+	cm.Restarts = 0
+	for _, restarts := range cm.Containers {
+		cm.Restarts += restarts
+	}
+	c.metrics[string(pod.UID)] = cm
+}
+
+func (c *containerRestarts) setConfig(cfg types.Measurement) error {
+	if cfg.RestartThreshold < 0 {
+		return fmt.Errorf("restartThreshold must be greater or equal than 0 in containerRestarts measurement")
+	}
+	c.config = cfg
+	return nil
+}
+
+// start starts watching the pods of the job
+func (c *containerRestarts) start(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+	if factory.jobConfig.JobType == config.DeletionJob {
+		log.Info("Container restarts measurement not compatible with delete jobs, skipping")
+		return
+	}
+	c.metrics = make(map[string]containerRestartsMetric)
+	log.Infof("Creating container restarts watcher for %s", factory.jobConfig.Name)
+	c.watcher = metrics.NewWatcher(
+		factory.clientSet.CoreV1().RESTClient().(*rest.RESTClient),
+		"containerRestartsWatcher",
+		"pods",
+		corev1.NamespaceAll,
+		informerListOptions(c.config, "pods"),
+	)
+	c.watcher.Informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.handlePod,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.handlePod(newObj)
+		},
+	})
+	if err := c.watcher.StartAndCacheSync(); err != nil {
+		log.Errorf("Container restarts measurement error: %s", err)
+	}
+}
+
+func (c *containerRestarts) collect(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+}
+
+// stop stops the watcher, flags the pods over the restart threshold and indexes the results
+func (c *containerRestarts) stop() error {
+	var err error
+	if factory.jobConfig.JobType == config.DeletionJob {
+		return nil
+	}
+	if c.watcher != nil {
+		c.watcher.StopWatcher()
+	}
+	podRestarts, summary := c.summarize()
+	if summary.PodsOverThreshold > 0 {
+		var flagged []string
+		for _, m := range podRestarts {
+			if pm := m.(containerRestartsMetric); pm.Flagged {
+				flagged = append(flagged, fmt.Sprintf("%s/%s: %d", pm.Namespace, pm.Name, pm.Restarts))
+			}
+		}
+		sort.Strings(flagged)
+		log.Warnf("%s: %d pods with more than %d container restarts: %s", factory.jobConfig.Name, summary.PodsOverThreshold, summary.Threshold, strings.Join(flagged, ", "))
+		if c.config.FailOnRestarts {
+			err = fmt.Errorf("%d pods with more than %d container restarts", summary.PodsOverThreshold, summary.Threshold)
+		}
+	}
+	if globalCfg.IndexerConfig.Type != "" {
+		if factory.jobConfig.SkipIndexing {
+			log.Infof("Skipping container restarts data indexing in job: %s", factory.jobConfig.Name)
+		} else {
+			c.index(podRestarts, summary)
+		}
+	}
+	log.Infof("%s: %d container restarts in %d/%d pods, max restarts per pod: %d", factory.jobConfig.Name, summary.TotalRestarts, summary.PodsWithRestarts, summary.Pods, summary.MaxRestarts)
+	return err
+}
+
+// summarize returns the pods with restarts, flagging the ones over the threshold, and the summary of the job
+func (c *containerRestarts) summarize() ([]interface{}, containerRestartsSummary) {
+	var podRestarts []interface{}
+	jc := *factory.jobConfig
+	jc.Objects = nil
+	summary := containerRestartsSummary{
+		Timestamp:  time.Now().UTC(),
+		Threshold:  c.config.RestartThreshold,
+		MetricName: containerRestartsSummaryMeasurement,
+		JobName:    factory.jobConfig.Name,
+		JobConfig:  jc,
+		UUID:       globalCfg.UUID,
+		Metadata:   factory.metadata,
+	}
+	c.metricLock.Lock()
+	defer c.metricLock.Unlock()
+	summary.Pods = len(c.metrics)
+	for _, m := range c.metrics {
+		if m.Restarts == 0 {
+			continue
+		}
+		m.Flagged = m.Restarts > c.config.RestartThreshold
+		if m.Flagged {
+			summary.PodsOverThreshold++
+		}
+		if m.Restarts > summary.MaxRestarts {
+			summary.MaxRestarts = m.Restarts
+		}
+		summary.PodsWithRestarts++
+		summary.TotalRestarts += m.Restarts
+		podRestarts = append(podRestarts, m)
+	}
+	return podRestarts, summary
+}
+
+// index sends metrics to the configured indexer
+func (c *containerRestarts) index(podRestarts []interface{}, summary containerRestartsSummary) {
+	log.Infof("Indexing container restarts data for job: %s", factory.jobConfig.Name)
+	metricMap := map[string][]interface{}{
+		containerRestartsMeasurement:        podRestarts,
+		containerRestartsSummaryMeasurement: {summary},
+	}
+	for metricName, data := range metricMap {
+		if len(data) == 0 {
+			continue
+		}
+		indexingOpts := indexers.IndexingOpts{
+			MetricName: fmt.Sprintf("%s-%s", metricName, factory.jobConfig.Name),
+		}
+		log.Debugf("Indexing [%d] documents: %s", len(data), metricName)
+		resp, err := (*factory.indexer).Index(data, indexingOpts)
+		if err != nil {
+			log.Error(err.Error())
+		} else {
+			log.Info(resp)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurements
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+var csvHeader = []string{"metric", "quantile", "value", "job", "uuid"}
+
+// csvRow single result of a measurement exported to CSV
+type csvRow struct {
+	metric   string
+	quantile string
+	value    string
+}
+
+// latencyQuantilesRows returns a row per percentile, max and avg latency of the given quantiles
+func latencyQuantilesRows(quantiles []interface{}, percentiles []float64) []csvRow {
+	var rows []csvRow
+	for _, q := range quantiles {
+		lq := q.(metrics.LatencyQuantiles)
+		labels, values := lq.Values(percentiles)
+		for i, label := range labels {
+			rows = append(rows, csvRow{metric: lq.QuantileName, quantile: label, value: strconv.Itoa(values[i])})
+		}
+	}
+	return rows
+}
+
+// exportCSV appends the results of the measurement to its CSV file in the configured CSV directory, regardless of the indexer.
+// The file is shared by all the jobs and runs, rows are identified by the job and uuid columns
+func exportCSV(measurement string, rows []csvRow) {
+	if globalCfg.CSVDirectory == "" || len(rows) == 0 {
+		return
+	}
+	if err := writeCSV(path.Join(globalCfg.CSVDirectory, measurement+".csv"), rows); err != nil {
+		log.Errorf("Error exporting %s results to CSV: %s", measurement, err)
+	}
+}
+
+func writeCSV(csvFile string, rows []csvRow) error {
+	if err := os.MkdirAll(path.Dir(csvFile), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(csvFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	if info.Size() == 0 {
+		w.Write(csvHeader)
+	}
+	for _, row := range rows {
+		w.Write([]string{row.metric, row.quantile, row.value, factory.jobConfig.Name, globalCfg.UUID})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("error writing %s: %s", csvFile, err)
+	}
+	log.Infof("Measurement results written to %s", csvFile)
+	return nil
+}
@@ -22,6 +22,9 @@ import (
 	"github.com/cloud-bulldozer/kube-burner/pkg/config"
 	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/types"
 	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/kubernetes"
 
@@ -44,18 +47,22 @@ type measurement interface {
 	setConfig(types.Measurement) error
 }
 
+// flusher is implemented by the measurements able to index the datapoints completed so far while they keep running
+type flusher interface {
+	flush() error
+}
+
 var factory measurementFactory
 var measurementMap = make(map[string]measurement)
 var globalCfg config.GlobalConfig
 
 // NewMeasurementFactory initializes the measurement facture
-func NewMeasurementFactory(configSpec config.Spec, indexer *indexers.Indexer, metadata map[string]interface{}) {
+func NewMeasurementFactory(configSpec config.Spec, indexer *indexers.Indexer, metadata map[string]interface{}) error {
 	globalCfg = configSpec.GlobalConfig
-	_, restConfig, err := config.GetClientSet(0, 0)
+	clientSet, restConfig, err := config.GetClientSet(0, 0)
 	if err != nil {
-		log.Fatalf("Error creating clientSet: %s", err)
+		return fmt.Errorf("error creating clientSet: %s", err)
 	}
-	clientSet := kubernetes.NewForConfigOrDie(restConfig)
 	factory = measurementFactory{
 		clientSet:   clientSet,
 		restConfig:  restConfig,
@@ -66,18 +73,24 @@ func NewMeasurementFactory(configSpec config.Spec, indexer *indexers.Indexer, me
 	for _, measurement := range globalCfg.Measurements {
 		if measurementFunc, exists := measurementMap[measurement.Name]; exists {
 			if err := factory.register(measurement, measurementFunc); err != nil {
-				log.Fatal(err.Error())
+				return err
 			}
 		} else {
 			log.Warnf("Measurement not found: %s", measurement.Name)
 		}
 	}
+	return nil
 }
 
 func (mf *measurementFactory) register(measurement types.Measurement, measurementFunc measurement) error {
 	if _, exists := mf.createFuncs[measurement.Name]; exists {
 		log.Warnf("Measurement already registered: %s", measurement.Name)
 	} else {
+		for resource, selector := range measurement.Selectors {
+			if _, err := fields.ParseSelector(selector.FieldSelector); err != nil {
+				return fmt.Errorf("Invalid field selector for %s: %s", resource, err)
+			}
+		}
 		if err := measurementFunc.setConfig(measurement); err != nil {
 			return fmt.Errorf("Config validation error: %s", err)
 		}
@@ -87,6 +100,44 @@ func (mf *measurementFactory) register(measurement types.Measurement, measuremen
 	return nil
 }
 
+// Validate validates the configuration of the given measurements without contacting the cluster, returning all the problems found
+func Validate(measurements []types.Measurement) []error {
+	var errs []error
+	for _, measurement := range measurements {
+		measurementFunc, exists := measurementMap[measurement.Name]
+		if !exists {
+			errs = append(errs, fmt.Errorf("measurement not found: %s", measurement.Name))
+			continue
+		}
+		for resource, selector := range measurement.Selectors {
+			if _, err := fields.ParseSelector(selector.FieldSelector); err != nil {
+				errs = append(errs, fmt.Errorf("measurement %s: invalid field selector for %s: %s", measurement.Name, resource, err))
+			}
+			if _, err := labels.ValidatedSelectorFromSet(selector.LabelSelector); err != nil {
+				errs = append(errs, fmt.Errorf("measurement %s: invalid label selector for %s: %s", measurement.Name, resource, err))
+			}
+		}
+		if err := measurementFunc.setConfig(measurement); err != nil {
+			errs = append(errs, fmt.Errorf("measurement %s: %s", measurement.Name, err))
+		}
+	}
+	return errs
+}
+
+// informerListOptions returns the list options modifier used by the informer of the given resource.
+// Informers are always scoped to the objects created by this run
+func informerListOptions(cfg types.Measurement, resource string) func(options *metav1.ListOptions) {
+	labelSelector := labels.Set{"kube-burner-runid": globalCfg.RUNID}
+	selector := cfg.Selectors[resource]
+	for k, v := range selector.LabelSelector {
+		labelSelector[k] = v
+	}
+	return func(options *metav1.ListOptions) {
+		options.LabelSelector = labelSelector.String()
+		options.FieldSelector = selector.FieldSelector
+	}
+}
+
 func SetJobConfig(jobConfig *config.Job) {
 	factory.jobConfig = jobConfig
 }
@@ -110,6 +161,19 @@ func Collect() {
 	wg.Wait()
 }
 
+// Flush indexes the datapoints completed so far by the registered measurements supporting it, without stopping them.
+// The rest of measurements index their datapoints when stopped
+func Flush() error {
+	errs := []error{}
+	for name, measurement := range factory.createFuncs {
+		if f, ok := measurement.(flusher); ok {
+			log.Infof("Flushing measurement: %s", name)
+			errs = append(errs, f.flush())
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
 // Stop stops registered measurements
 // returns a concatenated list of error strings with a new line between each string
 func Stop() error {
@@ -0,0 +1,233 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurements
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/metrics"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/types"
+	log "github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	jobLatencyMeasurement = "jobLatencyMeasurement"
+	jobStarted            = "Started"
+	jobComplete           = string(batchv1.JobComplete)
+)
+
+// jobMetric holds the start and completion latencies of a batch/v1 Job
+type jobMetric struct {
+	Timestamp         time.Time `json:"timestamp"`
+	started           time.Time
+	completed         time.Time
+	failed            bool
+	StartLatency      int         `json:"startLatency"`
+	CompletionLatency int         `json:"completionLatency"`
+	Completions       int32       `json:"completions"`
+	MetricName        string      `json:"metricName"`
+	JobName           string      `json:"jobName"`
+	JobConfig         config.Job  `json:"jobConfig"`
+	UUID              string      `json:"uuid"`
+	Namespace         string      `json:"namespace"`
+	Name              string      `json:"k8sJobName"`
+	Metadata          interface{} `json:"metadata,omitempty"`
+}
+
+// jobLatency measures the time batch/v1 Jobs take from their creation until they start and until they're completed
+type jobLatency struct {
+	config     types.Measurement
+	watcher    *metrics.Watcher
+	metrics    map[string]jobMetric
+	metricLock sync.Mutex
+}
+
+func init() {
+	measurementMap["jobLatency"] = &jobLatency{}
+}
+
+// handleJob records the creation of the Job, its start time and the first time it's observed completed
+func (j *jobLatency) handleJob(obj interface{}) {
+	job := obj.(*batchv1.Job)
+	j.metricLock.Lock()
+	defer j.metricLock.Unlock()
+	jm, exists := j.metrics[string(job.UID)]
+	if !exists {
+		jm = jobMetric{
+			Timestamp:   job.CreationTimestamp.Time.UTC(),
+			Namespace:   job.Namespace,
+			Name:        job.Name,
+			Completions: 1,
+			MetricName:  jobLatencyMeasurement,
+			UUID:        globalCfg.UUID,
+			JobConfig:   *factory.jobConfig,
+			JobName:     factory.jobConfig.Name,
+			Metadata:    factory.metadata,
+		}
+		if job.Spec.Completions != nil {
+			jm.Completions = *job.Spec.Completions
+		}
+	}
+	if job.Status.StartTime != nil && jm.started.IsZero() {
+		jm.started = job.Status.StartTime.Time.UTC()
+	}
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			if jm.completed.IsZero() {
+				log.Debugf("Job %s/%s is completed", job.Namespace, job.Name)
+				jm.completed = time.Now().UTC()
+			}
+		case batchv1.JobFailed:
+			jm.failed = true
+		}
+	}
+	j.metrics[string(job.UID)] = jm
+}
+
+func (j *jobLatency) setConfig(cfg types.Measurement) error {
+	j.config = cfg
+	latencyMetrics := []string{"P99", "P95", "P50", "Avg", "Max"}
+	if len(cfg.Quantiles) > 0 {
+		latencyMetrics = []string{"Avg", "Max"}
+	}
+	for _, percentile := range cfg.Quantiles {
+		if percentile <= 0 || percentile > 100 {
+			return fmt.Errorf("invalid quantile %v in jobLatency measurement, it must be greater than 0 and lower or equal than 100", percentile)
+		}
+		latencyMetrics = append(latencyMetrics, metrics.PercentileLabel(percentile))
+	}
+	for i, th := range cfg.LatencyThresholds {
+		if th.ConditionType == "" {
+			j.config.LatencyThresholds[i].ConditionType = jobComplete
+		} else if th.ConditionType != jobComplete && th.ConditionType != jobStarted {
+			return fmt.Errorf("unsupported condition type in jobLatency measurement: %s", th.ConditionType)
+		}
+		if th.Percentile != 0 {
+			if th.Percentile < 0 || th.Percentile > 100 {
+				return fmt.Errorf("invalid percentile %v in jobLatency measurement, it must be between 0 and 100", th.Percentile)
+			}
+			continue
+		}
+		var metricFound bool
+		for _, lm := range latencyMetrics {
+			if th.Metric == lm {
+				metricFound = true
+				break
+			}
+		}
+		if !metricFound {
+			return fmt.Errorf("unsupported metric %s in jobLatency measurement, supported are: %s", th.Metric, strings.Join(latencyMetrics, ", "))
+		}
+	}
+	return nil
+}
+
+// start starts watching the batch/v1 Jobs of the job
+func (j *jobLatency) start(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+	if factory.jobConfig.JobType == config.DeletionJob {
+		log.Info("Job latency measurement not compatible with delete jobs, skipping")
+		return
+	}
+	j.metrics = make(map[string]jobMetric)
+	log.Infof("Creating Job latency watcher for %s", factory.jobConfig.Name)
+	j.watcher = metrics.NewWatcher(
+		factory.clientSet.BatchV1().RESTClient().(*rest.RESTClient),
+		"jobWatcher",
+		"jobs",
+		corev1.NamespaceAll,
+		informerListOptions(j.config, "jobs"),
+	)
+	j.watcher.Informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: j.handleJob,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			j.handleJob(newObj)
+		},
+	})
+	if err := j.watcher.StartAndCacheSync(); err != nil {
+		log.Errorf("Job Latency measurement error: %s", err)
+	}
+}
+
+func (j *jobLatency) collect(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+}
+
+// stop stops the watcher and reports the start and completion latencies of the completed Jobs
+func (j *jobLatency) stop() error {
+	if factory.jobConfig.JobType == config.DeletionJob {
+		return nil
+	}
+	if j.watcher != nil {
+		j.watcher.StopWatcher()
+	}
+	report := latencyReport{
+		measurement: "jobLatency",
+		config:      j.config,
+		latencies:   map[string][]int{},
+	}
+	for _, m := range j.normalizeMetrics() {
+		report.documents = append(report.documents, m)
+		report.latencies[jobStarted] = append(report.latencies[jobStarted], m.StartLatency)
+		report.latencies[jobComplete] = append(report.latencies[jobComplete], m.CompletionLatency)
+	}
+	return report.report()
+}
+
+// normalizeMetrics returns the completed Jobs with their latencies calculated, failed Jobs are not accounted
+func (j *jobLatency) normalizeMetrics() []jobMetric {
+	var failed int
+	var normLatencies []jobMetric
+	j.metricLock.Lock()
+	defer j.metricLock.Unlock()
+	for _, m := range j.metrics {
+		if m.failed {
+			failed++
+			continue
+		}
+		if m.completed.IsZero() {
+			log.Tracef("Job %s/%s latency ignored as it wasn't completed", m.Namespace, m.Name)
+			continue
+		}
+		m.CompletionLatency = int(m.completed.Sub(m.Timestamp).Milliseconds())
+		if !m.started.IsZero() {
+			m.StartLatency = int(m.started.Sub(m.Timestamp).Milliseconds())
+		}
+		// Creation and start timestamps have second precision
+		if m.CompletionLatency < 0 {
+			m.CompletionLatency = 0
+		}
+		if m.StartLatency < 0 {
+			m.StartLatency = 0
+		}
+		normLatencies = append(normLatencies, m)
+	}
+	if failed > 0 {
+		log.Warnf("%d Jobs failed, their latencies are not accounted", failed)
+	}
+	return normLatencies
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurements
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/metrics"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/types"
+	log "github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// latencyReport summarizes the latencies of the measurements grouping them by a quantile name, like the kind of the objects.
+// The raw documents are indexed as <measurement>Measurement and the quantiles as <measurement>QuantilesMeasurement
+type latencyReport struct {
+	// measurement name of the measurement, i.e. patchLatency
+	measurement string
+	config      types.Measurement
+	// documents raw latency documents
+	documents []interface{}
+	// latencies latencies of each quantile name
+	latencies map[string][]int
+}
+
+// report calculates the latency quantiles, checks the thresholds, indexes the documents honoring latencyMetrics,
+// exports the quantiles to CSV and logs them. The threshold errors are returned
+func (r latencyReport) report() error {
+	var err error
+	quantiles := r.calcQuantiles()
+	if len(r.config.LatencyThresholds) > 0 {
+		err = utilerrors.NewAggregate([]error{
+			metrics.CheckThreshold(r.measurement, r.config.LatencyThresholds, quantiles),
+			metrics.CheckPercentileThresholds(r.measurement, r.config.LatencyThresholds, r.latencies),
+		})
+	}
+	if globalCfg.IndexerConfig.Type != "" {
+		if factory.jobConfig.SkipIndexing {
+			log.Infof("Skipping %s data indexing in job: %s", r.measurement, factory.jobConfig.Name)
+		} else {
+			r.index(quantiles)
+		}
+	}
+	exportCSV(r.measurement, latencyQuantilesRows(quantiles, r.config.Quantiles))
+	for _, q := range quantiles {
+		lq := q.(metrics.LatencyQuantiles)
+		log.Infof("%s: %s %s %s", factory.jobConfig.Name, r.measurement, lq.QuantileName, lq.Summary(r.config.Quantiles))
+	}
+	return err
+}
+
+// calcQuantiles sorts the latencies of each quantile name and calculates their quantiles
+func (r latencyReport) calcQuantiles() []interface{} {
+	var quantiles []interface{}
+	for name, latencies := range r.latencies {
+		sort.Ints(latencies)
+		lq := metrics.LatencyQuantiles{
+			QuantileName: name,
+			UUID:         globalCfg.UUID,
+			Timestamp:    time.Now().UTC(),
+			JobName:      factory.jobConfig.Name,
+			JobConfig:    *factory.jobConfig,
+			MetricName:   r.measurement + "QuantilesMeasurement",
+			Metadata:     factory.metadata,
+		}
+		if len(r.config.Quantiles) > 0 {
+			for _, percentile := range r.config.Quantiles {
+				lq.SetPercentile(percentile, metrics.Percentile(latencies, percentile))
+			}
+		} else {
+			for _, quantile := range []float64{0.5, 0.95, 0.99} {
+				lq.SetQuantile(quantile, metrics.Percentile(latencies, quantile*100))
+			}
+		}
+		lq.Max = latencies[len(latencies)-1]
+		sum := 0
+		for _, n := range latencies {
+			sum += n
+		}
+		lq.Avg = int(math.Round(float64(sum) / float64(len(latencies))))
+		quantiles = append(quantiles, lq)
+	}
+	return quantiles
+}
+
+// index sends the documents and the quantiles to the configured indexer
+func (r latencyReport) index(quantiles []interface{}) {
+	log.Infof("Indexing %s data for job: %s", r.measurement, factory.jobConfig.Name)
+	metricMap := map[string][]interface{}{
+		r.measurement + "Measurement":          r.documents,
+		r.measurement + "QuantilesMeasurement": quantiles,
+	}
+	if r.config.LatencyMetrics == types.Quantiles {
+		delete(metricMap, r.measurement+"Measurement")
+	}
+	for metricName, data := range metricMap {
+		indexingOpts := indexers.IndexingOpts{
+			MetricName: fmt.Sprintf("%s-%s", metricName, factory.jobConfig.Name),
+		}
+		log.Debugf("Indexing [%d] documents: %s", len(data), metricName)
+		resp, err := (*factory.indexer).Index(data, indexingOpts)
+		if err != nil {
+			log.Error(err.Error())
+		} else {
+			log.Info(resp)
+		}
+	}
+}
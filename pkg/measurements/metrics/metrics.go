@@ -15,8 +15,12 @@
 package metrics
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cloud-bulldozer/kube-burner/pkg/config"
@@ -39,6 +43,75 @@ type LatencyQuantiles struct {
 	JobName      string      `json:"jobName"`
 	JobConfig    config.Job  `json:"jobConfig"`
 	Metadata     interface{} `json:"metadata,omitempty"`
+	// percentiles configured percentiles, indexed by label, replacing P99, P95 and P50 when set
+	percentiles map[string]int
+}
+
+// PercentileLabel returns the label of the given percentile, i.e. P99_9. The decimal point is replaced by an underscore,
+// since indexers interpret the dots in field names as nested objects
+func PercentileLabel(percentile float64) string {
+	return "P" + strings.Replace(strconv.FormatFloat(percentile, 'f', -1, 64), ".", "_", 1)
+}
+
+// SetPercentile adds the value of the given percentile, between 0 and 100
+func (plq *LatencyQuantiles) SetPercentile(percentile float64, qValue int) {
+	if plq.percentiles == nil {
+		plq.percentiles = make(map[string]int)
+	}
+	plq.percentiles[PercentileLabel(percentile)] = qValue
+	plq.SetQuantile(percentile/100, qValue)
+}
+
+// Summary returns the 50th and 99th percentiles, or the given percentiles when set, along with the max and avg latencies
+func (plq LatencyQuantiles) Summary(percentiles []float64) string {
+	if len(percentiles) == 0 {
+		return fmt.Sprintf("50th: %v 99th: %v max: %v avg: %v", plq.P50, plq.P99, plq.Max, plq.Avg)
+	}
+	var summary string
+	for _, percentile := range percentiles {
+		label := PercentileLabel(percentile)
+		summary += fmt.Sprintf("%s: %v ", label, plq.percentiles[label])
+	}
+	return fmt.Sprintf("%smax: %v avg: %v", summary, plq.Max, plq.Avg)
+}
+
+// Values returns the labels and values of the 99th, 95th and 50th percentiles, or the given percentiles when set,
+// followed by the max and avg latencies
+func (plq LatencyQuantiles) Values(percentiles []float64) ([]string, []int) {
+	labels := []string{"P99", "P95", "P50"}
+	values := []int{plq.P99, plq.P95, plq.P50}
+	if len(percentiles) > 0 && plq.percentiles != nil {
+		labels, values = nil, nil
+		for _, percentile := range percentiles {
+			label := PercentileLabel(percentile)
+			labels = append(labels, label)
+			values = append(values, plq.percentiles[label])
+		}
+	}
+	return append(labels, "Max", "Avg"), append(values, plq.Max, plq.Avg)
+}
+
+// MarshalJSON replaces the default quantile fields with the configured percentiles when set
+func (plq LatencyQuantiles) MarshalJSON() ([]byte, error) {
+	type rawLatencyQuantiles LatencyQuantiles
+	if plq.percentiles == nil {
+		return json.Marshal(rawLatencyQuantiles(plq))
+	}
+	raw, err := json.Marshal(rawLatencyQuantiles(plq))
+	if err != nil {
+		return nil, err
+	}
+	doc := make(map[string]interface{})
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	delete(doc, "P99")
+	delete(doc, "P95")
+	delete(doc, "P50")
+	for label, v := range plq.percentiles {
+		doc[label] = v
+	}
+	return json.Marshal(doc)
 }
 
 // SetQuantile adds quantile value
@@ -53,20 +126,30 @@ func (plq *LatencyQuantiles) SetQuantile(quantile float64, qValue int) {
 	}
 }
 
-// CheckThreshold checks latency thresholds
+// CheckThreshold checks the latency thresholds of the given measurement
 // returns a concatenated list of error strings with a new line between each string
-func CheckThreshold(thresholds []types.LatencyThreshold, quantiles []interface{}) error {
+func CheckThreshold(measurement string, thresholds []types.LatencyThreshold, quantiles []interface{}) error {
 	errs := []error{}
 	log.Info("Evaluating latency thresholds")
 	for _, phase := range thresholds {
+		// Percentile thresholds are checked by CheckPercentileThresholds
+		if phase.Percentile > 0 {
+			continue
+		}
 		for _, pq := range quantiles {
 			if phase.ConditionType == pq.(LatencyQuantiles).QuantileName {
-				// Required to acccess the attribute by name
-				r := reflect.ValueOf(pq.(LatencyQuantiles))
-				v := r.FieldByName(phase.Metric).Int()
+				var v int64
+				// Configured percentiles are looked up by label
+				if value, ok := pq.(LatencyQuantiles).percentiles[phase.Metric]; ok {
+					v = int64(value)
+				} else {
+					// Required to acccess the attribute by name
+					r := reflect.ValueOf(pq.(LatencyQuantiles))
+					v = r.FieldByName(phase.Metric).Int()
+				}
 				if v > phase.Threshold.Milliseconds() {
 					latency := float32(v) / 1000
-					err := fmt.Errorf("podLatency: %s %s latency (%.2fs) higher than configured threshold: %v", phase.Metric, phase.ConditionType, latency, phase.Threshold)
+					err := fmt.Errorf("%s: %s %s latency (%.2fs) higher than configured threshold: %v", measurement, phase.Metric, phase.ConditionType, latency, phase.Threshold)
 					errs = append(errs, err)
 				}
 			}
@@ -74,3 +157,29 @@ func CheckThreshold(thresholds []types.LatencyThreshold, quantiles []interface{}
 	}
 	return utilerrors.NewAggregate(errs)
 }
+
+// CheckPercentileThresholds checks the latency thresholds configured with a percentile against the given
+// sorted latencies, in milliseconds, indexed by condition type
+func CheckPercentileThresholds(measurement string, thresholds []types.LatencyThreshold, latencies map[string][]int) error {
+	errs := []error{}
+	for _, th := range thresholds {
+		if th.Percentile <= 0 || len(latencies[th.ConditionType]) == 0 {
+			continue
+		}
+		v := Percentile(latencies[th.ConditionType], th.Percentile)
+		if int64(v) > th.Threshold.Milliseconds() {
+			latency := float32(v) / 1000
+			errs = append(errs, fmt.Errorf("%s: P%v %s latency (%.2fs) higher than configured threshold: %v", measurement, th.Percentile, th.ConditionType, latency, th.Threshold))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// Percentile returns the given percentile, between 0 and 100, of the sorted values using the nearest-rank method
+func Percentile(sorted []int, percentile float64) int {
+	rank := int(math.Ceil(float64(len(sorted))*percentile/100)) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
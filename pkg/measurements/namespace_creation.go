@@ -0,0 +1,198 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurements
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/metrics"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/types"
+	log "github.com/sirupsen/logrus"
+)
+
+const namespaceCreationMeasurement = "namespaceCreationMeasurement"
+
+// namespaceCreationMetric holds the time taken to create the namespaces of a job
+type namespaceCreationMetric struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Namespaces int       `json:"namespaces"`
+	Errors     int       `json:"errors"`
+	// Duration milliseconds from the start of the first namespace creation until the end of the last one
+	Duration int `json:"duration"`
+	// Rate namespaces created per second
+	Rate       float64     `json:"rate"`
+	P99        int         `json:"P99"`
+	P95        int         `json:"P95"`
+	P50        int         `json:"P50"`
+	Max        int         `json:"max"`
+	Avg        int         `json:"avg"`
+	Delay      float64     `json:"namespaceCreationDelay"`
+	Jitter     float64     `json:"namespaceCreationJitter"`
+	MetricName string      `json:"metricName"`
+	JobName    string      `json:"jobName"`
+	JobConfig  config.Job  `json:"jobConfig"`
+	UUID       string      `json:"uuid"`
+	Metadata   interface{} `json:"metadata,omitempty"`
+}
+
+// namespaceCreation measures the time taken to create the namespaces of the creation jobs, so configurations
+// staggering the namespace creations can be compared
+type namespaceCreation struct {
+	config    types.Measurement
+	lock      sync.Mutex
+	started   bool
+	first     time.Time
+	last      time.Time
+	latencies []int
+	errors    int
+}
+
+func init() {
+	measurementMap["namespaceCreation"] = &namespaceCreation{}
+}
+
+// RecordNamespaceCreation records the creation of a namespace started at the given time, it's a no-op when the namespaceCreation measurement isn't enabled
+func RecordNamespaceCreation(namespace string, start time.Time, err error) {
+	m, enabled := factory.createFuncs["namespaceCreation"]
+	if !enabled {
+		return
+	}
+	m.(*namespaceCreation).record(namespace, start, time.Now(), err)
+}
+
+func (n *namespaceCreation) record(namespace string, start, end time.Time, err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if !n.started {
+		return
+	}
+	if n.first.IsZero() || start.Before(n.first) {
+		n.first = start
+	}
+	if end.After(n.last) {
+		n.last = end
+	}
+	if err != nil {
+		log.Debugf("Namespace %s creation failed, its latency is not accounted", namespace)
+		n.errors++
+		return
+	}
+	n.latencies = append(n.latencies, int(end.Sub(start).Milliseconds()))
+}
+
+func (n *namespaceCreation) setConfig(cfg types.Measurement) error {
+	n.config = cfg
+	return nil
+}
+
+// start starts recording the namespace creations of the job
+func (n *namespaceCreation) start(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.first, n.last, n.latencies, n.errors = time.Time{}, time.Time{}, nil, 0
+	n.started = factory.jobConfig.JobType == config.CreationJob
+}
+
+func (n *namespaceCreation) collect(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+}
+
+// stop stops recording namespace creations and indexes the time taken to create them
+func (n *namespaceCreation) stop() error {
+	n.lock.Lock()
+	started := n.started
+	n.started = false
+	n.lock.Unlock()
+	if !started || (len(n.latencies) == 0 && n.errors == 0) {
+		return nil
+	}
+	m := n.summarize()
+	log.Infof("%s: %d namespaces created in %v, %.2f namespaces/s, %d errors. Creation latency: P99 %dms, P95 %dms, P50 %dms, max %dms, avg %dms",
+		factory.jobConfig.Name, m.Namespaces, time.Duration(m.Duration)*time.Millisecond, m.Rate, m.Errors, m.P99, m.P95, m.P50, m.Max, m.Avg)
+	if globalCfg.IndexerConfig.Type != "" {
+		if factory.jobConfig.SkipIndexing {
+			log.Infof("Skipping namespace creation data indexing in job: %s", factory.jobConfig.Name)
+		} else {
+			n.index(m)
+		}
+	}
+	exportCSV("namespaceCreation", []csvRow{
+		{metric: "namespaceCreation", quantile: "namespaces", value: strconv.Itoa(m.Namespaces)},
+		{metric: "namespaceCreation", quantile: "duration", value: strconv.Itoa(m.Duration)},
+		{metric: "namespaceCreation", quantile: "P99", value: strconv.Itoa(m.P99)},
+		{metric: "namespaceCreation", quantile: "P95", value: strconv.Itoa(m.P95)},
+		{metric: "namespaceCreation", quantile: "P50", value: strconv.Itoa(m.P50)},
+		{metric: "namespaceCreation", quantile: "max", value: strconv.Itoa(m.Max)},
+		{metric: "namespaceCreation", quantile: "avg", value: strconv.Itoa(m.Avg)},
+	})
+	n.latencies = nil
+	return nil
+}
+
+// summarize returns the number of namespaces created, the total time taken and the latency quantiles
+func (n *namespaceCreation) summarize() namespaceCreationMetric {
+	m := namespaceCreationMetric{
+		Timestamp:  n.first.UTC(),
+		Namespaces: len(n.latencies),
+		Errors:     n.errors,
+		Duration:   int(n.last.Sub(n.first).Milliseconds()),
+		Delay:      factory.jobConfig.NamespaceCreationDelay.Seconds(),
+		Jitter:     factory.jobConfig.NamespaceCreationJitter.Seconds(),
+		MetricName: namespaceCreationMeasurement,
+		JobName:    factory.jobConfig.Name,
+		JobConfig:  *factory.jobConfig,
+		UUID:       globalCfg.UUID,
+		Metadata:   factory.metadata,
+	}
+	if m.Duration > 0 {
+		m.Rate = float64(m.Namespaces) / n.last.Sub(n.first).Seconds()
+	}
+	if len(n.latencies) == 0 {
+		return m
+	}
+	sort.Ints(n.latencies)
+	m.P99 = metrics.Percentile(n.latencies, 99)
+	m.P95 = metrics.Percentile(n.latencies, 95)
+	m.P50 = metrics.Percentile(n.latencies, 50)
+	m.Max = n.latencies[len(n.latencies)-1]
+	sum := 0
+	for _, latency := range n.latencies {
+		sum += latency
+	}
+	m.Avg = int(math.Round(float64(sum) / float64(len(n.latencies))))
+	return m
+}
+
+// index sends metrics to the configured indexer
+func (n *namespaceCreation) index(m namespaceCreationMetric) {
+	log.Infof("Indexing namespace creation data for job: %s", factory.jobConfig.Name)
+	indexingOpts := indexers.IndexingOpts{
+		MetricName: fmt.Sprintf("%s-%s", namespaceCreationMeasurement, factory.jobConfig.Name),
+	}
+	resp, err := (*factory.indexer).Index([]interface{}{m}, indexingOpts)
+	if err != nil {
+		log.Error(err.Error())
+	} else {
+		log.Info(resp)
+	}
+}
@@ -0,0 +1,322 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurements
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/metrics"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/types"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	nodePressureMeasurement        = "nodePressureMeasurement"
+	nodePressureSummaryMeasurement = "nodePressureSummary"
+)
+
+// pressureConditions node conditions reporting resource pressure
+var pressureConditions = []corev1.NodeConditionType{corev1.NodeMemoryPressure, corev1.NodeDiskPressure, corev1.NodePIDPressure}
+
+// nodePressureEvent holds a period a node reported a pressure condition during the job
+type nodePressureEvent struct {
+	Timestamp    time.Time `json:"timestamp"`
+	EndTimestamp time.Time `json:"endTimestamp"`
+	// Duration seconds the node was under pressure during the job
+	Duration float64 `json:"duration"`
+	// Ongoing the node was still under pressure when the job finished
+	Ongoing    bool        `json:"ongoing"`
+	NodeName   string      `json:"nodeName"`
+	Condition  string      `json:"condition"`
+	Reason     string      `json:"reason,omitempty"`
+	Message    string      `json:"message,omitempty"`
+	MetricName string      `json:"metricName"`
+	JobName    string      `json:"jobName"`
+	UUID       string      `json:"uuid"`
+	Metadata   interface{} `json:"metadata,omitempty"`
+}
+
+// conditionPressure holds the pressure events of a condition
+type conditionPressure struct {
+	Events int `json:"events"`
+	// Duration seconds accumulated by the events of the condition
+	Duration float64 `json:"duration"`
+}
+
+// nodePressureSummary holds the pressure events of all the nodes during a job
+type nodePressureSummary struct {
+	Timestamp          time.Time                    `json:"timestamp"`
+	Nodes              int                          `json:"nodes"`
+	NodesUnderPressure int                          `json:"nodesUnderPressure"`
+	Events             int                          `json:"events"`
+	Conditions         map[string]conditionPressure `json:"conditions"`
+	MetricName         string                       `json:"metricName"`
+	JobName            string                       `json:"jobName"`
+	JobConfig          config.Job                   `json:"jobConfig"`
+	UUID               string                       `json:"uuid"`
+	Metadata           interface{}                  `json:"metadata,omitempty"`
+}
+
+// nodePressure records the periods the nodes report memory, disk or PID pressure during each job
+type nodePressure struct {
+	config   types.Measurement
+	watcher  *metrics.Watcher
+	lock     sync.Mutex
+	jobStart time.Time
+	nodes    map[string]bool
+	// open events by node and condition
+	open   map[string]*nodePressureEvent
+	events []nodePressureEvent
+}
+
+func init() {
+	measurementMap["nodePressure"] = &nodePressure{}
+}
+
+// handleNode opens an event for every pressure condition the node starts reporting, and closes the ones it stops reporting
+func (n *nodePressure) handleNode(obj interface{}) {
+	node := obj.(*corev1.Node)
+	now := time.Now().UTC()
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.nodes[node.Name] = true
+	for _, c := range node.Status.Conditions {
+		if !isPressureCondition(c.Type) {
+			continue
+		}
+		key := node.Name + "/" + string(c.Type)
+		event, open := n.open[key]
+		if c.Status == corev1.ConditionTrue && !open {
+			// Pressure reported before the job started is accounted from the start of the job
+			start := c.LastTransitionTime.Time.UTC()
+			if start.Before(n.jobStart) || start.After(now) {
+				start = n.jobStart
+			}
+			log.Debugf("Node %s reports %s: %s", node.Name, c.Type, c.Message)
+			n.open[key] = &nodePressureEvent{
+				Timestamp:  start,
+				NodeName:   node.Name,
+				Condition:  string(c.Type),
+				Reason:     c.Reason,
+				Message:    c.Message,
+				MetricName: nodePressureMeasurement,
+				JobName:    factory.jobConfig.Name,
+				UUID:       globalCfg.UUID,
+				Metadata:   factory.metadata,
+			}
+		} else if c.Status != corev1.ConditionTrue && open {
+			end := c.LastTransitionTime.Time.UTC()
+			if end.Before(event.Timestamp) || end.After(now) {
+				end = now
+			}
+			n.closeEvent(key, end, false)
+		}
+	}
+}
+
+// handleNodeDeletion closes the open events of the deleted node
+func (n *nodePressure) handleNodeDeletion(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		return
+	}
+	now := time.Now().UTC()
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for _, conditionType := range pressureConditions {
+		if _, open := n.open[node.Name+"/"+string(conditionType)]; open {
+			n.closeEvent(node.Name+"/"+string(conditionType), now, false)
+		}
+	}
+}
+
+// closeEvent must be called with the lock held
+func (n *nodePressure) closeEvent(key string, end time.Time, ongoing bool) {
+	event := n.open[key]
+	delete(n.open, key)
+	event.EndTimestamp = end
+	event.Duration = end.Sub(event.Timestamp).Seconds()
+	event.Ongoing = ongoing
+	n.events = append(n.events, *event)
+}
+
+func isPressureCondition(conditionType corev1.NodeConditionType) bool {
+	for _, c := range pressureConditions {
+		if conditionType == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *nodePressure) setConfig(cfg types.Measurement) error {
+	n.config = cfg
+	return nil
+}
+
+// start starts watching the nodes of the cluster
+func (n *nodePressure) start(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+	n.lock.Lock()
+	n.jobStart = time.Now().UTC()
+	n.nodes = make(map[string]bool)
+	n.open = make(map[string]*nodePressureEvent)
+	n.events = nil
+	n.lock.Unlock()
+	log.Infof("Creating node pressure watcher for %s", factory.jobConfig.Name)
+	// Nodes aren't created by the run, so the informer isn't scoped to its objects
+	selector := n.config.Selectors["nodes"]
+	n.watcher = metrics.NewWatcher(
+		factory.clientSet.CoreV1().RESTClient().(*rest.RESTClient),
+		"nodePressureWatcher",
+		"nodes",
+		corev1.NamespaceAll,
+		func(options *metav1.ListOptions) {
+			options.LabelSelector = labels.Set(selector.LabelSelector).String()
+			options.FieldSelector = selector.FieldSelector
+		},
+	)
+	n.watcher.Informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: n.handleNode,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			n.handleNode(newObj)
+		},
+		DeleteFunc: n.handleNodeDeletion,
+	})
+	if err := n.watcher.StartAndCacheSync(); err != nil {
+		log.Errorf("Node pressure measurement error: %s", err)
+	}
+}
+
+func (n *nodePressure) collect(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+}
+
+// stop stops the watcher, closes the events still open and indexes the results
+func (n *nodePressure) stop() error {
+	var err error
+	if n.watcher != nil {
+		n.watcher.StopWatcher()
+	}
+	events, summary := n.summarize()
+	if summary.Events > 0 {
+		var nodes []string
+		for _, event := range events {
+			nodes = append(nodes, fmt.Sprintf("%s: %s %.0fs", event.NodeName, event.Condition, event.Duration))
+		}
+		sort.Strings(nodes)
+		log.Warnf("%s: %d node pressure events in %d nodes: %s", factory.jobConfig.Name, summary.Events, summary.NodesUnderPressure, strings.Join(nodes, ", "))
+		if n.config.FailOnPressure {
+			err = fmt.Errorf("%d node pressure events in %d nodes", summary.Events, summary.NodesUnderPressure)
+		}
+	}
+	if globalCfg.IndexerConfig.Type != "" {
+		if factory.jobConfig.SkipIndexing {
+			log.Infof("Skipping node pressure data indexing in job: %s", factory.jobConfig.Name)
+		} else {
+			n.index(events, summary)
+		}
+	}
+	var rows []csvRow
+	for _, conditionType := range pressureConditions {
+		cp := summary.Conditions[string(conditionType)]
+		rows = append(rows,
+			csvRow{metric: string(conditionType), quantile: "events", value: strconv.Itoa(cp.Events)},
+			csvRow{metric: string(conditionType), quantile: "duration", value: strconv.FormatFloat(cp.Duration, 'f', 0, 64)},
+		)
+	}
+	exportCSV("nodePressure", rows)
+	log.Infof("%s: %d node pressure events in %d/%d nodes", factory.jobConfig.Name, summary.Events, summary.NodesUnderPressure, summary.Nodes)
+	return err
+}
+
+// summarize closes the events still open and returns the events of the job and their summary
+func (n *nodePressure) summarize() ([]nodePressureEvent, nodePressureSummary) {
+	now := time.Now().UTC()
+	jc := *factory.jobConfig
+	jc.Objects = nil
+	summary := nodePressureSummary{
+		Timestamp:  n.jobStart,
+		Conditions: make(map[string]conditionPressure),
+		MetricName: nodePressureSummaryMeasurement,
+		JobName:    factory.jobConfig.Name,
+		JobConfig:  jc,
+		UUID:       globalCfg.UUID,
+		Metadata:   factory.metadata,
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for key := range n.open {
+		n.closeEvent(key, now, true)
+	}
+	nodesUnderPressure := make(map[string]bool)
+	for _, conditionType := range pressureConditions {
+		summary.Conditions[string(conditionType)] = conditionPressure{}
+	}
+	for _, event := range n.events {
+		cp := summary.Conditions[event.Condition]
+		cp.Events++
+		cp.Duration += event.Duration
+		summary.Conditions[event.Condition] = cp
+		nodesUnderPressure[event.NodeName] = true
+	}
+	summary.Nodes = len(n.nodes)
+	summary.NodesUnderPressure = len(nodesUnderPressure)
+	summary.Events = len(n.events)
+	return n.events, summary
+}
+
+// index sends metrics to the configured indexer
+func (n *nodePressure) index(events []nodePressureEvent, summary nodePressureSummary) {
+	log.Infof("Indexing node pressure data for job: %s", factory.jobConfig.Name)
+	var eventDocs []interface{}
+	for _, event := range events {
+		eventDocs = append(eventDocs, event)
+	}
+	metricMap := map[string][]interface{}{
+		nodePressureMeasurement:        eventDocs,
+		nodePressureSummaryMeasurement: {summary},
+	}
+	for metricName, data := range metricMap {
+		if len(data) == 0 {
+			continue
+		}
+		indexingOpts := indexers.IndexingOpts{
+			MetricName: fmt.Sprintf("%s-%s", metricName, factory.jobConfig.Name),
+		}
+		log.Debugf("Indexing [%d] documents: %s", len(data), metricName)
+		resp, err := (*factory.indexer).Index(data, indexingOpts)
+		if err != nil {
+			log.Error(err.Error())
+		} else {
+			log.Info(resp)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurements
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/types"
+	log "github.com/sirupsen/logrus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	patchLatencyMeasurement = "patchLatencyMeasurement"
+)
+
+// patchMetric holds the latency of a patch performed by a patch job
+type patchMetric struct {
+	Timestamp  time.Time   `json:"timestamp"`
+	Kind       string      `json:"kind"`
+	Namespace  string      `json:"namespace"`
+	Name       string      `json:"name"`
+	Latency    int         `json:"latency"`
+	Retries    int         `json:"retries"`
+	Error      bool        `json:"error"`
+	NotFound   bool        `json:"notFound"`
+	MetricName string      `json:"metricName"`
+	JobName    string      `json:"jobName"`
+	JobConfig  config.Job  `json:"jobConfig"`
+	UUID       string      `json:"uuid"`
+	Metadata   interface{} `json:"metadata,omitempty"`
+}
+
+// patchLatency measures the latency of the patches of the patch jobs, unlike apiLatency, it accounts for
+// the whole patch operation, including the retries of the conflicting or throttled requests
+type patchLatency struct {
+	config      types.Measurement
+	patches     []patchMetric
+	patchesLock sync.Mutex
+	started     bool
+}
+
+func init() {
+	measurementMap["patchLatency"] = &patchLatency{}
+}
+
+// RecordPatch records the latency of a patch started at the given time, it's a no-op when the patchLatency measurement isn't enabled
+func RecordPatch(kind, namespace, name string, start time.Time, retries int, err error) {
+	m, enabled := factory.createFuncs["patchLatency"]
+	if !enabled {
+		return
+	}
+	m.(*patchLatency).record(patchMetric{
+		Timestamp: start.UTC(),
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Latency:   int(time.Since(start).Milliseconds()),
+		Retries:   retries,
+		Error:     err != nil,
+		NotFound:  kerrors.IsNotFound(err),
+	})
+}
+
+func (p *patchLatency) record(patch patchMetric) {
+	p.patchesLock.Lock()
+	defer p.patchesLock.Unlock()
+	if !p.started {
+		return
+	}
+	patch.MetricName = patchLatencyMeasurement
+	patch.JobName = factory.jobConfig.Name
+	patch.JobConfig = *factory.jobConfig
+	patch.UUID = globalCfg.UUID
+	patch.Metadata = factory.metadata
+	p.patches = append(p.patches, patch)
+}
+
+func (p *patchLatency) setConfig(cfg types.Measurement) error {
+	p.config = cfg
+	for _, percentile := range cfg.Quantiles {
+		if percentile <= 0 || percentile > 100 {
+			return fmt.Errorf("invalid quantile %v in patchLatency measurement, it must be greater than 0 and lower or equal than 100", percentile)
+		}
+	}
+	for _, th := range cfg.LatencyThresholds {
+		if th.ConditionType == "" {
+			return fmt.Errorf("patchLatency thresholds require the conditionType, holding the kind of the patched objects")
+		}
+		if th.Percentile < 0 || th.Percentile > 100 {
+			return fmt.Errorf("invalid percentile %v in patchLatency measurement, it must be between 0 and 100", th.Percentile)
+		}
+	}
+	return nil
+}
+
+// start starts recording the patches of the job
+func (p *patchLatency) start(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+	p.patchesLock.Lock()
+	defer p.patchesLock.Unlock()
+	p.patches = nil
+	p.started = factory.jobConfig.JobType == config.PatchJob
+}
+
+func (p *patchLatency) collect(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+}
+
+// stop stops recording patches and reports the latencies of the successful ones, grouped by the kind of the patched objects
+func (p *patchLatency) stop() error {
+	p.patchesLock.Lock()
+	started := p.started
+	p.started = false
+	p.patchesLock.Unlock()
+	if !started {
+		return nil
+	}
+	var failed int
+	report := latencyReport{
+		measurement: "patchLatency",
+		config:      p.config,
+		documents:   make([]interface{}, len(p.patches)),
+		latencies:   map[string][]int{},
+	}
+	for i, patch := range p.patches {
+		report.documents[i] = patch
+		if patch.Error {
+			failed++
+			continue
+		}
+		report.latencies[patch.Kind] = append(report.latencies[patch.Kind], patch.Latency)
+	}
+	if failed > 0 {
+		log.Warnf("%d patches failed, their latencies are not accounted", failed)
+	}
+	// Reset the patches, required in multi-job benchmarks
+	p.patches = nil
+	return report.report()
+}
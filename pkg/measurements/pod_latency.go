@@ -31,6 +31,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 )
@@ -57,6 +58,9 @@ type podMetric struct {
 	Namespace              string      `json:"namespace"`
 	Name                   string      `json:"podName"`
 	NodeName               string      `json:"nodeName"`
+	CPURequest             int64       `json:"cpuRequest"`
+	MemoryRequest          int64       `json:"memoryRequest"`
+	QOSClass               string      `json:"qosClass"`
 	Metadata               interface{} `json:"metadata,omitempty"`
 }
 
@@ -67,6 +71,22 @@ type podLatency struct {
 	metricLock       sync.RWMutex
 	latencyQuantiles []interface{}
 	normLatencies    []interface{}
+	// flushedLatencies latencies of the pods already indexed by the periodic flushes, the quantiles of the job are
+	// calculated over them as well once the measurement stops
+	flushedLatencies []interface{}
+	// totalPods and erroredPods count the ready pods of the job and the ones with negative latencies
+	totalPods   int
+	erroredPods int
+	// latencies sorted latencies of each pod condition, used to check percentile thresholds
+	latencies map[string][]int
+}
+
+// latencyConditions maps the pod latency document fields to their pod condition
+var latencyConditions = map[string]corev1.PodConditionType{
+	"schedulingLatency":      corev1.PodScheduled,
+	"initializedLatency":     corev1.PodInitialized,
+	"containersReadyLatency": corev1.ContainersReady,
+	"podReadyLatency":        corev1.PodReady,
 }
 
 func init() {
@@ -78,15 +98,19 @@ func (p *podLatency) handleCreatePod(obj interface{}) {
 	p.metricLock.Lock()
 	defer p.metricLock.Unlock()
 	if _, exists := p.metrics[string(pod.UID)]; !exists {
+		cpuRequest, memoryRequest := podRequests(pod)
 		p.metrics[string(pod.UID)] = podMetric{
-			Timestamp:  pod.CreationTimestamp.Time.UTC(),
-			Namespace:  pod.Namespace,
-			Name:       pod.Name,
-			MetricName: podLatencyMeasurement,
-			UUID:       globalCfg.UUID,
-			JobConfig:  *factory.jobConfig,
-			JobName:    factory.jobConfig.Name,
-			Metadata:   factory.metadata,
+			Timestamp:     pod.CreationTimestamp.Time.UTC(),
+			Namespace:     pod.Namespace,
+			Name:          pod.Name,
+			MetricName:    podLatencyMeasurement,
+			UUID:          globalCfg.UUID,
+			JobConfig:     *factory.jobConfig,
+			JobName:       factory.jobConfig.Name,
+			CPURequest:    cpuRequest,
+			MemoryRequest: memoryRequest,
+			QOSClass:      string(podQOSClass(pod)),
+			Metadata:      factory.metadata,
 		}
 	}
 }
@@ -124,6 +148,64 @@ func (p *podLatency) handleUpdatePod(obj interface{}) {
 	}
 }
 
+// podRequests returns the CPU requests in millicores and the memory requests in bytes of the given pod
+func podRequests(pod *corev1.Pod) (int64, int64) {
+	var cpu, memory int64
+	for _, c := range pod.Spec.Containers {
+		cpu += c.Resources.Requests.Cpu().MilliValue()
+		memory += c.Resources.Requests.Memory().Value()
+	}
+	return cpu, memory
+}
+
+// podQOSClass computes the QoS class of the given pod from its containers resources, following the same rules as the kubelet:
+// Guaranteed when all the containers have equal CPU and memory requests and limits, BestEffort when none of them have requests
+// nor limits, and Burstable otherwise
+func podQOSClass(pod *corev1.Pod) corev1.PodQOSClass {
+	requests := corev1.ResourceList{}
+	limits := corev1.ResourceList{}
+	supportedResources := []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+	isGuaranteed := true
+	containers := append(append([]corev1.Container{}, pod.Spec.Containers...), pod.Spec.InitContainers...)
+	for _, c := range containers {
+		for _, name := range supportedResources {
+			if quantity, ok := c.Resources.Requests[name]; ok && !quantity.IsZero() {
+				if total, exists := requests[name]; exists {
+					total.Add(quantity)
+					requests[name] = total
+				} else {
+					requests[name] = quantity.DeepCopy()
+				}
+			}
+			if quantity, ok := c.Resources.Limits[name]; ok && !quantity.IsZero() {
+				if total, exists := limits[name]; exists {
+					total.Add(quantity)
+					limits[name] = total
+				} else {
+					limits[name] = quantity.DeepCopy()
+				}
+			} else {
+				isGuaranteed = false
+			}
+		}
+	}
+	if len(requests) == 0 && len(limits) == 0 {
+		return corev1.PodQOSBestEffort
+	}
+	if isGuaranteed {
+		for name, request := range requests {
+			if limit, ok := limits[name]; !ok || limit.Cmp(request) != 0 {
+				isGuaranteed = false
+				break
+			}
+		}
+	}
+	if isGuaranteed && len(limits) == len(supportedResources) {
+		return corev1.PodQOSGuaranteed
+	}
+	return corev1.PodQOSBurstable
+}
+
 func (p *podLatency) setConfig(cfg types.Measurement) error {
 	p.config = cfg
 	if err := p.validateConfig(); err != nil {
@@ -146,9 +228,7 @@ func (p *podLatency) start(measurementWg *sync.WaitGroup) {
 		"podWatcher",
 		"pods",
 		corev1.NamespaceAll,
-		func(options *metav1.ListOptions) {
-			options.LabelSelector = fmt.Sprintf("kube-burner-runid=%s", globalCfg.RUNID)
-		},
+		informerListOptions(p.config, "pods"),
 	)
 	p.watcher.Informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: p.handleCreatePod,
@@ -192,6 +272,7 @@ func (p *podLatency) collect(measurementWg *sync.WaitGroup) {
 				podReady = c.LastTransitionTime.Time.UTC()
 			}
 		}
+		cpuRequest, memoryRequest := podRequests(&pod)
 		p.metrics[string(pod.UID)] = podMetric{
 			Timestamp:       pod.Status.StartTime.Time.UTC(),
 			Namespace:       pod.Namespace,
@@ -201,6 +282,9 @@ func (p *podLatency) collect(measurementWg *sync.WaitGroup) {
 			UUID:            globalCfg.UUID,
 			JobConfig:       *factory.jobConfig,
 			JobName:         factory.jobConfig.Name,
+			CPURequest:      cpuRequest,
+			MemoryRequest:   memoryRequest,
+			QOSClass:        string(podQOSClass(&pod)),
 			Metadata:        factory.metadata,
 			scheduled:       scheduled,
 			initialized:     initialized,
@@ -215,66 +299,116 @@ func (p *podLatency) stop() error {
 	if factory.jobConfig.JobType == config.DeletionJob {
 		return nil
 	}
-	var err error
 	if p.watcher != nil {
 		p.watcher.StopWatcher()
 	}
-	errorRate := p.normalizeMetrics()
+	p.normalizeMetrics(p.metrics)
+	return p.report()
+}
+
+// flush indexes the latencies of the pods that are already ready and forgets them, the rest are indexed once ready.
+// The quantiles are only calculated once the measurement stops, over all the pods of the job
+func (p *podLatency) flush() error {
+	if factory.jobConfig.JobType == config.DeletionJob {
+		return nil
+	}
+	p.metricLock.Lock()
+	readyPods := make(map[string]podMetric)
+	for uid, m := range p.metrics {
+		if !m.podReady.IsZero() {
+			readyPods[uid] = m
+			delete(p.metrics, uid)
+		}
+	}
+	p.metricLock.Unlock()
+	if len(readyPods) == 0 {
+		log.Infof("%s: no ready pods to flush", factory.jobConfig.Name)
+		return nil
+	}
+	p.normalizeMetrics(readyPods)
+	p.indexLatencies()
+	log.Infof("%s: flushed the latencies of %d ready pods", factory.jobConfig.Name, len(p.normLatencies))
+	p.flushedLatencies = append(p.flushedLatencies, p.normLatencies...)
+	p.normLatencies = nil
+	return nil
+}
+
+// report indexes the latencies of the pods not flushed yet, then calculates, checks and indexes the quantiles of all
+// the pods of the job
+func (p *podLatency) report() error {
+	var err error
+	// Reset latency slices, required in multi-job benchmarks
+	defer func() {
+		p.latencyQuantiles, p.normLatencies, p.flushedLatencies, p.latencies = nil, nil, nil, nil
+		p.totalPods, p.erroredPods = 0, 0
+	}()
+	errorRate := p.errorRate()
 	if errorRate > 10.00 {
 		log.Error("Latency errors beyond 10%. Hence invalidating the results")
 		return fmt.Errorf("Something is wrong with system under test. Pod latencies error rate was: %.2f", errorRate)
 	}
+	p.indexLatencies()
+	p.normLatencies = append(p.flushedLatencies, p.normLatencies...)
 	p.calcQuantiles()
 	if len(p.config.LatencyThresholds) > 0 {
-		err = metrics.CheckThreshold(p.config.LatencyThresholds, p.latencyQuantiles)
-	}
-	if globalCfg.IndexerConfig.Type != "" {
-		if factory.jobConfig.SkipIndexing {
-			log.Infof("Skipping pod latency data indexing in job: %s", factory.jobConfig.Name)
-		} else {
-			p.index()
-		}
+		err = utilerrors.NewAggregate([]error{
+			metrics.CheckThreshold("podLatency", p.config.LatencyThresholds, p.latencyQuantiles),
+			metrics.CheckPercentileThresholds("podLatency", p.config.LatencyThresholds, p.latencies),
+		})
 	}
+	p.index(podLatencyQuantilesMeasurement, p.latencyQuantiles)
+	exportCSV("podLatency", latencyQuantilesRows(p.latencyQuantiles, p.config.Quantiles))
 	for _, q := range p.latencyQuantiles {
 		pq := q.(metrics.LatencyQuantiles)
-		log.Infof("%s: %s 50th: %v 99th: %v max: %v avg: %v", factory.jobConfig.Name, pq.QuantileName, pq.P50, pq.P99, pq.Max, pq.Avg)
+		log.Infof("%s: %s %s", factory.jobConfig.Name, pq.QuantileName, pq.Summary(p.config.Quantiles))
 	}
 	if len(p.latencyQuantiles) > 0 {
 		log.Infof("Pod latencies error rate was: %.2f", errorRate)
 	}
-	// Reset latency slices, required in multi-job benchmarks
-	p.latencyQuantiles, p.normLatencies = nil, nil
 	return err
 }
 
+// indexLatencies indexes the latency documents of the pods normalized so far, unless only the quantiles are indexed
+func (p *podLatency) indexLatencies() {
+	if p.config.PodLatencyMetrics == types.Quantiles {
+		return
+	}
+	p.index(podLatencyMeasurement, p.normLatencies)
+}
+
 // index sends metrics to the configured indexer
-func (p *podLatency) index() {
+func (p *podLatency) index(metricName string, data []interface{}) {
+	if globalCfg.IndexerConfig.Type == "" || len(data) == 0 {
+		return
+	}
+	if factory.jobConfig.SkipIndexing {
+		log.Infof("Skipping pod latency data indexing in job: %s", factory.jobConfig.Name)
+		return
+	}
 	log.Infof("Indexing pod latency data for job: %s", factory.jobConfig.Name)
-	metricMap := map[string][]interface{}{
-		podLatencyMeasurement:          p.normLatencies,
-		podLatencyQuantilesMeasurement: p.latencyQuantiles,
+	indexingOpts := indexers.IndexingOpts{
+		MetricName: fmt.Sprintf("%s-%s", metricName, factory.jobConfig.Name),
 	}
-	if p.config.PodLatencyMetrics == types.Quantiles {
-		delete(metricMap, podLatencyMeasurement)
+	log.Debugf("Indexing [%d] documents: %s", len(data), metricName)
+	resp, err := (*factory.indexer).Index(data, indexingOpts)
+	if err != nil {
+		log.Error(err.Error())
+	} else {
+		log.Info(resp)
 	}
-	for metricName, data := range metricMap {
-		indexingOpts := indexers.IndexingOpts{
-			MetricName: fmt.Sprintf("%s-%s", metricName, factory.jobConfig.Name),
-		}
-		log.Debugf("Indexing [%d] documents: %s", len(data), metricName)
-		resp, err := (*factory.indexer).Index(data, indexingOpts)
-		if err != nil {
-			log.Error(err.Error())
-		} else {
-			log.Info(resp)
-		}
+}
+
+// errorRate returns the percentage of the ready pods of the job with negative latencies
+func (p *podLatency) errorRate() float64 {
+	if p.totalPods == 0 {
+		return 0.0
 	}
+	return float64(p.erroredPods) / float64(p.totalPods) * 100.0
 }
 
-func (p *podLatency) normalizeMetrics() float64 {
-	totalPods := 0
-	erroredPods := 0
-	for _, m := range p.metrics {
+// normalizeMetrics calculates the latencies of the given ready pods, counting the ones with negative latencies
+func (p *podLatency) normalizeMetrics(podMetrics map[string]podMetric) {
+	for _, m := range podMetrics {
 		// If a pod does not reach the Running state (this timestamp isn't set), we skip that pod
 		if m.podReady.IsZero() {
 			log.Tracef("Pod %v latency ignored as it did not reach Ready state", m.Name)
@@ -315,14 +449,10 @@ func (p *podLatency) normalizeMetrics() float64 {
 			errorFlag = 1
 			m.PodReadyLatency = 0
 		}
-		totalPods++
-		erroredPods += errorFlag
+		p.totalPods++
+		p.erroredPods += errorFlag
 		p.normLatencies = append(p.normLatencies, m)
 	}
-	if totalPods == 0 {
-		return 0.0
-	}
-	return float64(erroredPods) / float64(totalPods) * 100.0
 }
 
 func (p *podLatency) calcQuantiles() {
@@ -336,6 +466,7 @@ func (p *podLatency) calcQuantiles() {
 		quantileMap[corev1.PodInitialized] = append(quantileMap[corev1.PodInitialized], normLatency.(podMetric).InitializedLatency)
 		quantileMap[corev1.PodReady] = append(quantileMap[corev1.PodReady], normLatency.(podMetric).PodReadyLatency)
 	}
+	p.latencies = make(map[string][]int)
 	for quantileName, v := range quantileMap {
 		podQ := metrics.LatencyQuantiles{
 			QuantileName: string(quantileName),
@@ -347,11 +478,18 @@ func (p *podLatency) calcQuantiles() {
 			Metadata:     factory.metadata,
 		}
 		sort.Ints(v)
+		p.latencies[string(quantileName)] = v
 		length := len(v)
 		if length > 1 {
-			for _, quantile := range quantiles {
-				qValue := v[int(math.Ceil(float64(length)*quantile))-1]
-				podQ.SetQuantile(quantile, qValue)
+			if len(p.config.Quantiles) > 0 {
+				for _, percentile := range p.config.Quantiles {
+					podQ.SetPercentile(percentile, metrics.Percentile(v, percentile))
+				}
+			} else {
+				for _, quantile := range quantiles {
+					qValue := v[int(math.Ceil(float64(length)*quantile))-1]
+					podQ.SetQuantile(quantile, qValue)
+				}
 			}
 			podQ.Max = v[length-1]
 		}
@@ -367,7 +505,33 @@ func (p *podLatency) calcQuantiles() {
 func (p *podLatency) validateConfig() error {
 	var metricFound bool
 	var latencyMetrics = []string{"P99", "P95", "P50", "Avg", "Max"}
-	for _, th := range p.config.LatencyThresholds {
+	// Thresholds can only reference the configured quantiles
+	if len(p.config.Quantiles) > 0 {
+		latencyMetrics = []string{"Avg", "Max"}
+	}
+	for _, percentile := range p.config.Quantiles {
+		if percentile <= 0 || percentile > 100 {
+			return fmt.Errorf("invalid quantile %v in podLatency measurement, it must be greater than 0 and lower or equal than 100", percentile)
+		}
+		latencyMetrics = append(latencyMetrics, metrics.PercentileLabel(percentile))
+	}
+	for i, th := range p.config.LatencyThresholds {
+		if th.Percentile != 0 {
+			if th.Percentile < 0 || th.Percentile > 100 {
+				return fmt.Errorf("invalid percentile %v in podLatency measurement, it must be between 0 and 100", th.Percentile)
+			}
+			// The metric can reference the latency by its document field, i.e. podReadyLatency
+			if condition, ok := latencyConditions[th.Metric]; ok && th.ConditionType == "" {
+				p.config.LatencyThresholds[i].ConditionType = string(condition)
+				th.ConditionType = string(condition)
+			}
+			switch corev1.PodConditionType(th.ConditionType) {
+			case corev1.ContainersReady, corev1.PodInitialized, corev1.PodReady, corev1.PodScheduled:
+			default:
+				return fmt.Errorf("unsupported pod condition type in podLatency measurement: %s", th.ConditionType)
+			}
+			continue
+		}
 		if th.ConditionType == string(corev1.ContainersReady) || th.ConditionType == string(corev1.PodInitialized) || th.ConditionType == string(corev1.PodReady) || th.ConditionType == string(corev1.PodScheduled) {
 			for _, lm := range latencyMetrics {
 				if th.Metric == lm {
@@ -0,0 +1,99 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurements
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func resources(cpu, memory string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	if cpu != "" {
+		list[corev1.ResourceCPU] = resource.MustParse(cpu)
+	}
+	if memory != "" {
+		list[corev1.ResourceMemory] = resource.MustParse(memory)
+	}
+	return list
+}
+
+func TestPodQOSClass(t *testing.T) {
+	tests := []struct {
+		name           string
+		containers     []corev1.ResourceRequirements
+		initContainers []corev1.ResourceRequirements
+		want           corev1.PodQOSClass
+	}{
+		{
+			name:       "no resources",
+			containers: []corev1.ResourceRequirements{{}},
+			want:       corev1.PodQOSBestEffort,
+		},
+		{
+			name:       "zero resources",
+			containers: []corev1.ResourceRequirements{{Requests: resources("0", "0")}},
+			want:       corev1.PodQOSBestEffort,
+		},
+		{
+			name:       "equal requests and limits",
+			containers: []corev1.ResourceRequirements{{Requests: resources("100m", "128Mi"), Limits: resources("100m", "128Mi")}},
+			want:       corev1.PodQOSGuaranteed,
+		},
+		{
+			name:       "limits only",
+			containers: []corev1.ResourceRequirements{{Limits: resources("100m", "128Mi")}},
+			want:       corev1.PodQOSGuaranteed,
+		},
+		{
+			name:       "requests lower than limits",
+			containers: []corev1.ResourceRequirements{{Requests: resources("50m", "128Mi"), Limits: resources("100m", "128Mi")}},
+			want:       corev1.PodQOSBurstable,
+		},
+		{
+			name:       "memory limit only",
+			containers: []corev1.ResourceRequirements{{Limits: resources("", "128Mi")}},
+			want:       corev1.PodQOSBurstable,
+		},
+		{
+			name: "one container without limits",
+			containers: []corev1.ResourceRequirements{
+				{Requests: resources("100m", "128Mi"), Limits: resources("100m", "128Mi")},
+				{},
+			},
+			want: corev1.PodQOSBurstable,
+		},
+		{
+			name:           "init container without limits",
+			containers:     []corev1.ResourceRequirements{{Requests: resources("100m", "128Mi"), Limits: resources("100m", "128Mi")}},
+			initContainers: []corev1.ResourceRequirements{{Requests: resources("10m", "")}},
+			want:           corev1.PodQOSBurstable,
+		},
+	}
+	for _, tt := range tests {
+		pod := &corev1.Pod{}
+		for _, r := range tt.containers {
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Resources: r})
+		}
+		for _, r := range tt.initContainers {
+			pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{Resources: r})
+		}
+		if got := podQOSClass(pod); got != tt.want {
+			t.Errorf("%s: podQOSClass() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -50,16 +50,15 @@ func (p *pprof) setConfig(cfg types.Measurement) error {
 	if err := p.validateConfig(); err != nil {
 		return err
 	}
+	if err := os.MkdirAll(p.config.PProfDirectory, 0744); err != nil {
+		return fmt.Errorf("error creating pprof directory: %s", err)
+	}
 	return nil
 }
 
 func (p *pprof) start(measurementWg *sync.WaitGroup) {
 	defer measurementWg.Done()
 	var wg sync.WaitGroup
-	err := os.MkdirAll(p.config.PProfDirectory, 0744)
-	if err != nil {
-		log.Fatalf("Error creating pprof directory: %s", err)
-	}
 	p.stopChannel = make(chan bool)
 	p.getPProf(&wg, true)
 	wg.Wait()
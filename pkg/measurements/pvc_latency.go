@@ -0,0 +1,202 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurements
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/metrics"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/types"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	pvcLatencyMeasurement = "pvcLatencyMeasurement"
+	pvcBound              = string(corev1.ClaimBound)
+)
+
+// pvcMetric holds the bind latency of a PersistentVolumeClaim
+type pvcMetric struct {
+	Timestamp    time.Time `json:"timestamp"`
+	bound        time.Time
+	BindLatency  int         `json:"bindLatency"`
+	MetricName   string      `json:"metricName"`
+	JobName      string      `json:"jobName"`
+	JobConfig    config.Job  `json:"jobConfig"`
+	UUID         string      `json:"uuid"`
+	Namespace    string      `json:"namespace"`
+	Name         string      `json:"pvcName"`
+	StorageClass string      `json:"storageClass"`
+	VolumeName   string      `json:"volumeName"`
+	Metadata     interface{} `json:"metadata,omitempty"`
+}
+
+// pvcLatency measures the time PersistentVolumeClaims take from their creation until they're Bound
+type pvcLatency struct {
+	config     types.Measurement
+	watcher    *metrics.Watcher
+	metrics    map[string]pvcMetric
+	metricLock sync.Mutex
+}
+
+func init() {
+	measurementMap["pvcLatency"] = &pvcLatency{}
+}
+
+// handlePVC records the creation of the PVC and the first time it's observed Bound. PVCs have no condition recording
+// their binding time, so the bind latency precision depends on the informer event delivery
+func (p *pvcLatency) handlePVC(obj interface{}) {
+	pvc := obj.(*corev1.PersistentVolumeClaim)
+	p.metricLock.Lock()
+	defer p.metricLock.Unlock()
+	pm, exists := p.metrics[string(pvc.UID)]
+	if !exists {
+		pm = pvcMetric{
+			Timestamp:  pvc.CreationTimestamp.Time.UTC(),
+			Namespace:  pvc.Namespace,
+			Name:       pvc.Name,
+			MetricName: pvcLatencyMeasurement,
+			UUID:       globalCfg.UUID,
+			JobConfig:  *factory.jobConfig,
+			JobName:    factory.jobConfig.Name,
+			Metadata:   factory.metadata,
+		}
+		if pvc.Spec.StorageClassName != nil {
+			pm.StorageClass = *pvc.Spec.StorageClassName
+		}
+	}
+	if pvc.Status.Phase == corev1.ClaimBound && pm.bound.IsZero() {
+		log.Debugf("PVC %s/%s is bound", pvc.Namespace, pvc.Name)
+		pm.bound = time.Now().UTC()
+		pm.VolumeName = pvc.Spec.VolumeName
+	}
+	p.metrics[string(pvc.UID)] = pm
+}
+
+func (p *pvcLatency) setConfig(cfg types.Measurement) error {
+	p.config = cfg
+	latencyMetrics := []string{"P99", "P95", "P50", "Avg", "Max"}
+	if len(cfg.Quantiles) > 0 {
+		latencyMetrics = []string{"Avg", "Max"}
+	}
+	for _, percentile := range cfg.Quantiles {
+		if percentile <= 0 || percentile > 100 {
+			return fmt.Errorf("invalid quantile %v in pvcLatency measurement, it must be greater than 0 and lower or equal than 100", percentile)
+		}
+		latencyMetrics = append(latencyMetrics, metrics.PercentileLabel(percentile))
+	}
+	for i, th := range cfg.LatencyThresholds {
+		if th.ConditionType == "" {
+			p.config.LatencyThresholds[i].ConditionType = pvcBound
+		} else if th.ConditionType != pvcBound {
+			return fmt.Errorf("unsupported condition type in pvcLatency measurement: %s", th.ConditionType)
+		}
+		if th.Percentile != 0 {
+			if th.Percentile < 0 || th.Percentile > 100 {
+				return fmt.Errorf("invalid percentile %v in pvcLatency measurement, it must be between 0 and 100", th.Percentile)
+			}
+			continue
+		}
+		var metricFound bool
+		for _, lm := range latencyMetrics {
+			if th.Metric == lm {
+				metricFound = true
+				break
+			}
+		}
+		if !metricFound {
+			return fmt.Errorf("unsupported metric %s in pvcLatency measurement, supported are: %s", th.Metric, strings.Join(latencyMetrics, ", "))
+		}
+	}
+	return nil
+}
+
+// start starts watching the PVCs of the job
+func (p *pvcLatency) start(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+	if factory.jobConfig.JobType == config.DeletionJob {
+		log.Info("PVC latency measurement not compatible with delete jobs, skipping")
+		return
+	}
+	p.metrics = make(map[string]pvcMetric)
+	log.Infof("Creating PVC latency watcher for %s", factory.jobConfig.Name)
+	p.watcher = metrics.NewWatcher(
+		factory.clientSet.CoreV1().RESTClient().(*rest.RESTClient),
+		"pvcWatcher",
+		"persistentvolumeclaims",
+		corev1.NamespaceAll,
+		informerListOptions(p.config, "persistentvolumeclaims"),
+	)
+	p.watcher.Informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: p.handlePVC,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			p.handlePVC(newObj)
+		},
+	})
+	if err := p.watcher.StartAndCacheSync(); err != nil {
+		log.Errorf("PVC Latency measurement error: %s", err)
+	}
+}
+
+func (p *pvcLatency) collect(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+}
+
+// stop stops the watcher and reports the bind latencies of the bound PVCs
+func (p *pvcLatency) stop() error {
+	if factory.jobConfig.JobType == config.DeletionJob {
+		return nil
+	}
+	if p.watcher != nil {
+		p.watcher.StopWatcher()
+	}
+	report := latencyReport{
+		measurement: "pvcLatency",
+		config:      p.config,
+		latencies:   map[string][]int{},
+	}
+	for _, m := range p.normalizeMetrics() {
+		report.documents = append(report.documents, m)
+		report.latencies[pvcBound] = append(report.latencies[pvcBound], m.BindLatency)
+	}
+	return report.report()
+}
+
+// normalizeMetrics returns the bound PVCs with their bind latency calculated
+func (p *pvcLatency) normalizeMetrics() []pvcMetric {
+	var normLatencies []pvcMetric
+	p.metricLock.Lock()
+	defer p.metricLock.Unlock()
+	for _, m := range p.metrics {
+		if m.bound.IsZero() {
+			log.Tracef("PVC %s/%s latency ignored as it wasn't bound", m.Namespace, m.Name)
+			continue
+		}
+		m.BindLatency = int(m.bound.Sub(m.Timestamp).Milliseconds())
+		// Creation timestamps have second precision
+		if m.BindLatency < 0 {
+			m.BindLatency = 0
+		}
+		normLatencies = append(normLatencies, m)
+	}
+	return normLatencies
+}
@@ -0,0 +1,209 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurements
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/metrics"
+	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/types"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	schedulerThroughputMeasurement = "schedulerThroughputMeasurement"
+	defaultThroughputWindow        = 10 * time.Second
+)
+
+// schedulerThroughputMetric holds the pods scheduled per second during a job
+type schedulerThroughputMetric struct {
+	Timestamp     time.Time   `json:"timestamp"`
+	ScheduledPods int         `json:"scheduledPods"`
+	Window        string      `json:"window"`
+	MaxThroughput float64     `json:"maxThroughput"`
+	AvgThroughput float64     `json:"avgThroughput"`
+	MetricName    string      `json:"metricName"`
+	JobName       string      `json:"jobName"`
+	UUID          string      `json:"uuid"`
+	Metadata      interface{} `json:"metadata,omitempty"`
+}
+
+// schedulerThroughput measures the number of pods scheduled per second, from the PodScheduled condition transitions of the pods
+type schedulerThroughput struct {
+	config    types.Measurement
+	watcher   *metrics.Watcher
+	startTime time.Time
+	// scheduled transition time of the PodScheduled condition of each pod, by UID
+	scheduled map[string]time.Time
+	lock      sync.Mutex
+}
+
+func init() {
+	measurementMap["schedulerThroughput"] = &schedulerThroughput{}
+}
+
+func (s *schedulerThroughput) setConfig(cfg types.Measurement) error {
+	if cfg.ThroughputWindow < 0 {
+		return fmt.Errorf("invalid throughputWindow %v in schedulerThroughput measurement, it must be greater than 0", cfg.ThroughputWindow)
+	}
+	if cfg.ThroughputWindow == 0 {
+		cfg.ThroughputWindow = defaultThroughputWindow
+	}
+	s.config = cfg
+	return nil
+}
+
+func (s *schedulerThroughput) handlePod(obj interface{}) {
+	pod := obj.(*corev1.Pod)
+	for _, c := range pod.Status.Conditions {
+		if c.Type != corev1.PodScheduled || c.Status != corev1.ConditionTrue {
+			continue
+		}
+		// Pods scheduled before the job started don't account for its throughput
+		if c.LastTransitionTime.Time.Before(s.startTime) {
+			return
+		}
+		s.lock.Lock()
+		if _, exists := s.scheduled[string(pod.UID)]; !exists {
+			s.scheduled[string(pod.UID)] = c.LastTransitionTime.Time.UTC()
+		}
+		s.lock.Unlock()
+		return
+	}
+}
+
+// start starts watching the pods of the job
+func (s *schedulerThroughput) start(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+	if factory.jobConfig.JobType == config.DeletionJob {
+		log.Info("Scheduler throughput measurement not compatible with delete jobs, skipping")
+		return
+	}
+	// Condition transitions have second precision
+	s.startTime = time.Now().Truncate(time.Second)
+	s.scheduled = make(map[string]time.Time)
+	log.Infof("Creating scheduler throughput watcher for %s", factory.jobConfig.Name)
+	s.watcher = metrics.NewWatcher(
+		factory.clientSet.CoreV1().RESTClient().(*rest.RESTClient),
+		"schedulerThroughputWatcher",
+		"pods",
+		corev1.NamespaceAll,
+		informerListOptions(s.config, "pods"),
+	)
+	s.watcher.Informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: s.handlePod,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			s.handlePod(newObj)
+		},
+	})
+	if err := s.watcher.StartAndCacheSync(); err != nil {
+		log.Errorf("Scheduler throughput measurement error: %s", err)
+	}
+}
+
+func (s *schedulerThroughput) collect(measurementWg *sync.WaitGroup) {
+	defer measurementWg.Done()
+}
+
+// stop stops the watcher and calculates the maximum and average throughput
+func (s *schedulerThroughput) stop() error {
+	if factory.jobConfig.JobType == config.DeletionJob {
+		return nil
+	}
+	if s.watcher != nil {
+		s.watcher.StopWatcher()
+	}
+	s.lock.Lock()
+	scheduled := make([]time.Time, 0, len(s.scheduled))
+	for _, t := range s.scheduled {
+		scheduled = append(scheduled, t)
+	}
+	s.lock.Unlock()
+	throughput := calcThroughput(scheduled, s.config.ThroughputWindow)
+	log.Infof("%s: %d pods scheduled, max throughput: %.2f pods/s, avg throughput: %.2f pods/s (%v window)", factory.jobConfig.Name, throughput.ScheduledPods, throughput.MaxThroughput, throughput.AvgThroughput, s.config.ThroughputWindow)
+	if globalCfg.IndexerConfig.Type != "" {
+		if factory.jobConfig.SkipIndexing {
+			log.Infof("Skipping scheduler throughput data indexing in job: %s", factory.jobConfig.Name)
+		} else {
+			s.index(throughput)
+		}
+	}
+	exportCSV("schedulerThroughput", []csvRow{
+		{metric: "scheduledPods", value: strconv.Itoa(throughput.ScheduledPods)},
+		{metric: "throughput", quantile: "Max", value: strconv.FormatFloat(throughput.MaxThroughput, 'f', 2, 64)},
+		{metric: "throughput", quantile: "Avg", value: strconv.FormatFloat(throughput.AvgThroughput, 'f', 2, 64)},
+	})
+	s.scheduled = nil
+	return nil
+}
+
+// calcThroughput calculates the maximum number of pods scheduled per second within a sliding window of the given size,
+// and the average throughput between the first and the last scheduled pods, or within a single window when they're closer
+func calcThroughput(scheduled []time.Time, window time.Duration) schedulerThroughputMetric {
+	throughput := schedulerThroughputMetric{
+		Timestamp:     time.Now().UTC(),
+		ScheduledPods: len(scheduled),
+		Window:        window.String(),
+		MetricName:    schedulerThroughputMeasurement,
+		JobName:       factory.jobConfig.Name,
+		UUID:          globalCfg.UUID,
+		Metadata:      factory.metadata,
+	}
+	if len(scheduled) == 0 {
+		return throughput
+	}
+	sort.Slice(scheduled, func(i, j int) bool { return scheduled[i].Before(scheduled[j]) })
+	var maxPods int
+	// Windows start at each scheduled pod, the end pointer only moves forward
+	end := 0
+	for start := range scheduled {
+		for end < len(scheduled) && scheduled[end].Sub(scheduled[start]) < window {
+			end++
+		}
+		if end-start > maxPods {
+			maxPods = end - start
+		}
+	}
+	throughput.MaxThroughput = float64(maxPods) / window.Seconds()
+	elapsed := scheduled[len(scheduled)-1].Sub(scheduled[0])
+	if elapsed < window {
+		elapsed = window
+	}
+	throughput.AvgThroughput = float64(len(scheduled)) / elapsed.Seconds()
+	return throughput
+}
+
+// index sends the throughput document to the configured indexer
+func (s *schedulerThroughput) index(throughput schedulerThroughputMetric) {
+	log.Infof("Indexing scheduler throughput data for job: %s", factory.jobConfig.Name)
+	indexingOpts := indexers.IndexingOpts{
+		MetricName: fmt.Sprintf("%s-%s", schedulerThroughputMeasurement, factory.jobConfig.Name),
+	}
+	resp, err := (*factory.indexer).Index([]interface{}{throughput}, indexingOpts)
+	if err != nil {
+		log.Error(err.Error())
+	} else {
+		log.Info(resp)
+	}
+}
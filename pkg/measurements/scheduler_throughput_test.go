@@ -0,0 +1,94 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurements
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+)
+
+func TestCalcThroughput(t *testing.T) {
+	factory.jobConfig = &config.Job{Name: "test"}
+	t0 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(offsets ...time.Duration) []time.Time {
+		scheduled := make([]time.Time, len(offsets))
+		for i, offset := range offsets {
+			scheduled[i] = t0.Add(offset)
+		}
+		return scheduled
+	}
+	tests := []struct {
+		name      string
+		scheduled []time.Time
+		window    time.Duration
+		wantPods  int
+		wantMax   float64
+		wantAvg   float64
+	}{
+		{
+			name:   "no pods",
+			window: 10 * time.Second,
+		},
+		{
+			name:      "single pod",
+			scheduled: at(0),
+			window:    10 * time.Second,
+			wantPods:  1,
+			wantMax:   0.1,
+			wantAvg:   0.1,
+		},
+		{
+			name:      "pods within a single window",
+			scheduled: at(0, time.Second, 2*time.Second, 3*time.Second),
+			window:    10 * time.Second,
+			wantPods:  4,
+			wantMax:   0.4,
+			wantAvg:   0.4,
+		},
+		{
+			name:      "burst in the middle of the run",
+			scheduled: at(0, 20*time.Second, 21*time.Second, 22*time.Second, 23*time.Second, 24*time.Second, 40*time.Second),
+			window:    10 * time.Second,
+			wantPods:  7,
+			wantMax:   0.5,
+			wantAvg:   7.0 / 40,
+		},
+		{
+			name:      "window end is exclusive",
+			scheduled: at(0, 5*time.Second, 10*time.Second, 15*time.Second, 20*time.Second),
+			window:    10 * time.Second,
+			wantPods:  5,
+			wantMax:   0.2,
+			wantAvg:   0.25,
+		},
+		{
+			name:      "unsorted timestamps",
+			scheduled: at(3*time.Second, 0, 2*time.Second, time.Second),
+			window:    2 * time.Second,
+			wantPods:  4,
+			wantMax:   1,
+			wantAvg:   4.0 / 3,
+		},
+	}
+	for _, tt := range tests {
+		got := calcThroughput(tt.scheduled, tt.window)
+		if got.ScheduledPods != tt.wantPods || got.MaxThroughput != tt.wantMax || got.AvgThroughput != tt.wantAvg {
+			t.Errorf("%s: calcThroughput() = %d pods, max %v, avg %v, want %d pods, max %v, avg %v",
+				tt.name, got.ScheduledPods, got.MaxThroughput, got.AvgThroughput, tt.wantPods, tt.wantMax, tt.wantAvg)
+		}
+	}
+}
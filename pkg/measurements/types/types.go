@@ -21,7 +21,7 @@ import (
 type latencyMetric string
 
 const (
-	All            latencyMetric = "all"       // Both quantiles and per pod documents
+	All            latencyMetric = "all"       // Both quantiles and per object documents
 	Quantiles      latencyMetric = "quantiles" // Single quantile document
 	pprofDirectory string        = "pprof"
 )
@@ -32,6 +32,7 @@ func (m *Measurement) UnmarshalMeasurement(unmarshal func(interface{}) error) er
 	measurement := rawMeasurement{
 		PProfDirectory:    pprofDirectory,
 		PodLatencyMetrics: All,
+		LatencyMetrics:    All,
 	}
 	if err := unmarshal(&measurement); err != nil {
 		return err
@@ -54,6 +55,28 @@ type Measurement struct {
 	PProfDirectory string `yaml:"pprofDirectory"`
 	// Pod latency metrics to index
 	PodLatencyMetrics latencyMetric `yaml:"podLatencyMetrics"`
+	// LatencyMetrics latency metrics to index by the latency measurements of other objects than pods
+	LatencyMetrics latencyMetric `yaml:"latencyMetrics"`
+	// Quantiles percentiles, between 0 and 100, computed by the podLatency measurement instead of P50, P95 and P99
+	Quantiles []float64 `yaml:"quantiles"`
+	// Selectors extra selectors applied to the informers of the measurement, indexed by resource
+	Selectors map[string]InformerSelector `yaml:"selectors"`
+	// ThroughputWindow size of the sliding window used to calculate the scheduler throughput
+	ThroughputWindow time.Duration `yaml:"throughputWindow"`
+	// RestartThreshold pods with more container restarts are flagged by the containerRestarts measurement
+	RestartThreshold int32 `yaml:"restartThreshold"`
+	// FailOnRestarts fails the job when any pod exceeds the restart threshold
+	FailOnRestarts bool `yaml:"failOnRestarts"`
+	// FailOnPressure fails the job when any node reports memory, disk or PID pressure
+	FailOnPressure bool `yaml:"failOnPressure"`
+}
+
+// InformerSelector holds the selectors used to scope a measurement informer
+type InformerSelector struct {
+	// LabelSelector only watch objects with these labels
+	LabelSelector map[string]string `yaml:"labelSelector"`
+	// FieldSelector only watch objects matching this field selector
+	FieldSelector string `yaml:"fieldSelector"`
 }
 
 // LatencyThreshold holds the thresholds configuration
@@ -62,6 +85,8 @@ type LatencyThreshold struct {
 	ConditionType string `yaml:"conditionType"`
 	// Metric type
 	Metric string `yaml:"metric"`
+	// Percentile arbitrary percentile, between 0 and 100, checked instead of the metric when set
+	Percentile float64 `yaml:"percentile"`
 	// Threshold accepted
 	Threshold time.Duration `yaml:"threshold"`
 }
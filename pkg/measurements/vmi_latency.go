@@ -28,7 +28,6 @@ import (
 	"github.com/cloud-bulldozer/kube-burner/pkg/measurements/types"
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/client-go/rest"
@@ -301,9 +300,7 @@ func (p *vmiLatency) start(measurementWg *sync.WaitGroup) {
 		"vmWatcher",
 		"virtualmachines",
 		corev1.NamespaceAll,
-		func(options *metav1.ListOptions) {
-			options.LabelSelector = fmt.Sprintf("kube-burner-runid=%s", globalCfg.RUNID)
-		},
+		informerListOptions(p.config, "virtualmachines"),
 	)
 	p.vmWatcher.Informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: p.handleCreateVM,
@@ -321,9 +318,7 @@ func (p *vmiLatency) start(measurementWg *sync.WaitGroup) {
 		"vmiWatcher",
 		"virtualmachineinstances",
 		corev1.NamespaceAll,
-		func(options *metav1.ListOptions) {
-			options.LabelSelector = fmt.Sprintf("kube-burner-runid=%s", globalCfg.RUNID)
-		},
+		informerListOptions(p.config, "virtualmachineinstances"),
 	)
 	p.vmiWatcher.Informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: p.handleCreateVMI,
@@ -341,9 +336,7 @@ func (p *vmiLatency) start(measurementWg *sync.WaitGroup) {
 		"podWatcher",
 		"pods",
 		corev1.NamespaceAll,
-		func(options *metav1.ListOptions) {
-			options.LabelSelector = fmt.Sprintf("kube-burner-runid=%s", globalCfg.RUNID)
-		},
+		informerListOptions(p.config, "pods"),
 	)
 	p.vmiPodWatcher.Informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: p.handleCreateVMIPod,
@@ -393,10 +386,11 @@ func (p *vmiLatency) stop() error {
 	p.vmiPodWatcher.StopWatcher()
 	p.normalizeMetrics()
 	p.calcQuantiles()
-	err := metrics.CheckThreshold(p.config.LatencyThresholds, p.latencyQuantiles)
+	err := metrics.CheckThreshold("vmiLatency", p.config.LatencyThresholds, p.latencyQuantiles)
 	if globalCfg.IndexerConfig.Type != "" {
 		p.index()
 	}
+	exportCSV("vmiLatency", latencyQuantilesRows(p.latencyQuantiles, nil))
 	// Reset latency slices, required in multi-job benchmarks
 	p.latencyQuantiles, p.normLatencies = nil, nil
 	return err
@@ -0,0 +1,90 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/util"
+	"github.com/prometheus/client_golang/api"
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// Querier runs instant and range queries against Prometheus
+type Querier interface {
+	Query(query string, time time.Time) (model.Value, error)
+	QueryRange(query string, start, end time.Time, step time.Duration) (model.Value, error)
+}
+
+// apiClient queries the Prometheus HTTP API through the configured proxy
+type apiClient struct {
+	api apiv1.API
+}
+
+// authTransport adds the basic or bearer authentication headers to the requests
+type authTransport struct {
+	transport http.RoundTripper
+	auth      Auth
+}
+
+func (t authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.auth.Username != "" {
+		req.SetBasicAuth(t.auth.Username, t.auth.Password)
+	}
+	if t.auth.Token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.auth.Token))
+	}
+	return t.transport.RoundTrip(req)
+}
+
+// newAPIClient returns a client of the given Prometheus endpoint, verifying the connection
+func newAPIClient(url string, auth Auth) (*apiClient, error) {
+	transport, err := util.NewHTTPTransport(auth.SkipTLSVerify, auth.CACert)
+	if err != nil {
+		return nil, err
+	}
+	c, err := api.NewClient(api.Config{
+		Address: url,
+		RoundTripper: authTransport{
+			transport: transport,
+			auth:      auth,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	client := &apiClient{api: apiv1.NewAPI(c)}
+	if _, err := client.api.Runtimeinfo(context.TODO()); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// Query runs an instant query at the given time
+func (c *apiClient) Query(query string, time time.Time) (model.Value, error) {
+	v, _, err := c.api.Query(context.TODO(), query, time)
+	return v, err
+}
+
+// QueryRange runs a range query
+func (c *apiClient) QueryRange(query string, start, end time.Time, step time.Duration) (model.Value, error) {
+	r := apiv1.Range{Start: start, End: end, Step: step}
+	v, _, err := c.api.QueryRange(context.TODO(), query, r)
+	return v, err
+}
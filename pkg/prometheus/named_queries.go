@@ -0,0 +1,98 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// queryReference matches the references to named queries, i.e. {{ query "cpuUsage" }}
+var queryReference = regexp.MustCompile(`{{-?\s*query\s+"([^"]+)"\s*-?}}`)
+
+// expandNamedQueries replaces the references to named queries in the given metric definitions with their expressions,
+// resolving the references among named queries first. The expansion is textual, so Prometheus evaluates a named query
+// within every query referencing it, no result is shared. Definitions without metricName are only referenced, so they're
+// not returned. Unknown references and reference cycles are reported as errors
+func expandNamedQueries(metricProfile []metricDefinition) ([]metricDefinition, error) {
+	namedQueries := make(map[string]string)
+	for _, md := range metricProfile {
+		if md.Name == "" {
+			continue
+		}
+		if _, exists := namedQueries[md.Name]; exists {
+			return nil, fmt.Errorf("duplicated query name %s", md.Name)
+		}
+		namedQueries[md.Name] = md.Query
+	}
+	expanded := make(map[string]string, len(namedQueries))
+	var expand func(name string, path []string) (string, error)
+	expand = func(name string, path []string) (string, error) {
+		if query, done := expanded[name]; done {
+			return query, nil
+		}
+		for i, visited := range path {
+			if visited == name {
+				return "", fmt.Errorf("query reference cycle: %s", strings.Join(append(path[i:], name), " -> "))
+			}
+		}
+		query, err := expandReferences(namedQueries[name], namedQueries, func(ref string) (string, error) {
+			return expand(ref, append(path, name))
+		})
+		if err != nil {
+			return "", err
+		}
+		expanded[name] = query
+		return query, nil
+	}
+	var scraped []metricDefinition
+	for _, md := range metricProfile {
+		var err error
+		if md.Name != "" {
+			md.Query, err = expand(md.Name, nil)
+		} else {
+			md.Query, err = expandReferences(md.Query, namedQueries, func(ref string) (string, error) {
+				return expand(ref, nil)
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+		if md.MetricName != "" {
+			scraped = append(scraped, md)
+		}
+	}
+	return scraped, nil
+}
+
+// expandReferences replaces the named query references of the given query with the parenthesized expression returned by resolve
+func expandReferences(query string, namedQueries map[string]string, resolve func(string) (string, error)) (string, error) {
+	var err error
+	expandedQuery := queryReference.ReplaceAllStringFunc(query, func(reference string) string {
+		name := queryReference.FindStringSubmatch(reference)[1]
+		if _, exists := namedQueries[name]; !exists {
+			err = fmt.Errorf("query %s not found", name)
+			return reference
+		}
+		resolved, resolveErr := resolve(name)
+		if resolveErr != nil {
+			err = resolveErr
+			return reference
+		}
+		return "(" + resolved + ")"
+	})
+	return expandedQuery, err
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandNamedQueries(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile []metricDefinition
+		want    []metricDefinition
+		wantErr string
+	}{
+		{
+			name: "references are parenthesized",
+			profile: []metricDefinition{
+				{Name: "cpu", Query: "sum(rate(cpu[2m]))"},
+				{Query: `{{ query "cpu" }} * 100`, MetricName: "cpuPercent"},
+			},
+			want: []metricDefinition{
+				{Query: "(sum(rate(cpu[2m]))) * 100", MetricName: "cpuPercent"},
+			},
+		},
+		{
+			name: "named queries reference each other",
+			profile: []metricDefinition{
+				{Name: "a", Query: "up"},
+				{Name: "b", Query: `{{ query "a" }} > 0`, MetricName: "b"},
+				{Query: `count({{- query "b" -}})`, MetricName: "c"},
+			},
+			want: []metricDefinition{
+				{Name: "b", Query: "(up) > 0", MetricName: "b"},
+				{Query: "count(((up) > 0))", MetricName: "c"},
+			},
+		},
+		{
+			name: "unknown reference",
+			profile: []metricDefinition{
+				{Query: `{{ query "missing" }}`, MetricName: "m"},
+			},
+			wantErr: "query missing not found",
+		},
+		{
+			name: "duplicated name",
+			profile: []metricDefinition{
+				{Name: "a", Query: "up"},
+				{Name: "a", Query: "down"},
+			},
+			wantErr: "duplicated query name a",
+		},
+		{
+			name: "self reference",
+			profile: []metricDefinition{
+				{Name: "a", Query: `{{ query "a" }}`},
+			},
+			wantErr: "query reference cycle: a -> a",
+		},
+		{
+			name: "reference cycle",
+			profile: []metricDefinition{
+				{Name: "a", Query: `{{ query "b" }}`},
+				{Name: "b", Query: `{{ query "c" }}`},
+				{Name: "c", Query: `{{ query "a" }}`},
+				{Query: `{{ query "b" }}`, MetricName: "m"},
+			},
+			wantErr: "query reference cycle: a -> b -> c -> a",
+		},
+	}
+	for _, tt := range tests {
+		got, err := expandNamedQueries(tt.profile)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: expandNamedQueries() error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: expandNamedQueries() error = %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expandNamedQueries() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
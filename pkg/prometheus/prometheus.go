@@ -16,14 +16,16 @@ package prometheus
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"math"
 	"path"
+	"sort"
 	"text/template"
 	"time"
 
-	"github.com/cloud-bulldozer/go-commons/prometheus"
 	"github.com/cloud-bulldozer/kube-burner/pkg/config"
 	"github.com/cloud-bulldozer/kube-burner/pkg/util"
 	"github.com/prometheus/common/model"
@@ -43,35 +45,49 @@ func NewPrometheusClient(configSpec config.Spec, url string, auth Auth, step tim
 		embedConfig: embedConfig,
 	}
 	log.Infof("👽 Initializing prometheus client with URL: %s", url)
-	p.Client, err = prometheus.NewClient(url, auth.Token, auth.Username, auth.Password, auth.SkipTLSVerify)
+	client, err := newAPIClient(url, auth)
 	if err != nil {
 		return &p, err
 	}
+	p.Client = client
 	return &p, nil
 }
 
-// ScrapeJobsMetrics gets all prometheus metrics required and handles them
-func (p *Prometheus) ScrapeJobsMetrics(docsToIndex map[string][]interface{}) error {
-	start := p.JobList[0].Start
-	end := p.JobList[len(p.JobList)-1].End
+// ScrapeJobsMetrics gets all prometheus metrics required and handles them. The wait for the scrapeOffsetAfter of the
+// last job stops once the given context is cancelled
+func (p *Prometheus) ScrapeJobsMetrics(ctx context.Context, docsToIndex map[string][]interface{}) error {
+	windows := p.jobWindows()
+	// The windows padded by scrapeOffsetAfter can end in the future, the tail of the cluster activity is waited for
+	if wait := time.Until(windows[len(windows)-1].end); wait > 0 {
+		log.Infof("Waiting %v for the scrapeOffsetAfter of the last job to elapse", wait.Round(time.Second))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			log.Warnf("Stopped waiting for the scrapeOffsetAfter of the last job, scraping the metrics collected so far")
+		}
+	}
 	log.Infof("🔍 Scraping %v Profile: %v Start: %v End: %v",
 		p.Endpoint,
 		p.profileName,
-		start.Format(time.RFC3339),
-		end.Format(time.RFC3339))
-	elapsed := int(end.Sub(start).Seconds())
+		windows[0].start.Format(time.RFC3339),
+		windows[len(windows)-1].end.Format(time.RFC3339))
 	var renderedQuery bytes.Buffer
-	vars := util.EnvToMap()
-	vars["elapsed"] = fmt.Sprintf("%ds", elapsed)
-	for _, eachJob := range p.JobList {
+	for i, eachJob := range p.JobList {
 		if eachJob.JobConfig.SkipIndexing {
 			log.Infof("Skipping indexing in job: %v", eachJob.JobConfig.Name)
 			continue
 		}
-		jobStart := eachJob.Start
-		jobEnd := eachJob.End
+		jobStart, jobEnd := windows[i].start, windows[i].end
+		// elapsed is the duration of the scraping window of the job
+		vars := util.EnvToMap()
+		vars["elapsed"] = fmt.Sprintf("%ds", int(jobEnd.Sub(jobStart).Seconds()))
 		log.Info("Scraping metrics for job: ", eachJob.JobConfig.Name)
-		for _, md := range p.MetricProfile {
+		// Metrics from baseline jobs are indexed apart from the rest, with their own metricName
+		var metricSuffix string
+		if eachJob.JobConfig.JobType == config.BaselineJob {
+			metricSuffix = "-" + string(config.BaselineJob)
+		}
+		for _, md := range p.jobMetricProfile(eachJob.JobConfig) {
 			requiresInstant := false
 			t, _ := template.New("").Parse(md.Query)
 			if err := t.Execute(&renderedQuery, vars); err != nil {
@@ -80,23 +96,108 @@ func (p *Prometheus) ScrapeJobsMetrics(docsToIndex map[string][]interface{}) err
 			}
 			query := renderedQuery.String()
 			renderedQuery.Reset()
+			metricName := md.MetricName + metricSuffix
 			if md.Instant {
-				docsToIndex[md.MetricName+"-start"] = append(docsToIndex[md.MetricName+"-start"], p.runInstantQuery(query, md.MetricName+"-start", jobStart, eachJob.JobConfig)...)
-				docsToIndex[md.MetricName] = append(docsToIndex[md.MetricName], p.runInstantQuery(query, md.MetricName, jobEnd, eachJob.JobConfig)...)
+				docsToIndex[metricName+"-start"] = append(docsToIndex[metricName+"-start"], p.runInstantQuery(query, metricName+"-start", jobStart, eachJob.JobConfig)...)
+				docsToIndex[metricName] = append(docsToIndex[metricName], p.runInstantQuery(query, metricName, jobEnd, eachJob.JobConfig)...)
 			} else {
 				requiresInstant = ((jobEnd.Sub(jobStart).Milliseconds())%(p.Step.Milliseconds()) != 0)
-				docsToIndex[md.MetricName] = append(docsToIndex[md.MetricName], p.runRangeQuery(query, md.MetricName, jobStart, jobEnd, eachJob.JobConfig)...)
+				docsToIndex[metricName] = append(docsToIndex[metricName], p.runRangeQuery(query, metricName, jobStart, jobEnd, eachJob.JobConfig)...)
 			}
 			if requiresInstant {
-				docsToIndex[md.MetricName] = append(docsToIndex[md.MetricName], p.runInstantQuery(query, md.MetricName, jobEnd, eachJob.JobConfig)...)
+				docsToIndex[metricName] = append(docsToIndex[metricName], p.runInstantQuery(query, metricName, jobEnd, eachJob.JobConfig)...)
 			}
 		}
 	}
 	return nil
 }
 
-// Parse vector parses results for an instant query
+// SnapshotMetrics runs the queries of the metrics profile as instant queries at the given time, the query
+// variable elapsed is set to the step, as there's no time range to scrape
+func (p *Prometheus) SnapshotMetrics(timestamp time.Time, jobConfig config.Job, docsToIndex map[string][]interface{}) {
+	log.Infof("📸 Taking snapshot of %v Profile: %v Time: %v", p.Endpoint, p.profileName, timestamp.Format(time.RFC3339))
+	var renderedQuery bytes.Buffer
+	vars := util.EnvToMap()
+	vars["elapsed"] = fmt.Sprintf("%ds", int(p.Step.Seconds()))
+	for _, md := range p.MetricProfile {
+		t, _ := template.New("").Parse(md.Query)
+		if err := t.Execute(&renderedQuery, vars); err != nil {
+			log.Warnf("Error rendering query: %v", err)
+			continue
+		}
+		query := renderedQuery.String()
+		renderedQuery.Reset()
+		docsToIndex[md.MetricName] = append(docsToIndex[md.MetricName], p.runInstantQuery(query, md.MetricName, timestamp, jobConfig)...)
+	}
+}
+
+// scrapeWindow time range scraped for a job
+type scrapeWindow struct {
+	start time.Time
+	end   time.Time
+}
+
+// jobWindows returns the scraping windows of the jobs, padded by scrapeOffsetBefore and scrapeOffsetAfter. Every instant
+// is attributed to a single job: the time between two consecutive jobs, like the indexing of the measurements, belongs to
+// the job that finished, up to scrapeOffsetAfter, and the rest to the following job, whose window starts where the
+// previous one ends
+func (p *Prometheus) jobWindows() []scrapeWindow {
+	windows := make([]scrapeWindow, len(p.JobList))
+	for i, job := range p.JobList {
+		windows[i] = scrapeWindow{
+			start: job.Start.Add(-p.ConfigSpec.GlobalConfig.ScrapeOffsetBefore),
+			end:   job.End.Add(p.ConfigSpec.GlobalConfig.ScrapeOffsetAfter),
+		}
+	}
+	for i := 1; i < len(windows); i++ {
+		prev, next := p.JobList[i-1], p.JobList[i]
+		// Jobs running at the same time keep their windows
+		if next.Start.Before(prev.End) {
+			continue
+		}
+		if windows[i-1].end.After(next.Start) {
+			windows[i-1].end = next.Start
+		}
+		windows[i].start = windows[i-1].end
+	}
+	return windows
+}
+
+// ReadJobSummaries reads the job timeline from the given jobSummary documents, as generated by the local indexer
+func ReadJobSummaries(jobSummaryFiles []string) ([]Job, error) {
+	var jobList []Job
+	for _, jobSummaryFile := range jobSummaryFiles {
+		var jobSummaries []struct {
+			Start     time.Time  `json:"timestamp"`
+			End       time.Time  `json:"endTimestamp"`
+			JobConfig config.Job `json:"jobConfig"`
+		}
+		f, err := util.ReadConfig(jobSummaryFile)
+		if err == nil {
+			f, err = util.MaybeGunzip(f)
+		}
+		if err != nil {
+			return jobList, fmt.Errorf("error reading job summary %s: %s", jobSummaryFile, err)
+		}
+		if err := json.NewDecoder(f).Decode(&jobSummaries); err != nil {
+			return jobList, fmt.Errorf("error decoding job summary %s: %s", jobSummaryFile, err)
+		}
+		for _, js := range jobSummaries {
+			jobList = append(jobList, Job{Start: js.Start, End: js.End, JobConfig: js.JobConfig})
+		}
+	}
+	sort.Slice(jobList, func(i, j int) bool {
+		return jobList[i].Start.Before(jobList[j].Start)
+	})
+	return jobList, nil
+}
+
+// Parse vector parses results for an instant query, scalar results are handled as a single sample without labels
 func (p *Prometheus) parseVector(metricName, query string, jobConfig config.Job, value model.Value, metrics *[]interface{}) error {
+	if scalar, ok := value.(*model.Scalar); ok {
+		*metrics = append(*metrics, p.createMetric(query, metricName, jobConfig, model.Metric{}, scalar.Value, scalar.Timestamp.Time().UTC()))
+		return nil
+	}
 	data, ok := value.(model.Vector)
 	if !ok {
 		return fmt.Errorf("unsupported result format: %s", value.Type().String())
@@ -125,32 +226,68 @@ func (p *Prometheus) parseMatrix(metricName, query string, jobConfig config.Job,
 
 // ReadProfile reads, parses and validates metric profile configuration
 func (p *Prometheus) ReadProfile(metricsProfile string) error {
+	var err error
+	p.profileName = metricsProfile
+	p.MetricProfile, err = p.readProfile(metricsProfile)
+	return err
+}
+
+// ReadJobProfiles reads, parses and validates the metrics profiles of the configured jobs
+func (p *Prometheus) ReadJobProfiles() error {
+	p.jobProfiles = make(map[string][]metricDefinition)
+	for _, job := range p.ConfigSpec.Jobs {
+		if job.MetricsProfile == "" {
+			continue
+		}
+		metricProfile, err := p.readProfile(job.MetricsProfile)
+		if err != nil {
+			return fmt.Errorf("job %s: %s", job.Name, err)
+		}
+		p.jobProfiles[job.Name] = metricProfile
+	}
+	return nil
+}
+
+// jobMetricProfile returns the metric definitions to scrape within the given job
+func (p *Prometheus) jobMetricProfile(jobConfig config.Job) []metricDefinition {
+	jobProfile := p.jobProfiles[jobConfig.Name]
+	if jobConfig.ReplaceGlobalMetricsProfile {
+		return jobProfile
+	}
+	return append(append([]metricDefinition{}, p.MetricProfile...), jobProfile...)
+}
+
+func (p *Prometheus) readProfile(metricsProfile string) ([]metricDefinition, error) {
 	var f io.Reader
 	var err error
+	var metricProfile []metricDefinition
 	if p.embedConfig {
 		metricsProfile = path.Join(path.Dir(p.ConfigSpec.EmbedFSDir), metricsProfile)
 		f, err = util.ReadEmbedConfig(p.ConfigSpec.EmbedFS, metricsProfile)
 	} else {
 		f, err = util.ReadConfig(metricsProfile)
 	}
-	p.profileName = metricsProfile
 	if err != nil {
-		return fmt.Errorf("error reading metrics profile %s: %s", metricsProfile, err)
+		return metricProfile, fmt.Errorf("error reading metrics profile %s: %s", metricsProfile, err)
 	}
 	yamlDec := yaml.NewDecoder(f)
 	yamlDec.KnownFields(true)
-	if err = yamlDec.Decode(&p.MetricProfile); err != nil {
-		return fmt.Errorf("error decoding metrics profile %s: %s", metricsProfile, err)
+	if err = yamlDec.Decode(&metricProfile); err != nil {
+		return metricProfile, fmt.Errorf("error decoding metrics profile %s: %s", metricsProfile, err)
 	}
-	for i, md := range p.MetricProfile {
+	for i, md := range metricProfile {
 		if md.Query == "" {
-			return fmt.Errorf("query not defined in %d element", i)
+			return metricProfile, fmt.Errorf("query not defined in %d element", i)
 		}
-		if md.MetricName == "" {
-			return fmt.Errorf("metricName not defined in %d element", i)
+		// Named queries can be only referenced by other queries
+		if md.MetricName == "" && md.Name == "" {
+			return metricProfile, fmt.Errorf("metricName not defined in %d element", i)
 		}
 	}
-	return nil
+	if metricProfile, err = expandNamedQueries(metricProfile); err != nil {
+		return metricProfile, fmt.Errorf("error in metrics profile %s: %s", metricsProfile, err)
+	}
+	return metricProfile, nil
 }
 
 // Create metric creates metric to be indexed
@@ -159,7 +296,9 @@ func (p *Prometheus) createMetric(query, metricName string, jobConfig config.Job
 		Labels:     make(map[string]string),
 		UUID:       p.UUID,
 		Query:      query,
+		Endpoint:   p.Endpoint,
 		MetricName: metricName,
+		JobName:    jobConfig.Name,
 		JobConfig:  jobConfig,
 		Timestamp:  timestamp,
 		Metadata:   p.metadata,
@@ -193,6 +332,29 @@ func (p *Prometheus) runInstantQuery(query, metricName string, timestamp time.Ti
 	return datapoints
 }
 
+// EvaluateExpr runs the given expression as an instant query at the current time, it's true when the
+// result has samples and all of them are different than 0
+func (p *Prometheus) EvaluateExpr(expr string) (bool, error) {
+	log.Debugf("Instant query: %s", expr)
+	v, err := p.Client.Query(expr, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+	switch result := v.(type) {
+	case model.Vector:
+		for _, sample := range result {
+			if sample.Value == 0 {
+				return false, nil
+			}
+		}
+		return len(result) > 0, nil
+	case *model.Scalar:
+		return result.Value != 0, nil
+	default:
+		return false, fmt.Errorf("unsupported result type %s of expression %s", v.Type(), expr)
+	}
+}
+
 // runRangeQuery function to run a range query
 func (p *Prometheus) runRangeQuery(query, metricName string, jobStart, jobEnd time.Time, jobConfig config.Job) []interface{} {
 	var v model.Value
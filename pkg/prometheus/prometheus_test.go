@@ -0,0 +1,78 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+)
+
+func TestJobWindows(t *testing.T) {
+	t0 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return t0.Add(time.Duration(minutes) * time.Minute) }
+	tests := []struct {
+		name   string
+		before time.Duration
+		after  time.Duration
+		jobs   []Job
+		want   []scrapeWindow
+	}{
+		{
+			name:   "single job is padded",
+			before: time.Minute,
+			after:  2 * time.Minute,
+			jobs:   []Job{{Start: at(10), End: at(20)}},
+			want:   []scrapeWindow{{start: at(9), end: at(22)}},
+		},
+		{
+			name:   "gap longer than the offsets",
+			before: time.Minute,
+			after:  2 * time.Minute,
+			jobs:   []Job{{Start: at(0), End: at(10)}, {Start: at(20), End: at(30)}},
+			want:   []scrapeWindow{{start: at(-1), end: at(12)}, {start: at(12), end: at(32)}},
+		},
+		{
+			name:   "gap shorter than scrapeOffsetAfter",
+			before: time.Minute,
+			after:  5 * time.Minute,
+			jobs:   []Job{{Start: at(0), End: at(10)}, {Start: at(12), End: at(20)}},
+			want:   []scrapeWindow{{start: at(-1), end: at(12)}, {start: at(12), end: at(25)}},
+		},
+		{
+			name: "consecutive jobs without offsets",
+			jobs: []Job{{Start: at(0), End: at(10)}, {Start: at(15), End: at(20)}},
+			want: []scrapeWindow{{start: at(0), end: at(10)}, {start: at(10), end: at(20)}},
+		},
+		{
+			name:   "overlapping jobs keep their windows",
+			before: time.Minute,
+			after:  time.Minute,
+			jobs:   []Job{{Start: at(0), End: at(10)}, {Start: at(5), End: at(20)}},
+			want:   []scrapeWindow{{start: at(-1), end: at(11)}, {start: at(4), end: at(21)}},
+		},
+	}
+	for _, tt := range tests {
+		p := Prometheus{
+			JobList:    tt.jobs,
+			ConfigSpec: config.Spec{GlobalConfig: config.GlobalConfig{ScrapeOffsetBefore: tt.before, ScrapeOffsetAfter: tt.after}},
+		}
+		if got := p.jobWindows(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: jobWindows() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
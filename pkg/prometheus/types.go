@@ -17,7 +17,6 @@ package prometheus
 import (
 	"time"
 
-	"github.com/cloud-bulldozer/go-commons/prometheus"
 	"github.com/cloud-bulldozer/kube-burner/pkg/config"
 )
 
@@ -26,20 +25,24 @@ type Auth struct {
 	Password      string
 	Token         string
 	SkipTLSVerify bool
+	// CACert path to the PEM bundle of the CAs the server certificate is verified against
+	CACert string
 }
 
 // Prometheus describes the prometheus connection
 type Prometheus struct {
-	Client        *prometheus.Prometheus
+	Client        Querier
 	Endpoint      string
 	profileName   string
 	MetricProfile []metricDefinition
-	Step          time.Duration
-	UUID          string
-	ConfigSpec    config.Spec
-	JobList       []Job
-	metadata      map[string]interface{}
-	embedConfig   bool
+	// jobProfiles holds the metrics profiles of the jobs, indexed by job name
+	jobProfiles map[string][]metricDefinition
+	Step        time.Duration
+	UUID        string
+	ConfigSpec  config.Spec
+	JobList     []Job
+	metadata    map[string]interface{}
+	embedConfig bool
 }
 
 type Job struct {
@@ -50,6 +53,8 @@ type Job struct {
 
 // metricDefinition describes what metrics kube-burner collects
 type metricDefinition struct {
+	// Name names the query, so other queries of the profile can reference it with {{ query "name" }}
+	Name       string `yaml:"name"`
 	Query      string `yaml:"query"`
 	MetricName string `yaml:"metricName"`
 	Instant    bool   `yaml:"instant"`
@@ -57,10 +62,18 @@ type metricDefinition struct {
 
 // MetricEndpoint describes prometheus endpoint to scrape
 type MetricEndpoint struct {
-	Endpoint     string `yaml:"endpoint"`
+	Endpoint string `yaml:"endpoint"`
+	// URL alias of Endpoint
+	URL          string `yaml:"url"`
 	Token        string `yaml:"token"`
+	Username     string `yaml:"username"`
+	Password     string `yaml:"password"`
 	Profile      string `yaml:"profile"`
 	AlertProfile string `yaml:"alertProfile"`
+	// SkipTLSVerify overrides the --skip-tls-verify flag for this endpoint when set
+	SkipTLSVerify *bool `yaml:"skipTLSVerify"`
+	// CACert overrides the --ca-cert flag for this endpoint when set, the TLS verification is enabled unless skipTLSVerify is set
+	CACert string `yaml:"caCert"`
 }
 
 type metric struct {
@@ -69,7 +82,9 @@ type metric struct {
 	Value      float64           `json:"value"`
 	UUID       string            `json:"uuid"`
 	Query      string            `json:"query"`
+	Endpoint   string            `json:"endpoint,omitempty"`
 	MetricName string            `json:"metricName,omitempty"`
+	JobName    string            `json:"jobName,omitempty"`
 	JobConfig  config.Job        `json:"jobConfig,omitempty"`
 	Metadata   interface{}       `json:"metadata,omitempty"`
 }
@@ -0,0 +1,140 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// GitPrefix prefix of the templates read from a git repository
+	GitPrefix = "git://"
+	// GitTokenEnv environment variable holding the token used to clone repositories over HTTPS
+	GitTokenEnv = "KUBE_BURNER_GIT_TOKEN"
+	// GitSSHKeyEnv environment variable holding the path of the private key used to clone repositories over SSH
+	GitSSHKeyEnv = "KUBE_BURNER_GIT_SSH_KEY"
+)
+
+// GitReference references a file within a git repository, in git://<repository>@<ref>/<path> format
+type GitReference struct {
+	// Repository host and path of the repository, i.e. github.com/org/repo
+	Repository string
+	// Ref branch, tag or commit checked out
+	Ref string
+	// Path of the file within the repository
+	Path string
+}
+
+// gitCheckouts holds the directories where each repository and ref were checked out, so they're cloned once
+var gitCheckouts = struct {
+	sync.Mutex
+	dirs map[string]string
+}{dirs: make(map[string]string)}
+
+// ParseGitReference parses a reference in git://<repository>@<ref>/<path> format
+func ParseGitReference(reference string) (GitReference, error) {
+	var gitRef GitReference
+	invalidErr := fmt.Errorf("invalid git reference %q, expected %s<repository>@<ref>/<path>", reference, GitPrefix)
+	repository, refPath, found := strings.Cut(strings.TrimPrefix(reference, GitPrefix), "@")
+	if !found || repository == "" {
+		return gitRef, invalidErr
+	}
+	ref, filePath, found := strings.Cut(refPath, "/")
+	if !found || ref == "" || filePath == "" {
+		return gitRef, invalidErr
+	}
+	return GitReference{Repository: repository, Ref: ref, Path: filePath}, nil
+}
+
+// readGitFile reads the referenced file, cloning its repository at the given ref if it wasn't cloned yet
+func readGitFile(reference string) (io.Reader, error) {
+	gitRef, err := ParseGitReference(reference)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := gitCheckout(gitRef)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(filepath.Join(dir, filepath.FromSlash(gitRef.Path)))
+}
+
+// gitCheckout returns the directory where the repository is checked out at the reference ref
+func gitCheckout(gitRef GitReference) (string, error) {
+	gitCheckouts.Lock()
+	defer gitCheckouts.Unlock()
+	key := gitRef.Repository + "@" + gitRef.Ref
+	if dir, ok := gitCheckouts.dirs[key]; ok {
+		return dir, nil
+	}
+	dir, err := os.MkdirTemp("", "kube-burner-git-")
+	if err != nil {
+		return "", err
+	}
+	log.Infof("Cloning %s at %s", gitRef.Repository, gitRef.Ref)
+	env := os.Environ()
+	repoURL := "https://" + gitRef.Repository
+	if sshKey := os.Getenv(GitSSHKeyEnv); sshKey != "" {
+		host, repoPath, _ := strings.Cut(gitRef.Repository, "/")
+		repoURL = fmt.Sprintf("git@%s:%s", host, repoPath)
+		env = append(env, fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %s -o IdentitiesOnly=yes -o StrictHostKeyChecking=accept-new", sshKey))
+	} else if token := os.Getenv(GitTokenEnv); token != "" {
+		// The token is passed as a header, through the environment so it's neither persisted in the remote URL
+		// nor visible in the arguments of the git processes
+		auth := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+		env = append(env, "GIT_CONFIG_COUNT=1", "GIT_CONFIG_KEY_0=http.extraHeader", "GIT_CONFIG_VALUE_0=Authorization: Basic "+auth)
+	}
+	// Fetching the ref supports branches, tags and commits alike
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"remote", "add", "origin", repoURL},
+		{"fetch", "--quiet", "--depth", "1", "origin", gitRef.Ref},
+		{"checkout", "--quiet", "FETCH_HEAD"},
+	} {
+		var stderr bytes.Buffer
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = env
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("error cloning %s at %s: git %s: %s: %s", gitRef.Repository, gitRef.Ref, args[0], err, strings.TrimSpace(stderr.String()))
+		}
+	}
+	gitCheckouts.dirs[key] = dir
+	return dir, nil
+}
+
+// CleanupGitCheckouts removes the repositories cloned to read templates
+func CleanupGitCheckouts() {
+	gitCheckouts.Lock()
+	defer gitCheckouts.Unlock()
+	for key, dir := range gitCheckouts.dirs {
+		if err := os.RemoveAll(dir); err != nil {
+			log.Warnf("Error removing %s: %s", dir, err)
+		}
+		delete(gitCheckouts.dirs, key)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import "testing"
+
+func TestParseGitReference(t *testing.T) {
+	tests := []struct {
+		reference string
+		want      GitReference
+		wantErr   bool
+	}{
+		{
+			reference: "git://github.com/org/repo@main/templates/deployment.yml",
+			want:      GitReference{Repository: "github.com/org/repo", Ref: "main", Path: "templates/deployment.yml"},
+		},
+		{
+			reference: "git://gitlab.example.com/group/sub/repo@v1.2.0/pod.yml",
+			want:      GitReference{Repository: "gitlab.example.com/group/sub/repo", Ref: "v1.2.0", Path: "pod.yml"},
+		},
+		{
+			reference: "git://github.com/org/repo@3f2a1b9/a/b/c.yml",
+			want:      GitReference{Repository: "github.com/org/repo", Ref: "3f2a1b9", Path: "a/b/c.yml"},
+		},
+		{reference: "git://github.com/org/repo/pod.yml", wantErr: true},
+		{reference: "git://@main/pod.yml", wantErr: true},
+		{reference: "git://github.com/org/repo@main", wantErr: true},
+		{reference: "git://github.com/org/repo@/pod.yml", wantErr: true},
+		{reference: "git://github.com/org/repo@main/", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseGitReference(tt.reference)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseGitReference(%q) error = %v, wantErr %v", tt.reference, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseGitReference(%q) = %+v, want %+v", tt.reference, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Fields added to the structured log entries
+const (
+	LogFieldUUID      = "uuid"
+	LogFieldJob       = "job"
+	LogFieldIteration = "iteration"
+)
+
+// LogContextHook adds the fields of the current run context, such as the UUID or the running job, to every log entry
+type LogContextHook struct {
+	lock   sync.RWMutex
+	fields log.Fields
+}
+
+// logContext is the hook registered by SetupLogging when structured logging is enabled
+var logContext = &LogContextHook{fields: log.Fields{}}
+
+// Levels implements logrus.Hook
+func (h *LogContextHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+// Fire implements logrus.Hook, fields already set in the entry take precedence
+func (h *LogContextHook) Fire(entry *log.Entry) error {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	for k, v := range h.fields {
+		if _, ok := entry.Data[k]; !ok {
+			entry.Data[k] = v
+		}
+	}
+	return nil
+}
+
+// SetLogContext sets a field added to every structured log entry
+func SetLogContext(key string, value interface{}) {
+	logContext.lock.Lock()
+	defer logContext.lock.Unlock()
+	logContext.fields[key] = value
+}
+
+// ClearLogContext removes a field previously set with SetLogContext
+func ClearLogContext(key string) {
+	logContext.lock.Lock()
+	defer logContext.lock.Unlock()
+	delete(logContext.fields, key)
+}
+
+// SetupLogging configures the output of the logger, logs are written to stderr and to logFile when given.
+// The log file is closed by the logrus exit handlers, so log.Exit must be used to terminate the process.
+func SetupLogging(logFile string, jsonFormat bool, formatter *log.TextFormatter) error {
+	if jsonFormat {
+		log.SetFormatter(&log.JSONFormatter{
+			TimestampFormat:  formatter.TimestampFormat,
+			CallerPrettyfier: formatter.CallerPrettyfier,
+		})
+		log.AddHook(logContext)
+	} else {
+		log.SetFormatter(formatter)
+	}
+	if logFile == "" {
+		return nil
+	}
+	f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening log file: %s", err)
+	}
+	log.SetOutput(io.MultiWriter(os.Stderr, f))
+	log.RegisterExitHandler(func() {
+		f.Close()
+	})
+	return nil
+}
@@ -0,0 +1,200 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/util"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// AzureMonitorIndexer posts documents to a Log Analytics workspace using the HTTP Data Collector API
+	AzureMonitorIndexer       indexers.IndexerType = "azuremonitor"
+	azureMonitorDomain                             = "ods.opinsights.azure.com"
+	azureMonitorResource                           = "/api/logs"
+	azureMonitorAPIVersion                         = "2016-04-01"
+	azureMonitorLogType                            = "KubeBurner"
+	azureMonitorBatchSize                          = 500
+	azureMonitorMaxRetries                         = 5
+	azureMonitorRetryInterval                      = 2 * time.Second
+	// azureMonitorMaxPayload keeps the requests below the 30MB limit of the Data Collector API
+	azureMonitorMaxPayload = 25 * 1024 * 1024
+)
+
+// azureMonitorLogTypeRegex matches the record types accepted by the Data Collector API
+var azureMonitorLogTypeRegex = regexp.MustCompile(`^[A-Za-z0-9_]{1,100}$`)
+
+// azureMonitorIndexer implements indexers.Indexer, it embeds the interface only to satisfy its unexported method
+type azureMonitorIndexer struct {
+	indexers.Indexer
+	workspaceID string
+	sharedKey   []byte
+	logType     string
+	url         string
+	batchSize   int
+	maxRetries  int
+	client      *http.Client
+}
+
+// azureMonitorRetryableError is returned when the Data Collector API fails with a transient error
+type azureMonitorRetryableError struct {
+	error
+	retryAfter time.Duration
+}
+
+func newAzureMonitorIndexer(indexerConfig config.IndexerConfig) (*azureMonitorIndexer, error) {
+	azureConfig := indexerConfig.AzureMonitor
+	if azureConfig.WorkspaceID == "" || azureConfig.SharedKey == "" {
+		return nil, fmt.Errorf("azureMonitor workspaceID and sharedKey must be specified")
+	}
+	sharedKey, err := base64.StdEncoding.DecodeString(azureConfig.SharedKey)
+	if err != nil {
+		return nil, fmt.Errorf("azureMonitor sharedKey must be base64 encoded: %s", err)
+	}
+	indexer := &azureMonitorIndexer{
+		workspaceID: azureConfig.WorkspaceID,
+		sharedKey:   sharedKey,
+		logType:     azureConfig.LogType,
+		batchSize:   azureConfig.BatchSize,
+		maxRetries:  azureConfig.MaxRetries,
+		client: &http.Client{
+			Timeout:   time.Minute,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: indexerConfig.InsecureSkipVerify}, Proxy: util.ProxyFunc()},
+		},
+	}
+	domain := azureConfig.Domain
+	if domain == "" {
+		domain = azureMonitorDomain
+	}
+	indexer.url = fmt.Sprintf("https://%s.%s%s?api-version=%s", indexer.workspaceID, domain, azureMonitorResource, azureMonitorAPIVersion)
+	if indexer.logType == "" {
+		indexer.logType = azureMonitorLogType
+	}
+	if !azureMonitorLogTypeRegex.MatchString(indexer.logType) {
+		return nil, fmt.Errorf("invalid azureMonitor logType %s, only letters, numbers and underscores are allowed, up to 100 characters", indexer.logType)
+	}
+	if indexer.batchSize <= 0 {
+		indexer.batchSize = azureMonitorBatchSize
+	}
+	if indexer.maxRetries <= 0 {
+		indexer.maxRetries = azureMonitorMaxRetries
+	}
+	return indexer, nil
+}
+
+// Index sends the documents in batches, as JSON arrays, limited by the batch size and the maximum payload of the API
+func (a *azureMonitorIndexer) Index(documents []interface{}, opts indexers.IndexingOpts) (string, error) {
+	var batch [][]byte
+	var batchBytes, batchStart int
+	for i, document := range documents {
+		record, err := json.Marshal(document)
+		if err != nil {
+			return "", fmt.Errorf("JSON encoding error: %s", err)
+		}
+		if len(batch) > 0 && (len(batch) == a.batchSize || batchBytes+len(record) > azureMonitorMaxPayload) {
+			if err := a.send(batch); err != nil {
+				return "", fmt.Errorf("error sending documents %d-%d to workspace %s: %s", batchStart, i-1, a.workspaceID, err)
+			}
+			batch, batchBytes, batchStart = nil, 0, i
+		}
+		batch = append(batch, record)
+		batchBytes += len(record) + 1
+	}
+	if len(batch) > 0 {
+		if err := a.send(batch); err != nil {
+			return "", fmt.Errorf("error sending documents %d-%d to workspace %s: %s", batchStart, len(documents)-1, a.workspaceID, err)
+		}
+	}
+	return fmt.Sprintf("Indexed %d documents into workspace %s with log type %s", len(documents), a.workspaceID, a.logType), nil
+}
+
+// send posts a batch of records, retrying with exponential backoff on connection errors and transient HTTP status codes
+func (a *azureMonitorIndexer) send(records [][]byte) error {
+	var err error
+	payload := append(append([]byte{'['}, bytes.Join(records, []byte{','})...), ']')
+	interval := azureMonitorRetryInterval
+	for attempt := 0; attempt <= a.maxRetries; attempt++ {
+		if attempt > 0 {
+			wait := interval
+			if retryable, ok := err.(azureMonitorRetryableError); ok && retryable.retryAfter > wait {
+				wait = retryable.retryAfter
+			}
+			log.Warnf("Retrying Azure Monitor request in %v, attempt %d/%d: %s", wait, attempt, a.maxRetries, err)
+			time.Sleep(wait)
+			interval *= 2
+		}
+		err = a.post(payload)
+		if _, retryable := err.(azureMonitorRetryableError); !retryable {
+			return err
+		}
+	}
+	return err
+}
+
+// signature returns the SharedKey authorization of a request with the given content length and date
+func (a *azureMonitorIndexer) signature(contentLength int, date string) string {
+	stringToSign := fmt.Sprintf("%s\n%d\n%s\nx-ms-date:%s\n%s", http.MethodPost, contentLength, "application/json", date, azureMonitorResource)
+	mac := hmac.New(sha256.New, a.sharedKey)
+	mac.Write([]byte(stringToSign))
+	return fmt.Sprintf("SharedKey %s:%s", a.workspaceID, base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+func (a *azureMonitorIndexer) post(payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, a.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	// The date is part of the signature, so it's computed on every attempt
+	date := time.Now().UTC().Format(http.TimeFormat)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Log-Type", a.logType)
+	req.Header.Set("x-ms-date", date)
+	req.Header.Set("time-generated-field", "timestamp")
+	req.Header.Set("Authorization", a.signature(len(payload), date))
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return azureMonitorRetryableError{error: err}
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusInternalServerError,
+		resp.StatusCode == http.StatusServiceUnavailable:
+		retryable := azureMonitorRetryableError{error: fmt.Errorf("data collector API returned %s: %s", resp.Status, body)}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retryable.retryAfter = time.Duration(seconds) * time.Second
+		}
+		return retryable
+	default:
+		return fmt.Errorf("data collector API returned %s: %s", resp.Status, body)
+	}
+}
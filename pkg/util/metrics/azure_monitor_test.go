@@ -0,0 +1,34 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import "testing"
+
+func TestAzureMonitorSignature(t *testing.T) {
+	indexer := &azureMonitorIndexer{workspaceID: "workspace", sharedKey: []byte("kube-burner-shared-key")}
+	date := "Mon, 02 Jan 2023 15:04:05 GMT"
+	tests := []struct {
+		contentLength int
+		want          string
+	}{
+		{1024, "SharedKey workspace:UA7LiqBGIZlQXzn9m5ggjmNFhKPGa6ugC138ZiQeULg="},
+		{0, "SharedKey workspace:vfdMYGQCnkb+JWFXPx2+tq4wPixNcWust+kuW9JLGyI="},
+	}
+	for _, tt := range tests {
+		if got := indexer.signature(tt.contentLength, date); got != tt.want {
+			t.Errorf("signature(%d) = %s, want %s", tt.contentLength, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultBulkSize       = 1000
+	defaultBulkRetries    = 3
+	defaultSpillDirectory = "undelivered-metrics"
+	bulkRetryInterval     = 2 * time.Second
+)
+
+// bulkIndexer implements indexers.Indexer splitting the documents sent to Elasticsearch or OpenSearch in batches, which are
+// retried with exponential backoff. Batches failing after all the retries are written to the spill directory, in the format
+// of the local indexer, so they can be indexed later. It embeds the interface only to satisfy its unexported method
+type bulkIndexer struct {
+	indexers.Indexer
+	indexer        indexers.Indexer
+	bulkSize       int
+	retries        int
+	retryInterval  time.Duration
+	spillDirectory string
+	// lock protects the stats and the spill files, as documents of different metrics can be indexed concurrently
+	lock  sync.Mutex
+	stats bulkStats
+}
+
+// bulkStats counts the batches and documents of the bulk requests
+type bulkStats struct {
+	batches          int
+	failedBatches    int
+	documents        int
+	spilledDocuments int
+	retries          int
+}
+
+func newBulkIndexer(indexerConfig config.IndexerConfig) (*bulkIndexer, error) {
+	indexer, err := newSearchIndexer(indexerConfig)
+	if err != nil {
+		return nil, err
+	}
+	b := &bulkIndexer{
+		indexer:        indexer,
+		bulkSize:       indexerConfig.BulkSize,
+		retries:        indexerConfig.BulkRetries,
+		retryInterval:  bulkRetryInterval,
+		spillDirectory: indexerConfig.SpillDirectory,
+	}
+	if b.bulkSize <= 0 {
+		b.bulkSize = defaultBulkSize
+	}
+	if b.retries <= 0 {
+		b.retries = defaultBulkRetries
+	}
+	if b.spillDirectory == "" {
+		b.spillDirectory = defaultSpillDirectory
+	}
+	return b, nil
+}
+
+// Index sends the documents in batches of bulkSize, a failing batch doesn't prevent the next ones from being sent
+func (b *bulkIndexer) Index(documents []interface{}, opts indexers.IndexingOpts) (string, error) {
+	var failed, spilled int
+	for start := 0; start < len(documents); start += b.bulkSize {
+		end := start + b.bulkSize
+		if end > len(documents) {
+			end = len(documents)
+		}
+		batch := documents[start:end]
+		undelivered, err := b.send(batch, opts)
+		b.lock.Lock()
+		b.stats.batches++
+		b.stats.documents += len(batch)
+		if err != nil {
+			b.stats.failedBatches++
+		}
+		b.lock.Unlock()
+		if err == nil {
+			continue
+		}
+		failed++
+		log.Errorf("Error indexing documents %d-%d of %s after %d retries: %s", start, end-1, opts.MetricName, b.retries, err)
+		if err := b.spill(undelivered, opts.MetricName); err != nil {
+			log.Error(err.Error())
+			continue
+		}
+		spilled += len(undelivered)
+	}
+	if failed > 0 {
+		return "", fmt.Errorf("%d/%d batches of %s failed, %d documents written to %s", failed, (len(documents)+b.bulkSize-1)/b.bulkSize, opts.MetricName, spilled, b.spillDirectory)
+	}
+	return fmt.Sprintf("Indexed %d documents of %s", len(documents), opts.MetricName), nil
+}
+
+// send sends a batch, retrying the documents that failed with exponential backoff, and returns the ones still undelivered
+// after all the retries. Documents have a deterministic ID, so retrying a whole batch doesn't duplicate them
+func (b *bulkIndexer) send(batch []interface{}, opts indexers.IndexingOpts) ([]interface{}, error) {
+	var err error
+	interval := b.retryInterval
+	for attempt := 0; attempt <= b.retries; attempt++ {
+		if attempt > 0 {
+			log.Warnf("Retrying bulk request in %v, attempt %d/%d: %s", interval, attempt, b.retries, err)
+			b.lock.Lock()
+			b.stats.retries++
+			b.lock.Unlock()
+			time.Sleep(interval)
+			interval *= 2
+		}
+		if _, err = b.indexer.Index(batch, opts); err == nil {
+			return nil, nil
+		}
+		if failed, ok := err.(*failedDocumentsError); ok {
+			batch = failed.documents
+		}
+	}
+	return batch, err
+}
+
+// spill appends the undelivered documents to the metric file of the spill directory
+func (b *bulkIndexer) spill(batch []interface{}, metricName string) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if err := os.MkdirAll(b.spillDirectory, 0744); err != nil {
+		return fmt.Errorf("error creating spill directory %s: %s", b.spillDirectory, err)
+	}
+	var documents []interface{}
+	spillFile := path.Join(b.spillDirectory, metricName+".json")
+	if content, err := os.ReadFile(spillFile); err == nil {
+		if err := json.Unmarshal(content, &documents); err != nil {
+			return fmt.Errorf("error decoding spill file %s: %s", spillFile, err)
+		}
+	}
+	documents = append(documents, batch...)
+	content, err := json.Marshal(documents)
+	if err != nil {
+		return fmt.Errorf("JSON encoding error: %s", err)
+	}
+	if err := os.WriteFile(spillFile, content, 0644); err != nil {
+		return fmt.Errorf("error writing spill file %s: %s", spillFile, err)
+	}
+	b.stats.spilledDocuments += len(batch)
+	log.Warnf("%d undelivered documents written to %s", len(batch), spillFile)
+	return nil
+}
+
+// LogIndexingSummary logs the batches sent by the Elasticsearch and OpenSearch indexers, along with the failed ones
+func LogIndexingSummary(indexer *indexers.Indexer) {
+	if indexer == nil {
+		return
+	}
+	switch i := (*indexer).(type) {
+	case *bulkIndexer:
+		i.lock.Lock()
+		s := i.stats
+		i.lock.Unlock()
+		if s.failedBatches > 0 {
+			log.Warnf("Indexing summary: %d documents in %d batches, %d retries, %d batches failed, %d documents written to %s, they can be indexed with the merge subcommand",
+				s.documents, s.batches, s.retries, s.failedBatches, s.spilledDocuments, i.spillDirectory)
+		} else {
+			log.Infof("Indexing summary: %d documents in %d batches, %d retries", s.documents, s.batches, s.retries)
+		}
+	case *multiIndexer:
+		for _, indexer := range i.indexers {
+			LogIndexingSummary(indexer)
+		}
+	}
+}
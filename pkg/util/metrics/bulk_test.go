@@ -0,0 +1,131 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+)
+
+// bulkServer answers the bulk requests with item-level errors: the "throttled" document gets a 429 on its first attempt,
+// the "broken" document always gets a 500, the rest are indexed
+type bulkServer struct {
+	lock     sync.Mutex
+	attempts map[string]int
+}
+
+func (s *bulkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasSuffix(r.URL.Path, "/_bulk") {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+		return
+	}
+	var items []map[string]interface{}
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var action map[string]map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &action); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		id, _ := action["index"]["_id"].(string)
+		scanner.Scan()
+		var document map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &document); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		name := document["name"].(string)
+		s.lock.Lock()
+		s.attempts[name]++
+		attempt := s.attempts[name]
+		s.lock.Unlock()
+		item := map[string]interface{}{"_id": id, "status": 201, "result": "created"}
+		switch {
+		case name == "throttled" && attempt == 1:
+			item = map[string]interface{}{"_id": id, "status": 429, "error": map[string]string{"type": "es_rejected_execution_exception", "reason": "rejected"}}
+		case name == "broken":
+			item = map[string]interface{}{"_id": id, "status": 500, "error": map[string]string{"type": "internal_server_error", "reason": "broken"}}
+		}
+		items = append(items, map[string]interface{}{"index": item})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"errors": true, "items": items})
+}
+
+func TestBulkIndexerRetriesAndSpillsFailedItems(t *testing.T) {
+	for _, indexerType := range []indexers.IndexerType{indexers.ElasticIndexer, indexers.OpenSearchIndexer} {
+		t.Run(string(indexerType), func(t *testing.T) {
+			server := &bulkServer{attempts: make(map[string]int)}
+			ts := httptest.NewServer(server)
+			defer ts.Close()
+			spillDirectory := t.TempDir()
+			indexerConfig := config.IndexerConfig{
+				IndexerConfig: indexers.IndexerConfig{
+					Type:    indexerType,
+					Servers: []string{ts.URL},
+					Index:   "kube-burner",
+				},
+				BulkRetries:    2,
+				SpillDirectory: spillDirectory,
+			}
+			indexer, err := newBulkIndexer(indexerConfig)
+			if err != nil {
+				t.Fatalf("error creating indexer: %s", err)
+			}
+			indexer.retryInterval = time.Millisecond
+			documents := []interface{}{
+				map[string]interface{}{"name": "indexed"},
+				map[string]interface{}{"name": "throttled"},
+				map[string]interface{}{"name": "broken"},
+			}
+			if _, err := indexer.Index(documents, indexers.IndexingOpts{MetricName: "test"}); err == nil {
+				t.Fatal("expected an error indexing the broken document")
+			}
+			expectedAttempts := map[string]int{"indexed": 1, "throttled": 2, "broken": 3}
+			for name, expected := range expectedAttempts {
+				if server.attempts[name] != expected {
+					t.Errorf("document %s sent %d times, expected %d", name, server.attempts[name], expected)
+				}
+			}
+			content, err := os.ReadFile(path.Join(spillDirectory, "test.json"))
+			if err != nil {
+				t.Fatalf("error reading spill file: %s", err)
+			}
+			var spilled []map[string]interface{}
+			if err := json.Unmarshal(content, &spilled); err != nil {
+				t.Fatalf("error decoding spill file: %s", err)
+			}
+			if len(spilled) != 1 || spilled[0]["name"] != "broken" {
+				t.Errorf("expected only the broken document to be spilled, got %v", spilled)
+			}
+			if indexer.stats.spilledDocuments != 1 || indexer.stats.retries != 2 {
+				t.Errorf("unexpected stats: %+v", indexer.stats)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+)
+
+// gzipLocalIndexer implements indexers.Indexer writing gzip compressed files, it embeds the interface only to satisfy its unexported method
+type gzipLocalIndexer struct {
+	indexers.Indexer
+	metricsDirectory string
+}
+
+func newGzipLocalIndexer(indexerConfig indexers.IndexerConfig) (*gzipLocalIndexer, error) {
+	if indexerConfig.MetricsDirectory == "" {
+		return nil, fmt.Errorf("directory name not specified")
+	}
+	if err := os.MkdirAll(indexerConfig.MetricsDirectory, 0744); err != nil {
+		return nil, fmt.Errorf("error creating metrics directory %s: %s", indexerConfig.MetricsDirectory, err)
+	}
+	return &gzipLocalIndexer{metricsDirectory: indexerConfig.MetricsDirectory}, nil
+}
+
+// Index writes the documents into the <metricName>.json.gz file
+func (l *gzipLocalIndexer) Index(documents []interface{}, opts indexers.IndexingOpts) (string, error) {
+	if opts.MetricName == "" {
+		return "", fmt.Errorf("MetricName shouldn't be empty")
+	}
+	filename := path.Join(l.metricsDirectory, opts.MetricName+".json.gz")
+	f, err := os.Create(filename)
+	if err != nil {
+		return "", fmt.Errorf("Error creating metrics file %s: %s", filename, err)
+	}
+	defer f.Close()
+	gzipWriter := gzip.NewWriter(f)
+	if err := json.NewEncoder(gzipWriter).Encode(documents); err != nil {
+		return "", fmt.Errorf("JSON encoding error: %s", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return "", fmt.Errorf("Error compressing metrics file %s: %s", filename, err)
+	}
+	return fmt.Sprintf("File %s created with %d documents", filename, len(documents)), nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloud-bulldozer/kube-burner/pkg/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// LoadMetricsDirectories reads the documents of the metrics files written by the local indexer in the given directories, which can be glob patterns.
+// Documents are grouped by metric name, taken from the file name, so the same metric found in several directories is merged.
+func LoadMetricsDirectories(directories []string) (map[string][]interface{}, error) {
+	docsToIndex := make(map[string][]interface{})
+	for _, pattern := range directories {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return docsToIndex, fmt.Errorf("invalid metrics directory pattern %s: %s", pattern, err)
+		}
+		if len(matches) == 0 {
+			return docsToIndex, fmt.Errorf("metrics directory %s not found", pattern)
+		}
+		for _, dir := range matches {
+			if err := loadMetricsDirectory(dir, docsToIndex); err != nil {
+				return docsToIndex, err
+			}
+		}
+	}
+	return docsToIndex, nil
+}
+
+func loadMetricsDirectory(dir string, docsToIndex map[string][]interface{}) error {
+	log.Infof("Loading metrics from %s", dir)
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		var metricName string
+		switch {
+		case strings.HasSuffix(d.Name(), ".json.gz"):
+			metricName = strings.TrimSuffix(d.Name(), ".json.gz")
+		case strings.HasSuffix(d.Name(), ".json"):
+			metricName = strings.TrimSuffix(d.Name(), ".json")
+		default:
+			log.Debugf("Skipping %s, not a metrics file", path)
+			return nil
+		}
+		var docs []interface{}
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("error opening metrics file %s: %s", path, err)
+		}
+		defer f.Close()
+		// Files written by the local indexer with gzip enabled are decompressed transparently
+		r, err := util.MaybeGunzip(f)
+		if err != nil {
+			return fmt.Errorf("error reading metrics file %s: %s", path, err)
+		}
+		if err := json.NewDecoder(r).Decode(&docs); err != nil {
+			return fmt.Errorf("error decoding metrics file %s: %s", path, err)
+		}
+		log.Debugf("Loaded %d documents of metric %s from %s", len(docs), metricName, path)
+		docsToIndex[metricName] = append(docsToIndex[metricName], docs...)
+		return nil
+	})
+}
+
+// DedupeDocuments removes the documents of each metric having the same values in all the given keys, keeping the first one.
+// Documents without any of the keys are always kept.
+func DedupeDocuments(docsToIndex map[string][]interface{}, keys []string) int {
+	var removed int
+	for metricName, docs := range docsToIndex {
+		seen := make(map[string]bool)
+		var unique []interface{}
+		for _, doc := range docs {
+			docKey, ok := documentKey(doc, keys)
+			if ok {
+				if seen[docKey] {
+					removed++
+					continue
+				}
+				seen[docKey] = true
+			}
+			unique = append(unique, doc)
+		}
+		docsToIndex[metricName] = unique
+	}
+	return removed
+}
+
+// documentKey builds the dedupe key of a document from the JSON encoded values of the given keys
+func documentKey(doc interface{}, keys []string) (string, bool) {
+	fields, ok := doc.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	values := make([]string, len(keys))
+	var found bool
+	for i, key := range keys {
+		value, exists := fields[key]
+		if !exists {
+			continue
+		}
+		found = true
+		v, _ := json.Marshal(value)
+		values[i] = string(v)
+	}
+	return strings.Join(values, "\x00"), found
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDedupeDocuments(t *testing.T) {
+	doc := func(fields ...interface{}) map[string]interface{} {
+		d := make(map[string]interface{})
+		for i := 0; i < len(fields); i += 2 {
+			d[fields[i].(string)] = fields[i+1]
+		}
+		return d
+	}
+	tests := []struct {
+		name        string
+		docs        map[string][]interface{}
+		keys        []string
+		want        map[string][]interface{}
+		wantRemoved int
+	}{
+		{
+			name: "duplicates are removed keeping the first one",
+			docs: map[string][]interface{}{
+				"podLatency": {doc("uuid", "a", "podName", "p1", "v", 1.0), doc("uuid", "a", "podName", "p1", "v", 2.0), doc("uuid", "a", "podName", "p2", "v", 3.0)},
+			},
+			keys: []string{"uuid", "podName"},
+			want: map[string][]interface{}{
+				"podLatency": {doc("uuid", "a", "podName", "p1", "v", 1.0), doc("uuid", "a", "podName", "p2", "v", 3.0)},
+			},
+			wantRemoved: 1,
+		},
+		{
+			name: "metrics are deduplicated independently",
+			docs: map[string][]interface{}{
+				"cpu":    {doc("timestamp", "t1"), doc("timestamp", "t1")},
+				"memory": {doc("timestamp", "t1")},
+			},
+			keys: []string{"timestamp"},
+			want: map[string][]interface{}{
+				"cpu":    {doc("timestamp", "t1")},
+				"memory": {doc("timestamp", "t1")},
+			},
+			wantRemoved: 1,
+		},
+		{
+			name: "documents without any of the keys are kept",
+			docs: map[string][]interface{}{
+				"cpu": {doc("value", 1.0), doc("value", 1.0), "raw", "raw"},
+			},
+			keys: []string{"timestamp"},
+			want: map[string][]interface{}{
+				"cpu": {doc("value", 1.0), doc("value", 1.0), "raw", "raw"},
+			},
+		},
+		{
+			name: "values are compared by type",
+			docs: map[string][]interface{}{
+				"cpu": {doc("value", 1.0), doc("value", "1"), doc("labels", map[string]interface{}{"a": "b"}), doc("labels", map[string]interface{}{"a": "b"})},
+			},
+			keys: []string{"value", "labels"},
+			want: map[string][]interface{}{
+				"cpu": {doc("value", 1.0), doc("value", "1"), doc("labels", map[string]interface{}{"a": "b"})},
+			},
+			wantRemoved: 1,
+		},
+	}
+	for _, tt := range tests {
+		removed := DedupeDocuments(tt.docs, tt.keys)
+		if removed != tt.wantRemoved {
+			t.Errorf("%s: DedupeDocuments() removed %d documents, want %d", tt.name, removed, tt.wantRemoved)
+		}
+		if !reflect.DeepEqual(tt.docs, tt.want) {
+			t.Errorf("%s: DedupeDocuments() = %v, want %v", tt.name, tt.docs, tt.want)
+		}
+	}
+}
@@ -34,7 +34,7 @@ func ProcessMetricsScraperConfig(metricsScraperConfig ScraperConfig) Scraper {
 	if metricsScraperConfig.ConfigSpec.GlobalConfig.IndexerConfig.Type != "" {
 		indexerConfig := metricsScraperConfig.ConfigSpec.GlobalConfig.IndexerConfig
 		log.Infof("📁 Creating indexer: %s", indexerConfig.Type)
-		indexer, err = indexers.NewIndexer(indexerConfig)
+		indexer, err = NewIndexers(metricsScraperConfig.ConfigSpec.GlobalConfig.IndexerConfigs())
 		if err != nil {
 			log.Fatalf("%v indexer: %v", indexerConfig.Type, err.Error())
 		}
@@ -69,7 +69,26 @@ func ProcessMetricsScraperConfig(metricsScraperConfig ScraperConfig) Scraper {
 			Password:      metricsScraperConfig.Password,
 			Token:         metricsEndpoint.Token,
 			SkipTLSVerify: metricsScraperConfig.SkipTLSVerify,
+			CACert:        metricsScraperConfig.CACert,
 		}
+		// Credentials of each endpoint take precedence over the ones given by the CLI flags
+		if metricsEndpoint.Username != "" || metricsEndpoint.Password != "" {
+			auth.Username, auth.Password = metricsEndpoint.Username, metricsEndpoint.Password
+		}
+		if metricsEndpoint.CACert != "" {
+			auth.CACert, auth.SkipTLSVerify = metricsEndpoint.CACert, false
+		}
+		if metricsEndpoint.SkipTLSVerify != nil {
+			auth.SkipTLSVerify = *metricsEndpoint.SkipTLSVerify
+		}
+		profileSource, alertProfileSource := "endpoint", "endpoint"
+		if metricsEndpoint.Profile == "" {
+			metricsEndpoint.Profile, profileSource = metricsScraperConfig.MetricsProfile, "global"
+		}
+		if metricsEndpoint.AlertProfile == "" {
+			metricsEndpoint.AlertProfile, alertProfileSource = metricsScraperConfig.AlertProfile, "global"
+		}
+		log.Infof("Endpoint %s: using %s metrics profile %q and %s alert profile %q", metricsEndpoint.Endpoint, profileSource, metricsEndpoint.Profile, alertProfileSource, metricsEndpoint.AlertProfile)
 		p, err := prometheus.NewPrometheusClient(metricsScraperConfig.ConfigSpec, metricsEndpoint.Endpoint, auth, metricsScraperConfig.PrometheusStep, metadata, false)
 		if err != nil {
 			log.Fatal(err)
@@ -80,6 +99,9 @@ func ProcessMetricsScraperConfig(metricsScraperConfig ScraperConfig) Scraper {
 				log.Fatal(err)
 			}
 		}
+		if err = p.ReadJobProfiles(); err != nil {
+			log.Fatal(err)
+		}
 		if metricsEndpoint.AlertProfile != "" {
 			if alertM, err = alerting.NewAlertManager(metricsEndpoint.AlertProfile, metricsScraperConfig.ConfigSpec.GlobalConfig.UUID, indexer, p, false); err != nil {
 				log.Fatalf("Error creating alert manager: %s", err)
@@ -0,0 +1,69 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	log "github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// multiIndexer implements indexers.Indexer sending the documents to several indexers,
+// it embeds the interface only to satisfy its unexported method
+type multiIndexer struct {
+	indexers.Indexer
+	indexerTypes []indexers.IndexerType
+	indexers     []*indexers.Indexer
+}
+
+// NewIndexers creates an indexer for each of the given configurations, when there's more than one,
+// the returned indexer sends the documents to all of them
+func NewIndexers(indexerConfigs []config.IndexerConfig) (*indexers.Indexer, error) {
+	if len(indexerConfigs) == 1 {
+		return NewIndexer(indexerConfigs[0])
+	}
+	multi := &multiIndexer{}
+	for _, indexerConfig := range indexerConfigs {
+		log.Infof("📁 Creating indexer: %s", indexerConfig.Type)
+		indexer, err := NewIndexer(indexerConfig)
+		if err != nil {
+			return nil, fmt.Errorf("%v indexer: %v", indexerConfig.Type, err)
+		}
+		multi.indexerTypes = append(multi.indexerTypes, indexerConfig.Type)
+		multi.indexers = append(multi.indexers, indexer)
+	}
+	var indexer indexers.Indexer = multi
+	return &indexer, nil
+}
+
+// Index sends the documents to every indexer, a failing indexer doesn't prevent indexing to the rest of them
+func (m *multiIndexer) Index(documents []interface{}, opts indexers.IndexingOpts) (string, error) {
+	var responses []string
+	var errs []error
+	for i, indexer := range m.indexers {
+		resp, err := (*indexer).Index(documents, opts)
+		if err != nil {
+			log.Errorf("%s indexer: %s", m.indexerTypes[i], err)
+			errs = append(errs, fmt.Errorf("%s indexer: %s", m.indexerTypes[i], err))
+			continue
+		}
+		responses = append(responses, fmt.Sprintf("%s indexer: %s", m.indexerTypes[i], resp))
+	}
+	return strings.Join(responses, ", "), utilerrors.NewAggregate(errs)
+}
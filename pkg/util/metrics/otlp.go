@@ -0,0 +1,243 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/util"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// OTLPIndexer ships documents as log records to an OpenTelemetry collector using OTLP/HTTP
+	OTLPIndexer       indexers.IndexerType = "otlp"
+	otlpLogsPath                           = "/v1/logs"
+	otlpBatchSize                          = 500
+	otlpMaxRetries                         = 5
+	otlpRetryInterval                      = 2 * time.Second
+)
+
+// otlpIndexer implements indexers.Indexer, it embeds the interface only to satisfy its unexported method
+type otlpIndexer struct {
+	indexers.Indexer
+	url        string
+	headers    map[string]string
+	batchSize  int
+	maxRetries int
+	client     *http.Client
+}
+
+// OTLP/HTTP JSON encoding of the logs signal
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano string         `json:"timeUnixNano,omitempty"`
+	Body         otlpAnyValue   `json:"body"`
+	Attributes   []otlpKeyValue `json:"attributes"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+// otlpRetryableError is returned when the collector fails with a transient error
+type otlpRetryableError struct {
+	error
+}
+
+func newOTLPIndexer(indexerConfig config.IndexerConfig) (*otlpIndexer, error) {
+	otlpConfig := indexerConfig.OTLP
+	if otlpConfig.Endpoint == "" {
+		return nil, fmt.Errorf("otlp endpoint not specified")
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: indexerConfig.InsecureSkipVerify}
+	if otlpConfig.CACert != "" {
+		caCert, err := os.ReadFile(otlpConfig.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("error reading otlp CA certificate: %s", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("invalid otlp CA certificate %s", otlpConfig.CACert)
+		}
+	}
+	if otlpConfig.ClientCert != "" || otlpConfig.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(otlpConfig.ClientCert, otlpConfig.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error loading otlp client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	indexer := &otlpIndexer{
+		url:        strings.TrimSuffix(otlpConfig.Endpoint, "/") + otlpLogsPath,
+		headers:    otlpConfig.Headers,
+		batchSize:  otlpConfig.BatchSize,
+		maxRetries: otlpConfig.MaxRetries,
+		client: &http.Client{
+			Timeout:   time.Minute,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: util.ProxyFunc()},
+		},
+	}
+	if indexer.batchSize <= 0 {
+		indexer.batchSize = otlpBatchSize
+	}
+	if indexer.maxRetries <= 0 {
+		indexer.maxRetries = otlpMaxRetries
+	}
+	return indexer, nil
+}
+
+// Index sends the documents in batches, each document is mapped to a log record whose body is the JSON encoded document
+func (o *otlpIndexer) Index(documents []interface{}, opts indexers.IndexingOpts) (string, error) {
+	for start := 0; start < len(documents); start += o.batchSize {
+		end := start + o.batchSize
+		if end > len(documents) {
+			end = len(documents)
+		}
+		payload, err := o.logsRequest(documents[start:end], opts.MetricName)
+		if err != nil {
+			return "", err
+		}
+		if err := o.send(payload); err != nil {
+			return "", fmt.Errorf("error sending documents %d-%d to %s: %s", start, end-1, o.url, err)
+		}
+	}
+	return fmt.Sprintf("Indexed %d documents into %s", len(documents), o.url), nil
+}
+
+func (o *otlpIndexer) logsRequest(documents []interface{}, metricName string) ([]byte, error) {
+	records := make([]otlpLogRecord, 0, len(documents))
+	for _, document := range documents {
+		body, err := json.Marshal(document)
+		if err != nil {
+			return nil, fmt.Errorf("JSON encoding error: %s", err)
+		}
+		record := otlpLogRecord{
+			Body: otlpAnyValue{StringValue: string(body)},
+		}
+		name := metricName
+		// Documents are usually structs, decoding their JSON encoding gives access to their fields by their JSON names
+		var fields map[string]interface{}
+		if err := json.Unmarshal(body, &fields); err == nil {
+			if timestamp, ok := fields["timestamp"].(string); ok {
+				if t, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+					record.TimeUnixNano = strconv.FormatInt(t.UnixNano(), 10)
+				}
+			}
+			if docMetricName, ok := fields["metricName"].(string); ok && name == "" {
+				name = docMetricName
+			}
+			if uuid, ok := fields["uuid"].(string); ok {
+				record.Attributes = append(record.Attributes, otlpKeyValue{Key: "uuid", Value: otlpAnyValue{StringValue: uuid}})
+			}
+		}
+		record.Attributes = append(record.Attributes, otlpKeyValue{Key: "metricName", Value: otlpAnyValue{StringValue: name}})
+		records = append(records, record)
+	}
+	request := otlpLogsRequest{
+		ResourceLogs: []otlpResourceLogs{{
+			Resource: otlpResource{
+				Attributes: []otlpKeyValue{{Key: "service.name", Value: otlpAnyValue{StringValue: "kube-burner"}}},
+			},
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      otlpScope{Name: "kube-burner"},
+				LogRecords: records,
+			}},
+		}},
+	}
+	return json.Marshal(request)
+}
+
+// send posts the payload, retrying with exponential backoff on connection errors and transient HTTP status codes
+func (o *otlpIndexer) send(payload []byte) error {
+	var err error
+	interval := otlpRetryInterval
+	for attempt := 0; attempt <= o.maxRetries; attempt++ {
+		if attempt > 0 {
+			log.Warnf("Retrying OTLP request in %v, attempt %d/%d: %s", interval, attempt, o.maxRetries, err)
+			time.Sleep(interval)
+			interval *= 2
+		}
+		err = o.post(payload)
+		if _, retryable := err.(otlpRetryableError); !retryable {
+			return err
+		}
+	}
+	return err
+}
+
+func (o *otlpIndexer) post(payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, o.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range o.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return otlpRetryableError{err}
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusBadGateway,
+		resp.StatusCode == http.StatusServiceUnavailable, resp.StatusCode == http.StatusGatewayTimeout:
+		return otlpRetryableError{fmt.Errorf("collector returned %s: %s", resp.Status, body)}
+	default:
+		return fmt.Errorf("collector returned %s: %s", resp.Status, body)
+	}
+}
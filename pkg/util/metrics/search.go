@@ -0,0 +1,286 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/util"
+	elasticsearch "github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esutil"
+	opensearch "github.com/opensearch-project/opensearch-go"
+	"github.com/opensearch-project/opensearch-go/opensearchutil"
+)
+
+const (
+	searchFlushBytes = 5e+6
+	searchTimeout    = 10 * time.Minute
+)
+
+// elasticIndexer and openSearchIndexer index documents like the go-commons ones, with a transport honoring the configured
+// proxy. They embed the interface only to satisfy its unexported method
+type elasticIndexer struct {
+	indexers.Indexer
+	client *elasticsearch.Client
+	index  string
+}
+
+type openSearchIndexer struct {
+	indexers.Indexer
+	client *opensearch.Client
+	index  string
+}
+
+// indexerStats counts the results of the bulk requests and keeps the documents that failed to be indexed
+type indexerStats struct {
+	lock   sync.Mutex
+	stats  map[string]int
+	failed []interface{}
+	// lastError is the last item or request error reported by the bulk indexer
+	lastError error
+	// requestFailed is set when a whole bulk request failed, in that case the bulk indexer doesn't report its items
+	requestFailed bool
+}
+
+// failedDocumentsError is returned when some documents couldn't be indexed, so they can be retried
+type failedDocumentsError struct {
+	documents []interface{}
+	err       error
+}
+
+func (e *failedDocumentsError) Error() string {
+	return fmt.Sprintf("%d documents failed to be indexed: %s", len(e.documents), e.err)
+}
+
+// newSearchIndexer creates the Elasticsearch or OpenSearch indexer, checking the cluster health and creating the index when missing
+func newSearchIndexer(indexerConfig config.IndexerConfig) (indexers.Indexer, error) {
+	if indexerConfig.Index == "" {
+		return nil, fmt.Errorf("index name not specified")
+	}
+	index := strings.ToLower(indexerConfig.Index)
+	transport, err := util.NewHTTPTransport(indexerConfig.InsecureSkipVerify, indexerConfig.CACert)
+	if err != nil {
+		return nil, err
+	}
+	if indexerConfig.Type == indexers.OpenSearchIndexer {
+		client, err := opensearch.NewClient(opensearch.Config{Addresses: indexerConfig.Servers, Transport: transport})
+		if err != nil {
+			return nil, fmt.Errorf("error creating the OpenSearch client: %s", err)
+		}
+		r, err := client.Cluster.Health()
+		if err != nil {
+			return nil, fmt.Errorf("OpenSearch health check failed: %s", err)
+		}
+		if r.StatusCode != 200 {
+			return nil, fmt.Errorf("unexpected OpenSearch status code: %d", r.StatusCode)
+		}
+		r, _ = client.Indices.Exists([]string{index})
+		if r.IsError() {
+			r, _ = client.Indices.Create(index)
+			if r.IsError() {
+				return nil, fmt.Errorf("error creating index %s on OpenSearch: %s", index, r.String())
+			}
+		}
+		return &openSearchIndexer{client: client, index: index}, nil
+	}
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: indexerConfig.Servers, Transport: transport})
+	if err != nil {
+		return nil, fmt.Errorf("error creating the ES client: %s", err)
+	}
+	r, err := client.Cluster.Health()
+	if err != nil {
+		return nil, fmt.Errorf("ES health check failed: %s", err)
+	}
+	if r.StatusCode != 200 {
+		return nil, fmt.Errorf("unexpected ES status code: %d", r.StatusCode)
+	}
+	r, _ = client.Indices.Exists([]string{index})
+	if r.IsError() {
+		r, _ = client.Indices.Create(index)
+		if r.IsError() {
+			return nil, fmt.Errorf("error creating index %s on ES: %s", index, r.String())
+		}
+	}
+	return &elasticIndexer{client: client, index: index}, nil
+}
+
+// Index uses the ES bulk indexer to index the documents
+func (e *elasticIndexer) Index(documents []interface{}, opts indexers.IndexingOpts) (string, error) {
+	stats := indexerStats{stats: make(map[string]int)}
+	bi, err := esutil.NewBulkIndexer(esutil.BulkIndexerConfig{
+		Client:     e.client,
+		Index:      e.index,
+		FlushBytes: searchFlushBytes,
+		NumWorkers: runtime.NumCPU(),
+		Timeout:    searchTimeout,
+		OnError: func(_ context.Context, err error) {
+			stats.requestError(err)
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error creating the indexer: %s", err)
+	}
+	start := time.Now().UTC()
+	for _, document := range documents {
+		// The callbacks run once the batch is flushed
+		document := document
+		j, id, err := encodeDocument(document)
+		if err != nil {
+			return "", err
+		}
+		err = bi.Add(context.Background(), esutil.BulkIndexerItem{
+			Action:     "index",
+			Body:       bytes.NewReader(j),
+			DocumentID: id,
+			OnSuccess: func(_ context.Context, _ esutil.BulkIndexerItem, r esutil.BulkIndexerResponseItem) {
+				stats.add(r.Result)
+			},
+			OnFailure: func(_ context.Context, _ esutil.BulkIndexerItem, r esutil.BulkIndexerResponseItem, err error) {
+				if err == nil {
+					err = fmt.Errorf("status %d: %s: %s", r.Status, r.Error.Type, r.Error.Reason)
+				}
+				stats.fail(document, err)
+			},
+		})
+		if err != nil {
+			return "", fmt.Errorf("unexpected ES indexing error: %s", err)
+		}
+	}
+	if err := bi.Close(context.Background()); err != nil {
+		return "", fmt.Errorf("unexpected ES error: %s", err)
+	}
+	if err := stats.err(documents, bi.Stats().NumFailed); err != nil {
+		return "", err
+	}
+	return stats.String(time.Since(start)), nil
+}
+
+// Index uses the OpenSearch bulk indexer to index the documents
+func (o *openSearchIndexer) Index(documents []interface{}, opts indexers.IndexingOpts) (string, error) {
+	stats := indexerStats{stats: make(map[string]int)}
+	bi, err := opensearchutil.NewBulkIndexer(opensearchutil.BulkIndexerConfig{
+		Client:     o.client,
+		Index:      o.index,
+		FlushBytes: searchFlushBytes,
+		NumWorkers: runtime.NumCPU(),
+		Timeout:    searchTimeout,
+		OnError: func(_ context.Context, err error) {
+			stats.requestError(err)
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error creating the indexer: %s", err)
+	}
+	start := time.Now().UTC()
+	for _, document := range documents {
+		// The callbacks run once the batch is flushed
+		document := document
+		j, id, err := encodeDocument(document)
+		if err != nil {
+			return "", err
+		}
+		err = bi.Add(context.Background(), opensearchutil.BulkIndexerItem{
+			Action:     "index",
+			Body:       bytes.NewReader(j),
+			DocumentID: id,
+			OnSuccess: func(_ context.Context, _ opensearchutil.BulkIndexerItem, r opensearchutil.BulkIndexerResponseItem) {
+				stats.add(r.Result)
+			},
+			OnFailure: func(_ context.Context, _ opensearchutil.BulkIndexerItem, r opensearchutil.BulkIndexerResponseItem, err error) {
+				if err == nil {
+					err = fmt.Errorf("status %d: %s: %s", r.Status, r.Error.Type, r.Error.Reason)
+				}
+				stats.fail(document, err)
+			},
+		})
+		if err != nil {
+			return "", fmt.Errorf("unexpected OpenSearch indexing error: %s", err)
+		}
+	}
+	if err := bi.Close(context.Background()); err != nil {
+		return "", fmt.Errorf("unexpected OpenSearch error: %s", err)
+	}
+	if err := stats.err(documents, bi.Stats().NumFailed); err != nil {
+		return "", err
+	}
+	return stats.String(time.Since(start)), nil
+}
+
+// encodeDocument returns the JSON document and its ID, the sha256 of the document, so indexing it again doesn't duplicate it
+func encodeDocument(document interface{}) ([]byte, string, error) {
+	j, err := json.Marshal(document)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot encode document %s: %s", document, err)
+	}
+	sum := sha256.Sum256(j)
+	return j, hex.EncodeToString(sum[:]), nil
+}
+
+func (s *indexerStats) add(result string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.stats[result]++
+}
+
+func (s *indexerStats) fail(document interface{}, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.stats["failed"]++
+	s.failed = append(s.failed, document)
+	s.lastError = err
+}
+
+func (s *indexerStats) requestError(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.requestFailed = true
+	s.lastError = err
+}
+
+// err returns the documents that failed to be indexed, all of them when a whole bulk request failed, as the bulk indexer
+// doesn't report which items it contained
+func (s *indexerStats) err(documents []interface{}, numFailed uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if numFailed == 0 && !s.requestFailed {
+		return nil
+	}
+	if s.lastError == nil {
+		s.lastError = fmt.Errorf("bulk indexer reported %d failed documents", numFailed)
+	}
+	if s.requestFailed {
+		return &failedDocumentsError{documents: documents, err: s.lastError}
+	}
+	return &failedDocumentsError{documents: s.failed, err: s.lastError}
+}
+
+func (s *indexerStats) String(duration time.Duration) string {
+	var statString string
+	for stat, val := range s.stats {
+		statString += fmt.Sprintf(" %s=%d", stat, val)
+	}
+	return fmt.Sprintf("Indexing finished in %v:%v", duration.Truncate(time.Millisecond), statString)
+}
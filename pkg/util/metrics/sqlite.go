@@ -0,0 +1,248 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	log "github.com/sirupsen/logrus"
+	// Pure go SQLite driver, registered as sqlite
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// SQLiteIndexer writes documents into a SQLite database file
+	SQLiteIndexer  indexers.IndexerType = "sqlite"
+	sqliteDatabase                      = "kube-burner.db"
+)
+
+var invalidIdentifierChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// sqliteIndexer implements indexers.Indexer, it embeds the interface only to satisfy its unexported method
+type sqliteIndexer struct {
+	indexers.Indexer
+	db       *sql.DB
+	requests chan sqliteRequest
+	// columns holds the known columns of each table
+	columns map[string]map[string]bool
+}
+
+type sqliteRequest struct {
+	documents []interface{}
+	opts      indexers.IndexingOpts
+	result    chan error
+}
+
+// NewIndexer creates a new indexer with the given configuration, supporting the indexers from go-commons, the SQLite one,
+// the gzip compressed local one and the OTLP one. Elasticsearch and OpenSearch documents are sent in batches
+func NewIndexer(indexerConfig config.IndexerConfig) (*indexers.Indexer, error) {
+	var indexer indexers.Indexer
+	var err error
+	switch {
+	case indexerConfig.Type == SQLiteIndexer:
+		indexer, err = newSQLiteIndexer(indexerConfig.IndexerConfig)
+	case indexerConfig.Type == OTLPIndexer:
+		indexer, err = newOTLPIndexer(indexerConfig)
+	case indexerConfig.Type == AzureMonitorIndexer:
+		indexer, err = newAzureMonitorIndexer(indexerConfig)
+	case indexerConfig.Type == indexers.LocalIndexer && indexerConfig.Gzip:
+		indexer, err = newGzipLocalIndexer(indexerConfig.IndexerConfig)
+	case indexerConfig.Type == indexers.ElasticIndexer || indexerConfig.Type == indexers.OpenSearchIndexer:
+		indexer, err = newBulkIndexer(indexerConfig)
+	default:
+		return indexers.NewIndexer(indexerConfig.IndexerConfig)
+	}
+	return &indexer, err
+}
+
+// newSQLiteIndexer opens the database file within the metrics directory and starts the writer goroutine
+func newSQLiteIndexer(indexerConfig indexers.IndexerConfig) (*sqliteIndexer, error) {
+	if err := os.MkdirAll(indexerConfig.MetricsDirectory, 0744); err != nil {
+		return nil, fmt.Errorf("error creating metrics directory %s: %s", indexerConfig.MetricsDirectory, err)
+	}
+	dbFile := path.Join(indexerConfig.MetricsDirectory, sqliteDatabase)
+	db, err := sql.Open("sqlite", dbFile)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database %s: %s", dbFile, err)
+	}
+	// SQLite supports a single writer
+	db.SetMaxOpenConns(1)
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("error opening database %s: %s", dbFile, err)
+	}
+	indexer := &sqliteIndexer{
+		db:       db,
+		requests: make(chan sqliteRequest),
+		columns:  make(map[string]map[string]bool),
+	}
+	go indexer.writer()
+	return indexer, nil
+}
+
+// Index sends the documents to the writer goroutine and waits for them to be written
+func (s *sqliteIndexer) Index(documents []interface{}, opts indexers.IndexingOpts) (string, error) {
+	if opts.MetricName == "" {
+		return "", fmt.Errorf("MetricName shouldn't be empty")
+	}
+	result := make(chan error)
+	s.requests <- sqliteRequest{documents: documents, opts: opts, result: result}
+	if err := <-result; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Indexed %d documents into table %s", len(documents), tableName(opts.MetricName)), nil
+}
+
+// writer is the only goroutine writing into the database
+func (s *sqliteIndexer) writer() {
+	for request := range s.requests {
+		request.result <- s.write(request.documents, tableName(request.opts.MetricName))
+	}
+}
+
+// write inserts the given documents into the table within a single transaction. Every top level field
+// of the documents is a column of the table, and nested fields are stored as JSON text
+func (s *sqliteIndexer) write(documents []interface{}, table string) error {
+	var rows []map[string]interface{}
+	for _, document := range documents {
+		row := make(map[string]interface{})
+		j, err := json.Marshal(document)
+		if err != nil {
+			return fmt.Errorf("error marshaling document: %s", err)
+		}
+		if err := json.Unmarshal(j, &row); err != nil {
+			return fmt.Errorf("error unmarshaling document: %s", err)
+		}
+		rows = append(rows, row)
+	}
+	if err := s.insert(rows, table); err != nil {
+		// The transaction was rolled back along with the tables and columns it added, so the known columns
+		// of the table are read again by the next write
+		delete(s.columns, table)
+		return err
+	}
+	return nil
+}
+
+// insert inserts the given rows into the table within a single transaction, creating the table and its missing columns
+func (s *sqliteIndexer) insert(rows []map[string]interface{}, table string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, row := range rows {
+		if err := s.syncColumns(tx, table, row); err != nil {
+			return fmt.Errorf("error creating table %s: %s", table, err)
+		}
+		var columns, placeholders []string
+		var values []interface{}
+		for _, column := range sortedKeys(row) {
+			columns = append(columns, quoteIdentifier(column))
+			placeholders = append(placeholders, "?")
+			values = append(values, sqliteValue(row[column]))
+		}
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quoteIdentifier(table), strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+		if _, err := tx.Exec(query, values...); err != nil {
+			return fmt.Errorf("error inserting into table %s: %s", table, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// syncColumns creates the table or adds the columns of the given row missing in the table
+func (s *sqliteIndexer) syncColumns(tx *sql.Tx, table string, row map[string]interface{}) error {
+	known, exists := s.columns[table]
+	if !exists {
+		known = make(map[string]bool)
+		if _, err := tx.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id INTEGER PRIMARY KEY AUTOINCREMENT)", quoteIdentifier(table))); err != nil {
+			return err
+		}
+		columns, err := tx.Query(fmt.Sprintf("SELECT name FROM pragma_table_info('%s')", table))
+		if err != nil {
+			return err
+		}
+		for columns.Next() {
+			var name string
+			if err := columns.Scan(&name); err != nil {
+				columns.Close()
+				return err
+			}
+			known[name] = true
+		}
+		columns.Close()
+		s.columns[table] = known
+	}
+	for _, column := range sortedKeys(row) {
+		if known[column] {
+			continue
+		}
+		log.Debugf("Adding column %s to table %s", column, table)
+		if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", quoteIdentifier(table), quoteIdentifier(column), sqliteType(row[column]))); err != nil {
+			return err
+		}
+		known[column] = true
+	}
+	return nil
+}
+
+// sqliteType returns the column type for the given JSON value
+func sqliteType(value interface{}) string {
+	switch value.(type) {
+	case float64:
+		return "REAL"
+	case bool:
+		return "INTEGER"
+	default:
+		return "TEXT"
+	}
+}
+
+// sqliteValue converts the given JSON value into a value supported by the driver
+func sqliteValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}, []interface{}:
+		j, _ := json.Marshal(v)
+		return string(j)
+	default:
+		return v
+	}
+}
+
+// tableName returns a valid table name for the given metric name
+func tableName(metricName string) string {
+	return invalidIdentifierChars.ReplaceAllString(metricName, "_")
+}
+
+func quoteIdentifier(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
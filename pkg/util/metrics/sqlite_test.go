@@ -0,0 +1,111 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/cloud-bulldozer/go-commons/indexers"
+)
+
+func newTestSQLiteIndexer(t *testing.T) *sqliteIndexer {
+	indexer, err := newSQLiteIndexer(indexers.IndexerConfig{Type: SQLiteIndexer, MetricsDirectory: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { indexer.db.Close() })
+	return indexer
+}
+
+func countRows(t *testing.T, s *sqliteIndexer, query string) int {
+	var count int
+	if err := s.db.QueryRow(query).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestSQLiteIndexerAddsColumns(t *testing.T) {
+	s := newTestSQLiteIndexer(t)
+	batches := [][]interface{}{
+		{map[string]interface{}{"name": "a", "value": 1}},
+		{map[string]interface{}{"name": "b", "labels": map[string]string{"pod": "p"}}, map[string]interface{}{"name": "c", "ready": true}},
+	}
+	for _, batch := range batches {
+		if _, err := s.Index(batch, indexers.IndexingOpts{MetricName: "podLatency-job"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := countRows(t, s, `SELECT COUNT(*) FROM podLatency_job`); got != 3 {
+		t.Errorf("rows = %d, want 3", got)
+	}
+	for _, column := range []string{"name", "value", "labels", "ready"} {
+		if got := countRows(t, s, fmt.Sprintf(`SELECT COUNT(*) FROM pragma_table_info('podLatency_job') WHERE name = '%s'`, column)); got != 1 {
+			t.Errorf("column %s not found", column)
+		}
+	}
+	if got := countRows(t, s, `SELECT COUNT(*) FROM podLatency_job WHERE labels = '{"pod":"p"}'`); got != 1 {
+		t.Errorf("nested field not stored as JSON text")
+	}
+}
+
+func TestSQLiteIndexerRecoversFromRolledBackColumns(t *testing.T) {
+	s := newTestSQLiteIndexer(t)
+	// The trigger makes the inserts of the "broken" documents fail, rolling back the columns added by their transaction
+	for _, statement := range []string{
+		`CREATE TABLE events (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)`,
+		`CREATE TRIGGER reject BEFORE INSERT ON events WHEN NEW.name = 'broken' BEGIN SELECT RAISE(ABORT, 'broken'); END`,
+	} {
+		if _, err := s.db.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.Index([]interface{}{map[string]interface{}{"name": "broken", "extra": 1}}, indexers.IndexingOpts{MetricName: "events"}); err == nil {
+		t.Fatal("expected the insert to fail")
+	}
+	if _, err := s.Index([]interface{}{map[string]interface{}{"name": "ok", "extra": 2}}, indexers.IndexingOpts{MetricName: "events"}); err != nil {
+		t.Fatalf("write after a rolled back one failed: %s", err)
+	}
+	if got := countRows(t, s, `SELECT COUNT(*) FROM events WHERE extra = 2`); got != 1 {
+		t.Errorf("rows = %d, want 1", got)
+	}
+}
+
+func TestSQLiteIndexerConcurrentWriters(t *testing.T) {
+	s := newTestSQLiteIndexer(t)
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Every writer adds its own column to the shared table
+			document := map[string]interface{}{"name": "doc", fmt.Sprintf("field%d", i): i}
+			if _, err := s.Index([]interface{}{document}, indexers.IndexingOpts{MetricName: "shared"}); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if got := countRows(t, s, `SELECT COUNT(*) FROM shared`); got != 20 {
+		t.Errorf("rows = %d, want 20", got)
+	}
+}
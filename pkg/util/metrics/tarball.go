@@ -26,15 +26,22 @@ import (
 	"path/filepath"
 
 	"github.com/cloud-bulldozer/go-commons/indexers"
+	"github.com/cloud-bulldozer/kube-burner/pkg/config"
+	"github.com/cloud-bulldozer/kube-burner/pkg/util"
 	log "github.com/sirupsen/logrus"
 )
 
-func CreateTarball(indexerConfig indexers.IndexerConfig, tarballName string) error {
+func CreateTarball(indexerConfig config.IndexerConfig, tarballName string) error {
 	tarball, err := os.Create(tarballName)
 	if err != nil {
 		return fmt.Errorf("Could not create tarball file: %v", err)
 	}
-	gzipWriter := gzip.NewWriter(tarball)
+	compressionLevel := gzip.DefaultCompression
+	// Metrics files are already compressed, the tarball keeps the gzip format but doesn't compress them again
+	if indexerConfig.Gzip {
+		compressionLevel = gzip.NoCompression
+	}
+	gzipWriter, _ := gzip.NewWriterLevel(tarball, compressionLevel)
 	tarWriter := tar.NewWriter(gzipWriter)
 	// defer is LIFO
 	defer tarball.Close()
@@ -67,7 +74,32 @@ func CreateTarball(indexerConfig indexers.IndexerConfig, tarballName string) err
 	return nil
 }
 
-func ImportTarball(tarball string, indexer *indexers.Indexer, metricsDir string) error {
+// DocumentTransform modifies a document of a metrics tarball before it's indexed
+type DocumentTransform func(document map[string]interface{})
+
+// RelabelDocuments returns a DocumentTransform overriding the uuid of the documents, when not empty,
+// and merging the given metadata into their metadata field
+func RelabelDocuments(uuid string, metadata map[string]string) DocumentTransform {
+	return func(document map[string]interface{}) {
+		if uuid != "" {
+			document["uuid"] = uuid
+		}
+		if len(metadata) == 0 {
+			return
+		}
+		documentMetadata, ok := document["metadata"].(map[string]interface{})
+		if !ok {
+			documentMetadata = make(map[string]interface{}, len(metadata))
+			document["metadata"] = documentMetadata
+		}
+		for k, v := range metadata {
+			documentMetadata[k] = v
+		}
+	}
+}
+
+// ImportTarball indexes the metrics of a tarball, the given transform, when not nil, is applied to every document before indexing it
+func ImportTarball(tarball string, indexer *indexers.Indexer, metricsDir string, transform DocumentTransform) error {
 	log.Infof("Importing tarball %v", tarball)
 	var rawData bytes.Buffer
 	tarballFile, err := os.Open(tarball)
@@ -87,12 +119,24 @@ func ImportTarball(tarball string, indexer *indexers.Indexer, metricsDir string)
 		if err == io.EOF {
 			break
 		}
-		_, err = io.Copy(&rawData, tr)
+		// Files written by the local indexer with gzip enabled are decompressed transparently
+		fileReader, err := util.MaybeGunzip(tr)
+		if err != nil {
+			return fmt.Errorf("Tarball read error: %v", err)
+		}
+		_, err = io.Copy(&rawData, fileReader)
 		json.Unmarshal(rawData.Bytes(), &metrics)
 		rawData.Reset()
 		if err != nil {
 			return fmt.Errorf("Tarball read error: %v", err)
 		}
+		if transform != nil {
+			for _, metric := range metrics {
+				if document, ok := metric.(map[string]interface{}); ok {
+					transform(document)
+				}
+			}
+		}
 		log.Infof("Importing metrics from %s", hdr.Name)
 		log.Infof("Writing metric to: %s", metricsDir)
 		_, err = (*indexer).Index(metrics, indexers.IndexingOpts{})
@@ -0,0 +1,67 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRelabelDocuments(t *testing.T) {
+	tests := []struct {
+		name     string
+		uuid     string
+		metadata map[string]string
+		document map[string]interface{}
+		want     map[string]interface{}
+	}{
+		{
+			name:     "uuid is overridden",
+			uuid:     "new",
+			document: map[string]interface{}{"uuid": "old", "value": 1.0},
+			want:     map[string]interface{}{"uuid": "new", "value": 1.0},
+		},
+		{
+			name:     "empty uuid keeps the original one",
+			document: map[string]interface{}{"uuid": "old"},
+			want:     map[string]interface{}{"uuid": "old"},
+		},
+		{
+			name:     "metadata is merged",
+			metadata: map[string]string{"platform": "aws", "run": "2"},
+			document: map[string]interface{}{"uuid": "old", "metadata": map[string]interface{}{"run": "1", "ocpVersion": "4.14"}},
+			want:     map[string]interface{}{"uuid": "old", "metadata": map[string]interface{}{"run": "2", "ocpVersion": "4.14", "platform": "aws"}},
+		},
+		{
+			name:     "metadata is added to documents without it",
+			uuid:     "new",
+			metadata: map[string]string{"platform": "aws"},
+			document: map[string]interface{}{"uuid": "old"},
+			want:     map[string]interface{}{"uuid": "new", "metadata": map[string]interface{}{"platform": "aws"}},
+		},
+		{
+			name:     "non map metadata is replaced",
+			metadata: map[string]string{"platform": "aws"},
+			document: map[string]interface{}{"metadata": "invalid"},
+			want:     map[string]interface{}{"metadata": map[string]interface{}{"platform": "aws"}},
+		},
+	}
+	for _, tt := range tests {
+		RelabelDocuments(tt.uuid, tt.metadata)(tt.document)
+		if !reflect.DeepEqual(tt.document, tt.want) {
+			t.Errorf("%s: RelabelDocuments() = %v, want %v", tt.name, tt.document, tt.want)
+		}
+	}
+}
@@ -32,6 +32,7 @@ type ScraperConfig struct {
 	MetricsProfile  string
 	AlertProfile    string
 	SkipTLSVerify   bool
+	CACert          string
 	URL             string
 	Token           string
 	Username        string
@@ -51,6 +51,14 @@ func DecodeMetricsEndpoint(metricsEndpoint string, metricsEndpoints *[]prometheu
 	if err := yamlDec.Decode(&metricsEndpoints); err != nil {
 		log.Fatalf("Error decoding metricsEndpoint %s: %s", metricsEndpoint, err)
 	}
+	for i, me := range *metricsEndpoints {
+		if me.Endpoint == "" {
+			(*metricsEndpoints)[i].Endpoint = me.URL
+		}
+		if (*metricsEndpoints)[i].Endpoint == "" {
+			log.Fatalf("Error decoding metricsEndpoint %s: endpoint %d has no url", metricsEndpoint, i)
+		}
+	}
 }
 
 // Indexes datapoints to a specified indexer.
@@ -0,0 +1,247 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// OCIPrefix prefix of the configuration bundles pulled from an OCI registry
+	OCIPrefix = "oci://"
+	// OCIInsecureEnv environment variable that, set to true, makes the registries be reached over plain HTTP
+	OCIInsecureEnv = "KUBE_BURNER_OCI_INSECURE"
+	// Annotation holding the file name of each layer, as set by oras
+	ociTitleAnnotation = "org.opencontainers.image.title"
+)
+
+// bundleDir directory of the pulled config bundle, the relative paths read by ReadConfig are resolved against it first
+var bundleDir string
+
+// PullOCIBundle pulls the referenced artifact, in oci://<registry>/<repository>[:<tag>|@<digest>] format, into a new
+// temporary directory: tar layers are extracted and the rest are written with the file name of their title annotation.
+// Relative paths read afterwards are resolved against the bundle directory first. It returns the bundle directory and
+// the paths written, relative to it
+func PullOCIBundle(reference string) (string, []string, error) {
+	var nameOpts []name.Option
+	if os.Getenv(OCIInsecureEnv) == "true" {
+		nameOpts = append(nameOpts, name.Insecure)
+	}
+	ref, err := name.ParseReference(strings.TrimPrefix(reference, OCIPrefix), nameOpts...)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid OCI reference %q, expected %s<registry>/<repository>[:<tag>|@<digest>]: %s", reference, OCIPrefix, err)
+	}
+	log.Infof("Pulling %s", ref.Name())
+	img, err := pullImage(ref)
+	if err != nil {
+		return "", nil, fmt.Errorf("error pulling %s: %s", reference, err)
+	}
+	manifest, err := img.Manifest()
+	if err != nil {
+		return "", nil, fmt.Errorf("error reading manifest of %s: %s", reference, err)
+	}
+	if len(manifest.Layers) == 0 {
+		return "", nil, fmt.Errorf("artifact %s has no layers", reference)
+	}
+	dir, err := os.MkdirTemp("", "kube-burner-bundle-")
+	if err != nil {
+		return "", nil, err
+	}
+	var files []string
+	for _, layer := range manifest.Layers {
+		layerFiles, err := pullLayer(img, layer, dir)
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", nil, fmt.Errorf("error pulling layer %s of %s: %s", layer.Digest, reference, err)
+		}
+		files = append(files, layerFiles...)
+	}
+	bundleDir = dir
+	log.Infof("Pulled %d files from %s into %s", len(files), reference, dir)
+	return dir, files, nil
+}
+
+// pullImage returns the referenced image. Indexes are resolved to the manifest of the current platform, or to the
+// first manifest without platform, as artifacts usually are
+func pullImage(ref name.Reference) (v1.Image, error) {
+	opts := []remote.Option{remote.WithAuthFromKeychain(authn.NewMultiKeychain(authFileKeychain{}, authn.DefaultKeychain))}
+	desc, err := remote.Get(ref, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.MediaType.IsIndex() {
+		return desc.Image()
+	}
+	idx, err := desc.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	indexManifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	platform := v1.Platform{OS: runtime.GOOS, Architecture: runtime.GOARCH}
+	var selected *v1.Descriptor
+	for i, m := range indexManifest.Manifests {
+		if m.Platform != nil && m.Platform.Satisfies(platform) {
+			selected = &indexManifest.Manifests[i]
+			break
+		}
+		if m.Platform == nil && selected == nil {
+			selected = &indexManifest.Manifests[i]
+		}
+	}
+	if selected == nil {
+		return nil, fmt.Errorf("index has no manifest for platform %s", platform.String())
+	}
+	log.Debugf("Resolved index %s to manifest %s", ref.Name(), selected.Digest)
+	return idx.Image(selected.Digest)
+}
+
+// authFileKeychain resolves the credentials from the containers auth file set in REGISTRY_AUTH_FILE, as written by
+// podman login. The docker config, with its credential helpers, is handled by authn.DefaultKeychain
+type authFileKeychain struct{}
+
+// Resolve implements authn.Keychain
+func (authFileKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	authFile := os.Getenv("REGISTRY_AUTH_FILE")
+	if authFile == "" {
+		return authn.Anonymous, nil
+	}
+	f, err := os.Open(authFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading registry credentials file %s: %s", authFile, err)
+	}
+	defer f.Close()
+	cf, err := config.LoadFromReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing registry credentials file %s: %s", authFile, err)
+	}
+	for _, key := range []string{target.String(), target.RegistryStr()} {
+		if key == name.DefaultRegistry {
+			key = authn.DefaultAuthKey
+		}
+		cfg, err := cf.GetAuthConfig(key)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Username != "" || cfg.Password != "" || cfg.Auth != "" || cfg.IdentityToken != "" || cfg.RegistryToken != "" {
+			log.Debugf("Using credentials of %s from %s", key, authFile)
+			return authn.FromConfig(authn.AuthConfig{
+				Username:      cfg.Username,
+				Password:      cfg.Password,
+				Auth:          cfg.Auth,
+				IdentityToken: cfg.IdentityToken,
+				RegistryToken: cfg.RegistryToken,
+			}), nil
+		}
+	}
+	return authn.Anonymous, nil
+}
+
+// pullLayer downloads the given layer, whose digest is verified while reading it, and writes its content into dir
+func pullLayer(img v1.Image, layer v1.Descriptor, dir string) ([]string, error) {
+	l, err := img.LayerByDigest(layer.Digest)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := l.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer blob.Close()
+	if strings.Contains(string(layer.MediaType), "tar") {
+		return extractTar(blob, dir)
+	}
+	title := layer.Annotations[ociTitleAnnotation]
+	if title == "" {
+		return nil, fmt.Errorf("layer of media type %s has no %s annotation", layer.MediaType, ociTitleAnnotation)
+	}
+	target, err := bundlePath(dir, title)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeBundleFile(target, blob, 0644); err != nil {
+		return nil, err
+	}
+	return []string{filepath.Clean(title)}, nil
+}
+
+// extractTar extracts the regular files of the given, optionally gzip compressed, tarball into dir
+func extractTar(r io.Reader, dir string) ([]string, error) {
+	var files []string
+	r, err := MaybeGunzip(r)
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return files, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		target, err := bundlePath(dir, hdr.Name)
+		if err != nil {
+			return files, err
+		}
+		if err := writeBundleFile(target, tr, hdr.FileInfo().Mode().Perm()); err != nil {
+			return files, err
+		}
+		files = append(files, filepath.Clean(hdr.Name))
+	}
+}
+
+// bundlePath returns the path of the given bundle file within dir, rejecting the ones escaping it
+func bundlePath(dir, name string) (string, error) {
+	cleanName := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(cleanName) || cleanName == ".." || strings.HasPrefix(cleanName, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("bundle file %s is outside of the bundle directory", name)
+	}
+	return filepath.Join(dir, cleanName), nil
+}
+
+// writeBundleFile writes a new file, bundles holding the same file twice are rejected
+func writeBundleFile(target string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	log.Debugf("Writing %s", target)
+	_, err = io.Copy(f, r)
+	return err
+}
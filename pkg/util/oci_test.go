@@ -0,0 +1,49 @@
+// Copyright 2023 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBundlePath(t *testing.T) {
+	dir := filepath.FromSlash("/tmp/bundle")
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "config.yml", want: filepath.Join(dir, "config.yml")},
+		{name: "templates/pod.yml", want: filepath.Join(dir, "templates", "pod.yml")},
+		{name: "./templates/../pod.yml", want: filepath.Join(dir, "pod.yml")},
+		{name: "templates/..", want: dir},
+		{name: "..", wantErr: true},
+		{name: "../etc/passwd", wantErr: true},
+		{name: "templates/../../etc/passwd", wantErr: true},
+		{name: "/etc/passwd", wantErr: true},
+		{name: "..config.yml", want: filepath.Join(dir, "..config.yml")},
+	}
+	for _, tt := range tests {
+		got, err := bundlePath(dir, tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("bundlePath(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("bundlePath(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// httpProxy proxy used by the Prometheus and indexer clients instead of the one from the environment, when set
+var httpProxy string
+
+// SetHTTPProxy sets the proxy used by the Prometheus and indexer clients, overriding HTTP_PROXY and HTTPS_PROXY.
+// NO_PROXY is still honored. An empty proxy restores the proxy from the environment
+func SetHTTPProxy(proxy string) error {
+	if proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("invalid proxy URL %q", proxy)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("unsupported proxy scheme %q, expected http, https or socks5", u.Scheme)
+		}
+		log.Infof("Using proxy %s for the Prometheus and indexer clients", u.Redacted())
+	}
+	httpProxy = proxy
+	return nil
+}
+
+// ProxyFunc returns the proxy function of the Prometheus and indexer clients' transports: the proxy set with
+// SetHTTPProxy, or the one from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+func ProxyFunc() func(*http.Request) (*url.URL, error) {
+	if httpProxy == "" {
+		return http.ProxyFromEnvironment
+	}
+	noProxy := os.Getenv("NO_PROXY")
+	if noProxy == "" {
+		noProxy = os.Getenv("no_proxy")
+	}
+	proxyFunc := (&httpproxy.Config{HTTPProxy: httpProxy, HTTPSProxy: httpProxy, NoProxy: noProxy}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
+// NewHTTPTransport returns a transport using the configured proxy and the TLS configuration returned by NewTLSConfig.
+// Requests to HTTPS servers are tunneled through the proxy, so the verification applies to the servers themselves,
+// and to the proxy when it's reached over HTTPS
+func NewHTTPTransport(insecureSkipVerify bool, caCert string) (*http.Transport, error) {
+	tlsConfig, err := NewTLSConfig(insecureSkipVerify, caCert)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = ProxyFunc()
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
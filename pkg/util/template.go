@@ -34,10 +34,28 @@ const (
 	MissingKeyZero  templateOption = "missingkey=zero"
 )
 
-// RenderTemplate renders a go-template
+// TemplateEngine set of functions available to the templates
+type TemplateEngine string
+
+const (
+	// GoTemplateEngine the Go template builtins and the kube-burner functions
+	GoTemplateEngine TemplateEngine = "go"
+	// SprigTemplateEngine the Go template builtins, the kube-burner functions and the Sprig functions
+	SprigTemplateEngine TemplateEngine = "sprig"
+)
+
+// RenderTemplate renders a go-template with the Sprig functions
 func RenderTemplate(original []byte, inputData interface{}, options templateOption) ([]byte, error) {
+	return RenderTemplateWithEngine(SprigTemplateEngine, original, inputData, options)
+}
+
+// RenderTemplateWithEngine renders a go-template with the functions of the given engine, the Sprig engine is used when it's empty
+func RenderTemplateWithEngine(engine TemplateEngine, original []byte, inputData interface{}, options templateOption) ([]byte, error) {
 	var rendered bytes.Buffer
-	funcMap := sprig.GenericFuncMap()
+	funcMap := template.FuncMap{}
+	if engine != GoTemplateEngine {
+		funcMap = sprig.GenericFuncMap()
+	}
 	funcMap["Binomial"] = combin.Binomial
 	funcMap["IndexToCombination"] = combin.IndexToCombination
 	funcMap["GetSubnet24"] = func(subnetIdx int) string {
@@ -15,12 +15,16 @@
 package util
 
 import (
+	"bufio"
+	"compress/gzip"
 	"embed"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -31,13 +35,38 @@ func ReadEmbedConfig(embedFs embed.FS, configFile string) (io.Reader, error) {
 	return f, err
 }
 
-// ReadConfig reads configuration from the given path or URL
+// StdinConfig path of the configuration read from stdin
+const StdinConfig = "-"
+
+// ReadConfig reads configuration from the given path, URL, git reference or from stdin when the path is StdinConfig
 func ReadConfig(configFile string) (io.Reader, error) {
 	var f io.Reader
+	if configFile == StdinConfig {
+		log.Info("Reading configuration from stdin")
+		return os.Stdin, nil
+	}
+	if strings.HasPrefix(configFile, GitPrefix) {
+		return readGitFile(configFile)
+	}
+	// Bundles are pulled as a whole by PullOCIBundle
+	if strings.HasPrefix(configFile, OCIPrefix) {
+		return nil, fmt.Errorf("%s: OCI references are only supported as the config of the init subcommand", configFile)
+	}
+	// The files of a pulled config bundle take precedence over the ones of the working directory
+	if bundleDir != "" && !filepath.IsAbs(configFile) {
+		if f, err := os.Open(filepath.Join(bundleDir, configFile)); err == nil {
+			return f, nil
+		}
+	}
 	f, err := os.Open(configFile)
 	// If the template file does not exist we try to read it from an URL
 	if os.IsNotExist(err) {
 		log.Debugf("File %s not found, falling back to read from URL", configFile)
+		if u, parseErr := url.Parse(configFile); parseErr != nil || !u.IsAbs() {
+			// Relative paths are resolved against the working directory, also when the configuration is read from stdin
+			cwd, _ := os.Getwd()
+			return f, fmt.Errorf("%s not found, relative paths are resolved against the current working directory %s", configFile, cwd)
+		}
 		f, err = readURL(configFile, f)
 	}
 	return f, err
@@ -61,3 +90,16 @@ func readURL(stringURL string, body io.Reader) (io.Reader, error) {
 	}
 	return r.Body, nil
 }
+
+// MaybeGunzip returns a reader decompressing the given reader when its content is gzip compressed, or the reader itself otherwise
+func MaybeGunzip(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return br, err
+	}
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// WriteUUIDFile writes the UUID of a run to the given file, so other commands can read it with ReadUUIDFile
+func WriteUUIDFile(uuidFile, uuid string) error {
+	log.Infof("Writing UUID %s to %s", uuid, uuidFile)
+	if err := os.WriteFile(uuidFile, []byte(uuid+"\n"), 0644); err != nil {
+		return fmt.Errorf("error writing UUID file: %s", err)
+	}
+	return nil
+}
+
+// ReadUUIDFile reads the UUID written by WriteUUIDFile
+func ReadUUIDFile(uuidFile string) (string, error) {
+	content, err := os.ReadFile(uuidFile)
+	if err != nil {
+		return "", fmt.Errorf("error reading UUID file: %s", err)
+	}
+	uuid := strings.TrimSpace(string(content))
+	if uuid == "" {
+		return "", fmt.Errorf("UUID file %s is empty", uuidFile)
+	}
+	log.Infof("Read UUID %s from %s", uuid, uuidFile)
+	return uuid, nil
+}
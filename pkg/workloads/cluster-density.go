@@ -36,7 +36,7 @@ func NewClusterDensity(wh *WorkloadHelper, variant string) *cobra.Command {
 		Short: fmt.Sprintf("Runs %v workload", variant),
 		PreRun: func(cmd *cobra.Command, args []string) {
 			if !burner.VerifyContainerRegistry(wh.restConfig) {
-				os.Exit(1)
+				log.Exit(1)
 			}
 			wh.Metadata.Benchmark = cmd.Name()
 			os.Setenv("JOB_ITERATIONS", fmt.Sprint(iterations))
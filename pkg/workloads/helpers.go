@@ -15,6 +15,7 @@
 package workloads
 
 import (
+	"context"
 	"embed"
 	"fmt"
 	"io"
@@ -160,7 +161,7 @@ func (wh *WorkloadHelper) run(workload, metricsProfile string) {
 	if wh.Config.Indexing {
 		indexerConfig := configSpec.GlobalConfig.IndexerConfig
 		log.Infof("📁 Creating indexer: %s", indexerConfig.Type)
-		indexer, err = indexers.NewIndexer(indexerConfig)
+		indexer, err = metrics.NewIndexers(configSpec.GlobalConfig.IndexerConfigs())
 		if err != nil {
 			log.Fatalf("%v indexer: %v", indexerConfig.Type, err.Error())
 		}
@@ -187,6 +188,7 @@ func (wh *WorkloadHelper) run(workload, metricsProfile string) {
 				metricsEndpoints = append(metricsEndpoints, reportingProfile)
 				for i := range configSpec.GlobalConfig.Measurements {
 					configSpec.GlobalConfig.Measurements[i].PodLatencyMetrics = types.Quantiles
+					configSpec.GlobalConfig.Measurements[i].LatencyMetrics = types.Quantiles
 				}
 			case both:
 				metricsEndpoints = append(metricsEndpoints, regularProfile, reportingProfile)
@@ -208,6 +210,9 @@ func (wh *WorkloadHelper) run(workload, metricsProfile string) {
 			if err != nil {
 				log.Fatal(err)
 			}
+			if err = p.ReadJobProfiles(); err != nil {
+				log.Fatal(err)
+			}
 			if wh.Alerting && metricsEndpoint.AlertProfile != "" {
 				alertM, err = alerting.NewAlertManager(metricsEndpoint.AlertProfile, wh.Metadata.UUID, indexer, p, embedConfig)
 				if err != nil {
@@ -220,7 +225,11 @@ func (wh *WorkloadHelper) run(workload, metricsProfile string) {
 		}
 		configSpec.GlobalConfig.GCMetrics = wh.GcMetrics
 	}
-	rc, err = burner.Run(configSpec, prometheusClients, alertMs, indexer, wh.Timeout, metadata)
+	if wh.Context == nil {
+		wh.Context = context.Background()
+	}
+	result, err := burner.Run(wh.Context, configSpec, prometheusClients, alertMs, indexer, wh.Timeout, metadata)
+	rc = result.ReturnCode
 	if err != nil {
 		wh.Metadata.ExecutionErrors = err.Error()
 		log.Error(err)
@@ -230,7 +239,7 @@ func (wh *WorkloadHelper) run(workload, metricsProfile string) {
 		IndexMetadata(indexer, wh.Metadata)
 	}
 	log.Info("👋 Exiting kube-burner ", wh.UUID)
-	os.Exit(rc)
+	log.Exit(rc)
 }
 
 // ExtractWorkload extracts the given workload and metrics profile to the current diretory
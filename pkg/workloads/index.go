@@ -15,7 +15,7 @@
 package workloads
 
 import (
-	"os"
+	"context"
 	"time"
 
 	"github.com/cloud-bulldozer/go-commons/indexers"
@@ -44,7 +44,7 @@ func NewIndex(metricsEndpoint *string, ocpMetaAgent *ocpmetadata.Metadata) *cobr
 		SilenceUsage: true,
 		PostRun: func(cmd *cobra.Command, args []string) {
 			log.Info("👋 Exiting kube-burner ", uuid)
-			os.Exit(rc)
+			log.Exit(rc)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 			uuid, _ = cmd.Flags().GetString("uuid")
@@ -56,18 +56,22 @@ func NewIndex(metricsEndpoint *string, ocpMetaAgent *ocpmetadata.Metadata) *cobr
 			esIndex, _ := cmd.Flags().GetString("es-index")
 			configSpec.GlobalConfig.UUID = uuid
 			if esServer != "" && esIndex != "" {
-				configSpec.GlobalConfig.IndexerConfig = indexers.IndexerConfig{
-					Type:    indexers.ElasticIndexer,
-					Servers: []string{esServer},
-					Index:   esIndex,
+				configSpec.GlobalConfig.IndexerConfig = config.IndexerConfig{
+					IndexerConfig: indexers.IndexerConfig{
+						Type:    indexers.ElasticIndexer,
+						Servers: []string{esServer},
+						Index:   esIndex,
+					},
 				}
 			} else {
 				if metricsDirectory == "collected-metrics" {
 					metricsDirectory = metricsDirectory + "-" + uuid
 				}
-				configSpec.GlobalConfig.IndexerConfig = indexers.IndexerConfig{
-					Type:             indexers.LocalIndexer,
-					MetricsDirectory: metricsDirectory,
+				configSpec.GlobalConfig.IndexerConfig = config.IndexerConfig{
+					IndexerConfig: indexers.IndexerConfig{
+						Type:             indexers.LocalIndexer,
+						MetricsDirectory: metricsDirectory,
+					},
 				}
 			}
 			// When metricsEndpoint is specified, don't fetch any prometheus token
@@ -106,7 +110,7 @@ func NewIndex(metricsEndpoint *string, ocpMetaAgent *ocpmetadata.Metadata) *cobr
 					},
 				}
 				prometheusClients.JobList = append(prometheusClients.JobList, prometheusJob)
-				if prometheusClients.ScrapeJobsMetrics(docsToIndex) != nil {
+				if prometheusClients.ScrapeJobsMetrics(context.TODO(), docsToIndex) != nil {
 					rc = 1
 				}
 			}
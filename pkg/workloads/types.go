@@ -1,6 +1,7 @@
 package workloads
 
 import (
+	"context"
 	"embed"
 	"time"
 
@@ -66,4 +67,6 @@ type WorkloadHelper struct {
 	ocpConfig       embed.FS
 	OcpMetaAgent    ocpmetadata.Metadata
 	restConfig      *rest.Config
+	// Context interrupts the run once cancelled
+	Context context.Context
 }